@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
+
+	"polar-bear/internal/config"
+	"polar-bear/internal/event"
+	"polar-bear/internal/informer"
+	"polar-bear/internal/store"
+)
+
+// Cluster names are part of URLs.
+var clusterNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// clusterSource is a cluster to show and how to connect to it.
+type clusterSource struct {
+	name    string
+	cluster informer.Cluster
+}
+
+// clusterSources returns the clusters of -kube-contexts and -kubeconfigs,
+// entries are either the context or file, or name=context and name=file.
+// Without any, the cluster of KUBECONFIG is shown as -cluster-name.
+func clusterSources(cfg *config.Config) ([]clusterSource, error) {
+	var sources []clusterSource
+
+	for _, entry := range cfg.KubeContexts {
+		name, kubeContext := splitClusterEntry(entry)
+		if name == "" {
+			name = kubeContext
+		}
+		sources = append(sources, clusterSource{
+			name:    name,
+			cluster: informer.Cluster{Context: kubeContext},
+		})
+	}
+
+	for _, entry := range cfg.Kubeconfigs {
+		name, path := splitClusterEntry(entry)
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		sources = append(sources, clusterSource{
+			name:    name,
+			cluster: informer.Cluster{Kubeconfig: path},
+		})
+	}
+
+	if len(sources) == 0 {
+		return []clusterSource{{name: cfg.ClusterName}}, nil
+	}
+
+	seen := make(map[string]bool, len(sources))
+	for _, src := range sources {
+		if !clusterNamePattern.MatchString(src.name) {
+			return nil, fmt.Errorf("invalid cluster name %q, use name=context to rename it", src.name)
+		}
+		if seen[src.name] {
+			return nil, fmt.Errorf("duplicate cluster name %q", src.name)
+		}
+		seen[src.name] = true
+	}
+
+	return sources, nil
+}
+
+func splitClusterEntry(entry string) (name string, value string) {
+	name, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+	if !found {
+		return "", name
+	}
+	return name, value
+}
+
+// splitList splits a comma separated flag value.
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func clusterInformers(
+	ctx context.Context,
+	cfg *config.Config,
+	src clusterSource,
+	store store.Store,
+	ed event.Distribution,
+) ([]informer.Informer, error) {
+	fct, err := informer.NewInformerFactory(src.cluster, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new informer factory: %v", err)
+	}
+
+	infs := []informer.Informer{
+		informer.NewNodeInformer(fct, store, ed),
+		informer.NewNamespaceInformer(fct, store, ed),
+		informer.NewPodInformer(fct, store, ed),
+		informer.NewReplicaSetInformer(fct, store, ed),
+		informer.NewStatefulSetInformer(fct, store, ed),
+		informer.NewDeploymentInformer(fct, store, ed),
+		informer.NewDaemonSetInformer(fct, store, ed),
+		informer.NewJobInformer(fct, store, ed),
+		informer.NewCronJobInformer(fct, store, ed),
+		informer.NewControllerRevisionInformer(fct, store, ed),
+		informer.NewServiceInformer(fct, store, ed),
+		informer.NewEndpointSliceInformer(fct, store, ed),
+		informer.NewIngressInformer(fct, store, ed),
+		informer.NewNetworkPolicyInformer(fct, store, ed),
+		informer.NewPersistentVolumeInformer(fct, store, ed),
+		informer.NewPersistentVolumeClaimInformer(fct, store, ed),
+		informer.NewStorageClassInformer(fct, store, ed),
+		informer.NewVolumeAttachmentInformer(fct, store, ed),
+		informer.NewCSIDriverInformer(fct, store, ed),
+		informer.NewCSINodeInformer(fct, store, ed),
+		informer.NewCSIStorageCapacityInformer(fct, store, ed),
+		informer.NewClusterRoleInformer(fct, store, ed),
+		informer.NewClusterRoleBindingInformer(fct, store, ed),
+		informer.NewRoleInformer(fct, store, ed),
+		informer.NewRoleBindingInformer(fct, store, ed),
+		informer.NewServiceAccountInformer(fct, store, ed),
+		informer.NewConfigMapInformer(fct, store, ed),
+		informer.NewSecretInformer(fct, store, ed),
+		informer.NewEventInformer(fct, store, ed, cfg.EventRetention),
+	}
+
+	if cfg.CustomResources {
+		crInfs, err := customResourceInformers(ctx, src.cluster, store, ed)
+		if err != nil {
+			slog.WarnContext(ctx, "unable to discover custom resources, skipping them",
+				"cluster", src.name,
+				"err", err,
+			)
+		}
+		infs = append(infs, crInfs...)
+	}
+
+	if cfg.UsageMetrics {
+		client, err := informer.NewDynamicClient(src.cluster)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create new dynamic client: %v", err)
+		}
+		infs = append(infs, informer.NewUsageInformer(client, store, ed, cfg.UsageInterval))
+	}
+
+	return infs, nil
+}
+
+func customResourceInformers(
+	ctx context.Context,
+	cluster informer.Cluster,
+	store store.Store,
+	ed event.Distribution,
+) ([]informer.Informer, error) {
+	client, err := informer.NewExtensionsClient(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new extensions client: %v", err)
+	}
+
+	dynFct, err := informer.NewDynamicInformerFactory(cluster, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new dynamic informer factory: %v", err)
+	}
+
+	crInfs, err := informer.NewCustomResourceInformers(ctx, client, dynFct, store, ed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover custom resource definitions: %v", err)
+	}
+
+	crdFct := apiextensionsinformers.NewSharedInformerFactory(client, 0)
+	infs := []informer.Informer{
+		informer.NewCustomResourceDefinitionInformer(crdFct, store, ed),
+	}
+
+	return append(infs, crInfs...), nil
+}
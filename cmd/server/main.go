@@ -15,6 +15,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	metrics "github.com/slok/go-http-metrics/metrics/prometheus"
 	"github.com/slok/go-http-metrics/middleware"
+	"k8s.io/client-go/tools/cache"
 
 	"polar-bear/cmd"
 	"polar-bear/internal/config"
@@ -38,15 +39,33 @@ func main() {
 	ll := fs.String("loglevel", "info", "Verbosity of logging, one of debug/info/warn/error")
 	lf := fs.String("logformat", "human", "Format of logging, one of human/json")
 	cn := fs.String("cluster-name", "My Cluster", "name of the cluster")
+	cr := fs.Bool("custom-resources", true, "Discover CRDs at startup and watch their custom resources")
+	er := fs.Duration("event-retention", time.Hour, "How long to keep Kubernetes events in the store")
 	dm := fs.Bool("devmode", false, "Use non-optimized Tailwind CSS file with all classes")
 	hn := fs.String("hostname", "My Host", "name of the host that serves the application")
+	kc := fs.String("kubeconfigs", "", "Comma separated kubeconfig files of the clusters to show, as file or name=file")
+	kx := fs.String("kube-contexts", "", "Comma separated kubeconfig contexts of the clusters to show, as context or name=context")
 	hl := fs.String("http-listen-address", "localhost:8888", "http listen address")
+	lt := fs.String("live-transport", "ws", "Default transport of live updates, one of ws/sse")
 	ml := fs.String("metrics-listen-address", "localhost:8889", "metrics listen address")
+	sb := fs.String("store", "memory", "Store backend, one of memory/bolt")
+	sm := fs.String("store-mode", "json", "How resources are kept in the store, one of json/typed")
+	se := fs.String("store-eviction", "count", "When the memory store evicts resources, one of count/size/none")
+	so := fs.Uint64("store-max-objects", 10_000, "Number of resources the memory store holds with count eviction")
+	sz := fs.Uint64("store-max-mb", 512, "Size in MiB of the resources the memory store holds with size eviction")
+	sp := fs.String("store-path", "polar-bear.db", "Path of the database file of the bolt store")
+	ub := fs.Int("update-buffer", 256, "Number of changed resources buffered per live view before updates are dropped")
+	um := fs.Bool("usage-metrics", false, "Poll the CPU and memory usage of pods and nodes from the metrics.k8s.io API")
+	ui := fs.Duration("usage-interval", 30*time.Second, "How often to poll the metrics.k8s.io API with -usage-metrics")
 	err := ff.Parse(fs, os.Args[1:], ff.WithEnvVarPrefix(envVarPrefix))
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	if err := checkStoreFlags(fs, *sm); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	logger, err := cmd.MakeLogger(*ll, *lf)
 	if err != nil {
@@ -86,16 +105,44 @@ func main() {
 
 	cfg := &config.Config{
 		ClusterName:          *cn,
+		CustomResources:      *cr,
 		DevMode:              *dm,
+		EventRetention:       *er,
 		HTTPListenAddress:    *hl,
+		KubeContexts:         splitList(*kx),
+		Kubeconfigs:          splitList(*kc),
+		LiveTransport:        *lt,
 		MetricsListenAddress: *ml,
+		Store:                *sb,
+		StoreMode:            *sm,
+		StoreEviction:        *se,
+		StoreMaxObjects:      *so,
+		StoreMaxMB:           *sz,
+		StorePath:            *sp,
+		UpdateBuffer:         *ub,
+		UsageMetrics:         *um,
+		UsageInterval:        *ui,
 	}
 	slog.Info(
 		"config",
 		"cluster_name", cfg.ClusterName,
+		"custom_resources", cfg.CustomResources,
 		"dev_mode", cfg.DevMode,
+		"event_retention", cfg.EventRetention.String(),
 		"http_listen_address", cfg.HTTPListenAddress,
+		"kube_contexts", cfg.KubeContexts,
+		"kubeconfigs", cfg.Kubeconfigs,
+		"live_transport", cfg.LiveTransport,
 		"metrics_listen_address", cfg.MetricsListenAddress,
+		"store", cfg.Store,
+		"store_mode", cfg.StoreMode,
+		"store_eviction", cfg.StoreEviction,
+		"store_max_objects", cfg.StoreMaxObjects,
+		"store_max_mb", cfg.StoreMaxMB,
+		"store_path", cfg.StorePath,
+		"update_buffer", cfg.UpdateBuffer,
+		"usage_metrics", cfg.UsageMetrics,
+		"usage_interval", cfg.UsageInterval.String(),
 	)
 
 	ctx := context.Background()
@@ -113,30 +160,32 @@ func run(
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
 	defer cancel()
 
-	store, err := store.NewOtterStore()
-	if err != nil {
-		return fmt.Errorf("failed to create new store: %v", err)
+	if cfg.LiveTransport != "ws" && cfg.LiveTransport != "sse" {
+		return fmt.Errorf("unknown live transport %q", cfg.LiveTransport)
+	}
+	if cfg.UsageMetrics && cfg.UsageInterval <= 0 {
+		return fmt.Errorf("usage interval must be positive, got %s", cfg.UsageInterval)
 	}
 
-	ed, err := event.NewDistributer(slog.With("component", "event-distributer"))
+	sources, err := clusterSources(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create new event distributer: %v", err)
+		return err
 	}
 
-	fct, err := informer.NewInformerFactory("")
+	store, err := newStore(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create new informer factory: %v", err)
+		return fmt.Errorf("failed to create new store: %v", err)
 	}
 
-	infs := []informer.Informer{
-		informer.NewNodeInformer(fct, store, ed),
-		informer.NewNamespaceInformer(fct, store, ed),
-		informer.NewPodInformer(fct, store, ed),
-		informer.NewReplicaSetInformer(fct, store, ed),
-		informer.NewStatefulSetInformer(fct, store, ed),
-		informer.NewDeploymentInformer(fct, store, ed),
-		informer.NewServiceInformer(fct, store, ed),
-		informer.NewIngressInformer(fct, store, ed),
+	var infs []informer.Informer
+	clusters := make([]server.Cluster, 0, len(sources))
+	for _, src := range sources {
+		cluster, clusterInfs, err := newCluster(ctx, cfg, src, store, len(sources) > 1)
+		if err != nil {
+			return fmt.Errorf("failed to set up cluster %s: %v", src.name, err)
+		}
+		clusters = append(clusters, cluster)
+		infs = append(infs, clusterInfs...)
 	}
 
 	mdlw := middleware.New(middleware.Config{
@@ -149,7 +198,7 @@ func run(
 		ReadHeaderTimeout: 3 * time.Second,
 		IdleTimeout:       120 * time.Second,
 		Addr:              cfg.HTTPListenAddress,
-		Handler:           server.GetRoutes(rm, cfg, mdlw, store, ed),
+		Handler:           server.GetRoutes(rm, cfg, mdlw, clusters),
 	}
 
 	metricsSrv := &http.Server{
@@ -177,6 +226,8 @@ func run(
 		}()
 	}
 
+	go reconcileStore(ctx, store, infs)
+
 	go func() {
 		slog.InfoContext(ctx, "http server running", "address", cfg.HTTPListenAddress)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -237,3 +288,116 @@ func run(
 
 	return errors.Join(errs...)
 }
+
+// newCluster creates the informers of a cluster. With several clusters, each
+// keeps its resources below its own prefix of the shared store.
+func newCluster(
+	ctx context.Context,
+	cfg *config.Config,
+	src clusterSource,
+	db store.Store,
+	prefixed bool,
+) (server.Cluster, []informer.Informer, error) {
+	if prefixed {
+		db = store.NewPrefixedStore(db, "c/"+src.name+"/")
+	}
+
+	ed, err := event.NewDistributer(
+		slog.With("component", "event-distributer", "cluster", src.name),
+		cfg.UpdateBuffer,
+	)
+	if err != nil {
+		return server.Cluster{}, nil, fmt.Errorf("failed to create new event distributer: %v", err)
+	}
+
+	infs, err := clusterInformers(ctx, cfg, src, db, ed)
+	if err != nil {
+		return server.Cluster{}, nil, err
+	}
+
+	cluster := server.Cluster{
+		Name:   src.name,
+		Store:  db,
+		Events: ed,
+	}
+	return cluster, infs, nil
+}
+
+func newStore(cfg *config.Config) (store.Store, error) {
+	switch cfg.StoreMode {
+	case "json":
+	case "typed":
+		// Typed objects are kept in memory, the bolt store only persists them
+		if cfg.Store == "memory" {
+			return store.NewTypedStore(nil)
+		}
+	default:
+		return nil, fmt.Errorf("unknown store mode %q, one of json/typed", cfg.StoreMode)
+	}
+
+	var db store.Store
+	var err error
+	switch cfg.Store {
+	case "memory":
+		db, err = store.NewOtterStore(store.OtterOptions{
+			Eviction:   cfg.StoreEviction,
+			MaxObjects: cfg.StoreMaxObjects,
+			MaxBytes:   cfg.StoreMaxMB << 20,
+		})
+	case "bolt":
+		db, err = store.NewBoltStore(cfg.StorePath)
+	default:
+		return nil, fmt.Errorf("unknown store %q, one of memory/bolt", cfg.Store)
+	}
+	if err != nil || cfg.StoreMode == "json" {
+		return db, err
+	}
+
+	return store.NewTypedStore(db)
+}
+
+// checkStoreFlags rejects eviction flags set with the typed store mode, the
+// typed store keeps all objects in memory and never evicts.
+func checkStoreFlags(fs *flag.FlagSet, mode string) error {
+	if mode != "typed" {
+		return nil
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "store-eviction", "store-max-objects", "store-max-mb":
+			err = fmt.Errorf("-%s is not supported with -store-mode typed, the typed store never evicts", f.Name)
+		}
+	})
+	return err
+}
+
+// reconcileStore removes resources deleted while polar-bear was not running
+// from a persistent store, once all informers have written their initial list.
+func reconcileStore(
+	ctx context.Context,
+	db store.Store,
+	infs []informer.Informer,
+) {
+	rec, ok := db.(store.Reconciler)
+	if !ok {
+		return
+	}
+
+	synced := make([]cache.InformerSynced, 0, len(infs))
+	for _, inf := range infs {
+		synced = append(synced, inf.HasSynced)
+	}
+
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return
+	}
+
+	removed, err := rec.Reconcile()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to reconcile store", "err", err)
+		return
+	}
+	slog.InfoContext(ctx, "store reconciled", "removed", removed)
+}
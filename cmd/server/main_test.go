@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/peterbourgon/ff"
+)
+
+func TestCheckStoreFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "eviction flags with json mode",
+			args: []string{"-store-eviction", "size", "-store-max-objects", "10", "-store-max-mb", "1"},
+		},
+		{
+			name: "typed mode without eviction flags",
+			args: []string{"-store-mode", "typed", "-store", "bolt"},
+		},
+		{
+			name:    "typed mode with eviction",
+			args:    []string{"-store-mode", "typed", "-store-eviction", "none"},
+			wantErr: true,
+		},
+		{
+			name:    "typed mode with max objects",
+			args:    []string{"-store-mode", "typed", "-store-max-objects", "10"},
+			wantErr: true,
+		},
+		{
+			name:    "typed mode with max size",
+			args:    []string{"-store-mode", "typed", "-store-max-mb", "1"},
+			wantErr: true,
+		},
+		{
+			name:    "typed mode with eviction from the environment",
+			args:    []string{"-store-mode", "typed"},
+			env:     map[string]string{envVarPrefix + "_STORE_MAX_MB": "1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			fs := flag.NewFlagSet(applicationName, flag.ContinueOnError)
+			sm := fs.String("store-mode", "json", "")
+			fs.String("store", "memory", "")
+			fs.String("store-eviction", "count", "")
+			fs.Uint64("store-max-objects", 10_000, "")
+			fs.Uint64("store-max-mb", 512, "")
+			if err := ff.Parse(fs, tt.args, ff.WithEnvVarPrefix(envVarPrefix)); err != nil {
+				t.Fatalf("unable to parse flags: %v", err)
+			}
+
+			err := checkStoreFlags(fs, *sm)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,8 +1,24 @@
 package config
 
+import "time"
+
 type Config struct {
 	ClusterName          string
+	CustomResources      bool
 	DevMode              bool
+	EventRetention       time.Duration
 	HTTPListenAddress    string
+	KubeContexts         []string
+	Kubeconfigs          []string
+	LiveTransport        string
 	MetricsListenAddress string
+	Store                string
+	StoreMode            string
+	StoreEviction        string
+	StoreMaxObjects      uint64
+	StoreMaxMB           uint64
+	StorePath            string
+	UpdateBuffer         int
+	UsageMetrics         bool
+	UsageInterval        time.Duration
 }
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"polar-bear/internal/store"
+)
+
+// TopPodsLimit is the number of pods listed as the top requesting pods of a
+// node or namespace.
+const TopPodsLimit = 10
+
+// Allocation are the requests and limits of pods for one resource, compared
+// to what is allocatable to them.
+type Allocation struct {
+	Name        corev1.ResourceName
+	Requests    resource.Quantity
+	Limits      resource.Quantity
+	Allocatable resource.Quantity
+}
+
+// RequestsRatio returns the share of the allocatable amount that is
+// requested, false if nothing is allocatable.
+func (a Allocation) RequestsRatio() (float64, bool) {
+	return quantityRatio(a.Requests, a.Allocatable)
+}
+
+// LimitsRatio returns the share of the allocatable amount that the limits add
+// up to, above one if the resource is overcommitted.
+func (a Allocation) LimitsRatio() (float64, bool) {
+	return quantityRatio(a.Limits, a.Allocatable)
+}
+
+func quantityRatio(q resource.Quantity, of resource.Quantity) (float64, bool) {
+	if of.IsZero() {
+		return 0, false
+	}
+	return q.AsApproximateFloat64() / of.AsApproximateFloat64(), true
+}
+
+// PodResources are the effective requests and limits of a pod.
+type PodResources struct {
+	Pod      *corev1.Pod
+	Requests corev1.ResourceList
+	Limits   corev1.ResourceList
+}
+
+// Capacity is the allocation of a node or namespace by its pods.
+type Capacity struct {
+	// Allocations has cpu, memory and ephemeral-storage first, then extended
+	// resources such as GPUs by name
+	Allocations []Allocation
+	// Pods counts the pods that are neither succeeded nor failed
+	Pods int
+	// TopPods are the pods requesting the most CPU, then memory
+	TopPods []PodResources
+}
+
+// NodeCapacity returns the allocation of the allocatable resources of a node
+// by the pods bound to it.
+func NodeCapacity(store store.Store, no *corev1.Node) Capacity {
+	return newCapacity(GetPodsOnNode(store, no.Name), no.Status.Allocatable)
+}
+
+// NamespaceCapacity returns the allocation of the allocatable resources of all
+// nodes by the pods of a namespace, ns may be AllNamespaces.
+func NamespaceCapacity(store store.Store, ns string) Capacity {
+	allocatable := corev1.ResourceList{}
+	for _, no := range GetNodes(store) {
+		addResources(allocatable, no.Status.Allocatable)
+	}
+	return newCapacity(GetPods(store, ns), allocatable)
+}
+
+func newCapacity(pds []*corev1.Pod, allocatable corev1.ResourceList) Capacity {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	c := Capacity{}
+	for _, pd := range pds {
+		if pd.Status.Phase == corev1.PodSucceeded || pd.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		pr := GetPodResources(pd)
+		addResources(requests, pr.Requests)
+		addResources(limits, pr.Limits)
+		c.Pods++
+		c.TopPods = append(c.TopPods, pr)
+	}
+
+	for _, name := range allocationNames(requests, limits, allocatable) {
+		c.Allocations = append(c.Allocations, Allocation{
+			Name:        name,
+			Requests:    requests[name],
+			Limits:      limits[name],
+			Allocatable: allocatable[name],
+		})
+	}
+
+	slices.SortStableFunc(c.TopPods, func(a, b PodResources) int {
+		return cmp.Or(
+			b.Requests.Cpu().Cmp(*a.Requests.Cpu()),
+			b.Requests.Memory().Cmp(*a.Requests.Memory()),
+		)
+	})
+	if len(c.TopPods) > TopPodsLimit {
+		c.TopPods = c.TopPods[:TopPodsLimit]
+	}
+	return c
+}
+
+// allocationNames returns cpu, memory and ephemeral-storage followed by the
+// other requested, limited or extended allocatable resources by name.
+func allocationNames(lists ...corev1.ResourceList) []corev1.ResourceName {
+	names := []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage}
+	other := make([]corev1.ResourceName, 0)
+	for i, list := range lists {
+		for name, q := range list {
+			if slices.Contains(names, name) || slices.Contains(other, name) || name == corev1.ResourcePods {
+				continue
+			}
+			// Allocatable only lists extended resources nobody asks for, as
+			// in idle GPUs
+			if i == len(lists)-1 && (!strings.Contains(string(name), "/") || q.IsZero()) {
+				continue
+			}
+			other = append(other, name)
+		}
+	}
+	slices.Sort(other)
+	return append(names, other...)
+}
+
+// GetPodResources returns the requests and limits of a pod as the scheduler
+// sees them: the containers and sidecars running at once, or the largest init
+// container with the sidecars started before it, plus the pod overhead.
+// Limits only add up the containers that set them.
+func GetPodResources(pd *corev1.Pod) PodResources {
+	return PodResources{
+		Pod:      pd,
+		Requests: podResources(pd, func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Requests }),
+		Limits:   podResources(pd, func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Limits }),
+	}
+}
+
+func podResources(pd *corev1.Pod, of func(corev1.ResourceRequirements) corev1.ResourceList) corev1.ResourceList {
+	res := corev1.ResourceList{}
+	for _, cnt := range pd.Spec.Containers {
+		addResources(res, of(cnt.Resources))
+	}
+
+	sidecars, inits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, cnt := range pd.Spec.InitContainers {
+		if cnt.RestartPolicy != nil && *cnt.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResources(sidecars, of(cnt.Resources))
+			continue
+		}
+		init := corev1.ResourceList{}
+		addResources(init, sidecars)
+		addResources(init, of(cnt.Resources))
+		maxResources(inits, init)
+	}
+	addResources(res, sidecars)
+	maxResources(res, inits)
+
+	addResources(res, pd.Spec.Overhead)
+	return res
+}
+
+func addResources(to corev1.ResourceList, list corev1.ResourceList) {
+	for name, q := range list {
+		sum := to[name]
+		sum.Add(q)
+		to[name] = sum
+	}
+}
+
+func maxResources(to corev1.ResourceList, list corev1.ResourceList) {
+	for name, q := range list {
+		if current, ok := to[name]; !ok || q.Cmp(current) > 0 {
+			to[name] = q.DeepCopy()
+		}
+	}
+}
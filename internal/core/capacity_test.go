@@ -0,0 +1,242 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testResources(pairs ...string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		list[corev1.ResourceName(pairs[i])] = resource.MustParse(pairs[i+1])
+	}
+	return list
+}
+
+func testContainer(name string, requests corev1.ResourceList, limits corev1.ResourceList) corev1.Container {
+	return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits}}
+}
+
+func testSidecar(name string, requests corev1.ResourceList) corev1.Container {
+	always := corev1.ContainerRestartPolicyAlways
+	cnt := testContainer(name, requests, nil)
+	cnt.RestartPolicy = &always
+	return cnt
+}
+
+// formatResources returns a resource list as "name=quantity" sorted by name.
+func formatResources(list corev1.ResourceList) []string {
+	res := make([]string, 0, len(list))
+	for name, q := range list {
+		res = append(res, fmt.Sprintf("%s=%s", name, q.String()))
+	}
+	slices.Sort(res)
+	return res
+}
+
+func TestPodResources(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         corev1.PodSpec
+		wantRequests []string
+		wantLimits   []string
+	}{
+		{
+			name: "no resources",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		},
+		{
+			name: "containers add up",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				testContainer("app", testResources("cpu", "100m", "memory", "64Mi"), testResources("memory", "128Mi")),
+				testContainer("proxy", testResources("cpu", "50m"), testResources("cpu", "200m")),
+			}},
+			wantRequests: []string{"cpu=150m", "memory=64Mi"},
+			wantLimits:   []string{"cpu=200m", "memory=128Mi"},
+		},
+		{
+			name: "largest init container",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					testContainer("migrate", testResources("cpu", "2"), nil),
+					testContainer("fetch", testResources("memory", "1Gi"), nil),
+				},
+				Containers: []corev1.Container{
+					testContainer("app", testResources("cpu", "500m", "memory", "256Mi"), nil),
+				},
+			},
+			wantRequests: []string{"cpu=2", "memory=1Gi"},
+		},
+		{
+			name: "init containers below the containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testContainer("migrate", testResources("cpu", "100m"), nil)},
+				Containers:     []corev1.Container{testContainer("app", testResources("cpu", "500m"), nil)},
+			},
+			wantRequests: []string{"cpu=500m"},
+		},
+		{
+			name: "sidecars run with the containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testSidecar("proxy", testResources("cpu", "100m"))},
+				Containers:     []corev1.Container{testContainer("app", testResources("cpu", "500m"), nil)},
+			},
+			wantRequests: []string{"cpu=600m"},
+		},
+		{
+			name: "init containers run with the sidecars started before them",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					testContainer("early", testResources("cpu", "700m"), nil),
+					testSidecar("proxy", testResources("cpu", "200m")),
+					testContainer("late", testResources("cpu", "600m"), nil),
+				},
+				Containers: []corev1.Container{testContainer("app", testResources("cpu", "100m"), nil)},
+			},
+			// late with proxy is larger than early alone and than app with
+			// proxy
+			wantRequests: []string{"cpu=800m"},
+		},
+		{
+			name: "overhead",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{testContainer("app", testResources("cpu", "100m"), testResources("cpu", "200m"))},
+				Overhead:   testResources("cpu", "50m", "memory", "20Mi"),
+			},
+			wantRequests: []string{"cpu=150m", "memory=20Mi"},
+			wantLimits:   []string{"cpu=250m", "memory=20Mi"},
+		},
+		{
+			name: "extended resources",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{testContainer("check", testResources("nvidia.com/gpu", "2"), nil)},
+				Containers: []corev1.Container{
+					testContainer("train", testResources("nvidia.com/gpu", "1"), testResources("nvidia.com/gpu", "1")),
+				},
+			},
+			wantRequests: []string{"nvidia.com/gpu=2"},
+			wantLimits:   []string{"nvidia.com/gpu=1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pd := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "x"}, Spec: tt.spec}
+			pr := GetPodResources(pd)
+			if got := formatResources(pr.Requests); !slices.Equal(got, tt.wantRequests) {
+				t.Errorf("got requests %v, want %v", got, tt.wantRequests)
+			}
+			if got := formatResources(pr.Limits); !slices.Equal(got, tt.wantLimits) {
+				t.Errorf("got limits %v, want %v", got, tt.wantLimits)
+			}
+		})
+	}
+}
+
+func TestAllocationNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		requests    corev1.ResourceList
+		limits      corev1.ResourceList
+		allocatable corev1.ResourceList
+		want        []corev1.ResourceName
+	}{
+		{
+			name: "standard resources always",
+			want: []corev1.ResourceName{"cpu", "memory", "ephemeral-storage"},
+		},
+		{
+			name:        "pods are no allocation",
+			allocatable: testResources("cpu", "4", "pods", "110"),
+			want:        []corev1.ResourceName{"cpu", "memory", "ephemeral-storage"},
+		},
+		{
+			name:     "requested and limited resources by name",
+			requests: testResources("nvidia.com/gpu", "1", "cpu", "1"),
+			limits:   testResources("example.com/dongle", "1", "hugepages-2Mi", "4Mi"),
+			want:     []corev1.ResourceName{"cpu", "memory", "ephemeral-storage", "example.com/dongle", "hugepages-2Mi", "nvidia.com/gpu"},
+		},
+		{
+			name:        "allocatable extended resources nobody requests",
+			allocatable: testResources("nvidia.com/gpu", "8", "amd.com/gpu", "0", "hugepages-1Gi", "2Gi"),
+			want:        []corev1.ResourceName{"cpu", "memory", "ephemeral-storage", "nvidia.com/gpu"},
+		},
+		{
+			name:        "requested resources are not listed twice",
+			requests:    testResources("nvidia.com/gpu", "1"),
+			limits:      testResources("nvidia.com/gpu", "1"),
+			allocatable: testResources("nvidia.com/gpu", "8"),
+			want:        []corev1.ResourceName{"cpu", "memory", "ephemeral-storage", "nvidia.com/gpu"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allocationNames(tt.requests, tt.limits, tt.allocatable); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCapacity(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, cpu string, memory string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: name},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				testContainer("app", testResources("cpu", cpu, "memory", memory), testResources("cpu", cpu)),
+			}},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	pds := []*corev1.Pod{
+		pod("small", corev1.PodRunning, "100m", "1Gi"),
+		pod("done", corev1.PodSucceeded, "4", "1Gi"),
+		pod("large", corev1.PodRunning, "1", "1Gi"),
+		pod("crashed", corev1.PodFailed, "4", "1Gi"),
+		pod("pending", corev1.PodPending, "100m", "2Gi"),
+	}
+	for i := range TopPodsLimit {
+		pds = append(pds, pod(fmt.Sprintf("tiny-%d", i), corev1.PodRunning, "10m", "1Mi"))
+	}
+
+	c := newCapacity(pds, testResources("cpu", "4", "memory", "16Gi", "pods", "110"))
+
+	if c.Pods != 3+TopPodsLimit {
+		t.Errorf("got %d pods, want %d", c.Pods, 3+TopPodsLimit)
+	}
+
+	var allocations []string
+	for _, a := range c.Allocations {
+		allocations = append(allocations, fmt.Sprintf("%s %s/%s of %s", a.Name, a.Requests.String(), a.Limits.String(), a.Allocatable.String()))
+	}
+	want := []string{
+		"cpu 1300m/1300m of 4",
+		"memory 4106Mi/0 of 16Gi",
+		"ephemeral-storage 0/0 of 0",
+	}
+	if !slices.Equal(allocations, want) {
+		t.Errorf("got allocations %v, want %v", allocations, want)
+	}
+	if ratio, ok := c.Allocations[0].RequestsRatio(); !ok || ratio != 0.325 {
+		t.Errorf("got cpu requests ratio %v, %v, want 0.325", ratio, ok)
+	}
+	if _, ok := c.Allocations[2].RequestsRatio(); ok {
+		t.Error("got a ratio of nothing allocatable")
+	}
+
+	// Most CPU first, then most memory
+	var top []string
+	for _, pr := range c.TopPods {
+		top = append(top, pr.Pod.Name)
+	}
+	if len(top) != TopPodsLimit || !slices.Equal(top[:3], []string{"large", "pending", "small"}) {
+		t.Errorf("got top pods %v", top)
+	}
+}
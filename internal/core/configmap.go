@@ -0,0 +1,97 @@
+package core
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"polar-bear/internal/store"
+)
+
+// WorkloadReference is a workload whose pod template refers to an object.
+type WorkloadReference struct {
+	Kind string
+	Name string
+	// Refs are how the template refers to the object
+	Refs []string
+}
+
+// GetPodsUsingConfigMap returns the pods of a namespace referring to a config
+// map, see ConfigMapReferences.
+func GetPodsUsingConfigMap(store store.Store, ns string, name string) []*corev1.Pod {
+	pds := GetPods(store, ns)
+	return slices.DeleteFunc(pds, func(pd *corev1.Pod) bool {
+		return len(ConfigMapReferences(&pd.Spec, name)) == 0
+	})
+}
+
+// GetWorkloadsUsingConfigMap returns the workloads of a namespace whose pod
+// template refers to a config map.
+func GetWorkloadsUsingConfigMap(store store.Store, ns string, name string) []WorkloadReference {
+	refs := make([]WorkloadReference, 0)
+	for _, tpl := range workloadTemplates(store, ns) {
+		if found := ConfigMapReferences(tpl.spec, name); len(found) > 0 {
+			refs = append(refs, WorkloadReference{Kind: tpl.kind, Name: tpl.name, Refs: found})
+		}
+	}
+	return refs
+}
+
+// ConfigMapReferences returns how a pod spec refers to a config map, by
+// volumes and environment variables.
+func ConfigMapReferences(spec *corev1.PodSpec, name string) []string {
+	refs := make([]string, 0)
+	for _, v := range spec.Volumes {
+		switch {
+		case v.ConfigMap != nil && v.ConfigMap.Name == name:
+			refs = append(refs, "volume "+v.Name)
+		case v.Projected != nil && slices.ContainsFunc(v.Projected.Sources, func(src corev1.VolumeProjection) bool {
+			return src.ConfigMap != nil && src.ConfigMap.Name == name
+		}):
+			refs = append(refs, "projected volume "+v.Name)
+		}
+	}
+	return append(refs, envReferences(spec, func(env corev1.EnvFromSource) bool {
+		return env.ConfigMapRef != nil && env.ConfigMapRef.Name == name
+	}, func(src *corev1.EnvVarSource) bool {
+		return src.ConfigMapKeyRef != nil && src.ConfigMapKeyRef.Name == name
+	})...)
+}
+
+// workloadTemplate is the pod template of a workload.
+type workloadTemplate struct {
+	kind string
+	name string
+	spec *corev1.PodSpec
+}
+
+// workloadTemplates returns the pod templates of the workloads of a namespace.
+// Replica sets and jobs created by another workload are left out, their
+// template is that of their owner.
+func workloadTemplates(store store.Store, ns string) []workloadTemplate {
+	tpls := make([]workloadTemplate, 0)
+	for _, deploy := range GetDeployments(store, ns) {
+		tpls = append(tpls, workloadTemplate{"Deployment", deploy.Name, &deploy.Spec.Template.Spec})
+	}
+	for _, rs := range GetReplicaSets(store, ns) {
+		if metav1.GetControllerOf(rs) == nil {
+			tpls = append(tpls, workloadTemplate{"ReplicaSet", rs.Name, &rs.Spec.Template.Spec})
+		}
+	}
+	for _, sts := range GetStatefulSets(store, ns) {
+		tpls = append(tpls, workloadTemplate{"StatefulSet", sts.Name, &sts.Spec.Template.Spec})
+	}
+	for _, ds := range GetDaemonSets(store, ns) {
+		tpls = append(tpls, workloadTemplate{"DaemonSet", ds.Name, &ds.Spec.Template.Spec})
+	}
+	for _, cj := range GetCronJobs(store, ns) {
+		tpls = append(tpls, workloadTemplate{"CronJob", cj.Name, &cj.Spec.JobTemplate.Spec.Template.Spec})
+	}
+	for _, job := range GetJobs(store, ns) {
+		if metav1.GetControllerOf(job) == nil {
+			tpls = append(tpls, workloadTemplate{"Job", job.Name, &job.Spec.Template.Spec})
+		}
+	}
+	return tpls
+}
@@ -0,0 +1,213 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"polar-bear/internal/store"
+)
+
+// CustomResourceType describes the version of a CustomResourceDefinition that
+// polar-bear watches and stores.
+type CustomResourceType struct {
+	Name       string // name of the CRD, always <plural>.<group>
+	Group      string
+	Version    string
+	Kind       string
+	Plural     string
+	Namespaced bool
+	Columns    []apiextensionsv1.CustomResourceColumnDefinition
+}
+
+// NewCustomResourceType picks the storage version of a CRD, falling back to the
+// first served version. Returns false if the CRD serves no version at all.
+func NewCustomResourceType(crd *apiextensionsv1.CustomResourceDefinition) (CustomResourceType, bool) {
+	var served *apiextensionsv1.CustomResourceDefinitionVersion
+	for i := range crd.Spec.Versions {
+		v := &crd.Spec.Versions[i]
+		if !v.Served {
+			continue
+		}
+		if served == nil || v.Storage {
+			served = v
+		}
+	}
+	if served == nil {
+		return CustomResourceType{}, false
+	}
+
+	return CustomResourceType{
+		Name:       crd.Name,
+		Group:      crd.Spec.Group,
+		Version:    served.Name,
+		Kind:       crd.Spec.Names.Kind,
+		Plural:     crd.Spec.Names.Plural,
+		Namespaced: crd.Spec.Scope == apiextensionsv1.NamespaceScoped,
+		Columns:    served.AdditionalPrinterColumns,
+	}, true
+}
+
+func (crt CustomResourceType) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    crt.Group,
+		Version:  crt.Version,
+		Resource: crt.Plural,
+	}
+}
+
+func (crt CustomResourceType) APIVersion() string {
+	return schema.GroupVersion{Group: crt.Group, Version: crt.Version}.String()
+}
+
+func CustomResourceKey(crt CustomResourceType, ns string, name string) ([]byte, error) {
+	if !crt.Namespaced {
+		return fmt.Appendf(nil, "cr/%s/%s/%s/%s", crt.Group, crt.Version, crt.Kind, name), nil
+	}
+
+	if ns == "" {
+		return []byte(""), fmt.Errorf("got empty namespace for namespaced custom resource")
+	}
+	return fmt.Appendf(nil, "ns/%s/cr/%s/%s/%s/%s", ns, crt.Group, crt.Version, crt.Kind, name), nil
+}
+
+// customResourcePrefix returns the prefix of the custom resources of a type
+// in a namespace, see kindPrefix.
+func customResourcePrefix(crt CustomResourceType, ns string) ([]byte, keyFilter, error) {
+	if !crt.Namespaced {
+		prefix, err := CustomResourceKey(crt, "", "")
+		return prefix, nil, err
+	}
+	return kindPrefix(func(ns string) ([]byte, error) { return CustomResourceKey(crt, ns, "") }, ns)
+}
+
+func GetCustomResourceType(store store.Store, name string) (CustomResourceType, bool) {
+	crd := GetCustomResourceDefinition(store, name)
+	if crd == nil {
+		return CustomResourceType{}, false
+	}
+	return NewCustomResourceType(crd)
+}
+
+func GetCustomResourceTypes(store store.Store) []CustomResourceType {
+	crds := GetCustomResourceDefinitions(store)
+
+	crts := make([]CustomResourceType, 0, len(crds))
+	for _, crd := range crds {
+		if crt, ok := NewCustomResourceType(crd); ok {
+			crts = append(crts, crt)
+		}
+	}
+
+	return crts
+}
+
+func GetCustomResource(
+	store store.Store,
+	crt CustomResourceType,
+	ns string,
+	name string,
+) *unstructured.Unstructured {
+	logger := slog.With("component", "core-customresource")
+
+	dbKey, err := CustomResourceKey(crt, ns, name)
+	if err != nil {
+		logger.Error(
+			"unable to get resource key",
+			"kind", crt.Name,
+			"namespace", ns,
+			"name", name,
+			"error", err,
+		)
+		return nil
+	}
+
+	defer observeDecode(store, crt.Name, time.Now())
+
+	res, err := getValue[*unstructured.Unstructured](store, dbKey)
+	if err != nil {
+		logger.Error(
+			"error on get from db",
+			"key", string(dbKey),
+			"error", err,
+		)
+		return nil
+	}
+
+	return res
+}
+
+func GetCustomResources(
+	store store.Store,
+	crt CustomResourceType,
+	ns string,
+) []*unstructured.Unstructured {
+	logger := slog.With("component", "core-customresource")
+
+	dbKey, match, err := customResourcePrefix(crt, ns)
+	if err != nil {
+		logger.Error(
+			"unable to get resource key",
+			"kind", crt.Name,
+			"namespace", ns,
+			"error", err,
+		)
+		return nil
+	}
+
+	defer observeDecode(store, crt.Name, time.Now())
+
+	resources, err := getValues[*unstructured.Unstructured](store, dbKey, match, func(key string, err error) {
+		logger.Error(
+			"error on unmarshal from json",
+			"namespace", ns,
+			"key", key,
+			"error", err,
+		)
+	})
+	if err != nil {
+		logger.Error(
+			"error on get all from db",
+			"key", string(dbKey),
+			"error", err,
+		)
+		return nil
+	}
+
+	return resources
+}
+
+func CountCustomResources(
+	store store.Store,
+	crt CustomResourceType,
+	ns string,
+) uint {
+	logger := slog.With("component", "core-customresource")
+
+	dbKey, match, err := customResourcePrefix(crt, ns)
+	if err != nil {
+		logger.Error(
+			"unable to get resource key",
+			"kind", crt.Name,
+			"namespace", ns,
+			"error", err,
+		)
+		return uint(0)
+	}
+
+	count, err := countValues(store, dbKey, match)
+	if err != nil {
+		logger.Error(
+			"error on get from db",
+			"key", string(dbKey),
+			"error", err,
+		)
+		return uint(0)
+	}
+
+	return count
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"polar-bear/internal/metrics"
+	"polar-bear/internal/store"
+)
+
+// storeMode returns the label value of the decode metrics for a store.
+func storeMode(db store.Store) string {
+	if _, ok := db.(store.ObjectStore); ok {
+		return "typed"
+	}
+	return "json"
+}
+
+func observeDecode(db store.Store, kind string, start time.Time) {
+	metrics.DecodeDuration.WithLabelValues(kind, storeMode(db)).Observe(time.Since(start).Seconds())
+}
+
+// decodeValue returns an object of a typed store as T, falling back to
+// unmarshalling for values the store only holds as JSON.
+func decodeValue[T any](obj any) (T, error) {
+	if res, ok := obj.(T); ok {
+		return res, nil
+	}
+
+	var res T
+	raw, ok := obj.(json.RawMessage)
+	if !ok {
+		return res, fmt.Errorf("unexpected type %T", obj)
+	}
+	err := json.Unmarshal(raw, &res)
+	return res, err
+}
+
+// getValue reads the resource stored under key.
+func getValue[T any](db store.Store, key []byte) (T, error) {
+	if os, ok := db.(store.ObjectStore); ok {
+		obj, found := os.GetObject(key)
+		if !found {
+			var zero T
+			return zero, fmt.Errorf("not found")
+		}
+		return decodeValue[T](obj)
+	}
+
+	var res T
+	keyVal, err := db.Get(key)
+	if err != nil {
+		return res, err
+	}
+	err = json.Unmarshal(keyVal, &res)
+	return res, err
+}
+
+// keyFilter selects the keys below a prefix to read, nil selects all of them.
+type keyFilter func(key string) bool
+
+// getValues reads all resources stored below prefix that match, sorted by
+// key. Values that can't be decoded are passed to onError and skipped.
+func getValues[T any](db store.Store, prefix []byte, match keyFilter, onError func(key string, err error)) ([]T, error) {
+	var decode func(key string) (T, error)
+	var keys []string
+
+	if os, ok := db.(store.ObjectStore); ok {
+		objs := os.GetAllObjects(prefix)
+		for key := range objs {
+			if match == nil || match(key) {
+				keys = append(keys, key)
+			}
+		}
+		decode = func(key string) (T, error) { return decodeValue[T](objs[key]) }
+	} else {
+		keyVals, err := db.GetAll(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for key := range keyVals {
+			if match == nil || match(key) {
+				keys = append(keys, key)
+			}
+		}
+		decode = func(key string) (T, error) {
+			var res T
+			err := json.Unmarshal(keyVals[key], &res)
+			return res, err
+		}
+	}
+	sort.Strings(keys)
+
+	resources := make([]T, 0, len(keys))
+	for _, key := range keys {
+		res, err := decode(key)
+		if err != nil {
+			onError(key, err)
+			continue
+		}
+		resources = append(resources, res)
+	}
+
+	return resources, nil
+}
+
+// countValues counts the resources stored below prefix that match, without
+// decoding them.
+func countValues(db store.Store, prefix []byte, match keyFilter) (uint, error) {
+	if match == nil {
+		return db.Count(prefix)
+	}
+
+	count := uint(0)
+	if os, ok := db.(store.ObjectStore); ok {
+		for key := range os.GetAllObjects(prefix) {
+			if match(key) {
+				count++
+			}
+		}
+		return count, nil
+	}
+
+	keyVals, err := db.GetAll(prefix)
+	if err != nil {
+		return 0, err
+	}
+	for key := range keyVals {
+		if match(key) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// kindPrefix returns the prefix of the keys of a kind in a namespace. For
+// AllNamespaces and namespaced kinds it is the prefix of all namespaced keys,
+// with a filter for the keys of the kind in any of them.
+func kindPrefix(kindKey func(ns string) ([]byte, error), ns string) ([]byte, keyFilter, error) {
+	if ns != AllNamespaces {
+		prefix, err := kindKey(ns)
+		return prefix, nil, err
+	}
+
+	// Namespace names can't contain an underscore, so "_" only fills the gap
+	prefix, err := kindKey("_")
+	if err != nil || !strings.HasPrefix(string(prefix), "ns/") {
+		// Cluster-scoped kinds ignore the namespace
+		return prefix, nil, err
+	}
+	kind := afterNamespace(string(prefix))
+	return []byte("ns/"), func(key string) bool {
+		return strings.HasPrefix(afterNamespace(key), kind)
+	}, nil
+}
+
+// afterNamespace returns the part of a namespaced key after its namespace,
+// keys are ns/{ns}/{kind}/{name}.
+func afterNamespace(key string) string {
+	rest, _ := strings.CutPrefix(key, "ns/")
+	_, rest, _ = strings.Cut(rest, "/")
+	return rest
+}
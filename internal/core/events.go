@@ -0,0 +1,94 @@
+package core
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"polar-bear/internal/store"
+)
+
+// EventTime returns the most recent point in time an event was observed at.
+// Depending on the reporting component only some of the fields are set.
+func EventTime(ev *eventsv1.Event) time.Time {
+	if ev.Series != nil && !ev.Series.LastObservedTime.IsZero() {
+		return ev.Series.LastObservedTime.Time
+	}
+	if !ev.DeprecatedLastTimestamp.IsZero() {
+		return ev.DeprecatedLastTimestamp.Time
+	}
+	if !ev.EventTime.IsZero() {
+		return ev.EventTime.Time
+	}
+	if !ev.DeprecatedFirstTimestamp.IsZero() {
+		return ev.DeprecatedFirstTimestamp.Time
+	}
+	return ev.CreationTimestamp.Time
+}
+
+// EventCount returns how often an event was observed.
+func EventCount(ev *eventsv1.Event) int32 {
+	if ev.Series != nil && ev.Series.Count > 0 {
+		return ev.Series.Count
+	}
+	if ev.DeprecatedCount > 0 {
+		return ev.DeprecatedCount
+	}
+	return 1
+}
+
+// SortEvents sorts events chronologically, oldest first.
+func SortEvents(evs []*eventsv1.Event) {
+	sort.SliceStable(evs, func(i, j int) bool {
+		return EventTime(evs[i]).Before(EventTime(evs[j]))
+	})
+}
+
+// GetEventsRegarding returns the events about a single object in chronological
+// order. Events about cluster-scoped objects are recorded in the default namespace.
+func GetEventsRegarding(store store.Store, kind string, ns string, name string) []*eventsv1.Event {
+	evNs := ns
+	if evNs == "" {
+		evNs = metav1.NamespaceDefault
+	}
+
+	evs := LookupResources[*eventsv1.Event](store, evNs, indexRegarding, regardingIndexValue(kind, ns, name))
+	SortEvents(evs)
+	return evs
+}
+
+// GetEventKeysRegarding returns the store keys of the events about a single
+// object, see GetEventsRegarding.
+func GetEventKeysRegarding(store store.Store, kind string, ns string, name string) []string {
+	return LookupKeys(store, indexRegarding, regardingIndexValue(kind, ns, name))
+}
+
+// GetRecentEvents returns the newest events of a namespace in chronological order.
+func GetRecentEvents(store store.Store, ns string, limit int) []*eventsv1.Event {
+	evs := GetEvents(store, ns)
+	SortEvents(evs)
+
+	if len(evs) > limit {
+		evs = evs[len(evs)-limit:]
+	}
+	return evs
+}
+
+// GetWarningEvents returns the warnings of all namespaces, newest first.
+func GetWarningEvents(store store.Store, limit int) []*eventsv1.Event {
+	evs := slices.DeleteFunc(GetEvents(store, AllNamespaces), func(ev *eventsv1.Event) bool {
+		return ev.Type != corev1.EventTypeWarning
+	})
+
+	SortEvents(evs)
+	slices.Reverse(evs)
+
+	if len(evs) > limit {
+		evs = evs[:limit]
+	}
+	return evs
+}
@@ -170,16 +170,6 @@ func GetCSINodes(store store.Store) []*storagev1.CSINode {
 	return GetResources[*storagev1.CSINode](store, "")
 }
 
-// CSIStorageCapacity
-
-func GetCSIStorageCapacity(store store.Store, name string) *storagev1.CSIStorageCapacity {
-	return GetResource[*storagev1.CSIStorageCapacity](store, "", name)
-}
-
-func GetCSIStorageCapacities(store store.Store) []*storagev1.CSIStorageCapacity {
-	return GetResources[*storagev1.CSIStorageCapacity](store, "")
-}
-
 // =============================================================================
 // NAMESPACED RESOURCES
 // =============================================================================
@@ -264,6 +254,9 @@ func GetCronJob(store store.Store, ns string, name string) *batchv1.CronJob {
 func GetCronJobs(store store.Store, ns string) []*batchv1.CronJob {
 	return GetResources[*batchv1.CronJob](store, ns)
 }
+func CountCronJobs(store store.Store, ns string) uint {
+	return CountResources[*batchv1.CronJob](store, ns)
+}
 
 // ReplicationController
 
@@ -294,6 +287,9 @@ func GetEndpointSlice(store store.Store, ns string, name string) *discoveryv1.En
 func GetEndpointSlices(store store.Store, ns string) []*discoveryv1.EndpointSlice {
 	return GetResources[*discoveryv1.EndpointSlice](store, ns)
 }
+func CountEndpointSlices(store store.Store, ns string) uint {
+	return CountResources[*discoveryv1.EndpointSlice](store, ns)
+}
 
 // Ingress
 
@@ -315,6 +311,9 @@ func GetNetworkPolicy(store store.Store, ns string, name string) *networkingv1.N
 func GetNetworkPolicies(store store.Store, ns string) []*networkingv1.NetworkPolicy {
 	return GetResources[*networkingv1.NetworkPolicy](store, ns)
 }
+func CountNetworkPolicies(store store.Store, ns string) uint {
+	return CountResources[*networkingv1.NetworkPolicy](store, ns)
+}
 
 // ConfigMap
 
@@ -324,6 +323,9 @@ func GetConfigMap(store store.Store, ns string, name string) *corev1.ConfigMap {
 func GetConfigMaps(store store.Store, ns string) []*corev1.ConfigMap {
 	return GetResources[*corev1.ConfigMap](store, ns)
 }
+func CountConfigMaps(store store.Store, ns string) uint {
+	return CountResources[*corev1.ConfigMap](store, ns)
+}
 
 // Secret
 
@@ -333,6 +335,9 @@ func GetSecret(store store.Store, ns string, name string) *corev1.Secret {
 func GetSecrets(store store.Store, ns string) []*corev1.Secret {
 	return GetResources[*corev1.Secret](store, ns)
 }
+func CountSecrets(store store.Store, ns string) uint {
+	return CountResources[*corev1.Secret](store, ns)
+}
 
 // PersistentVolumeClaim
 
@@ -342,6 +347,18 @@ func GetPersistentVolumeClaim(store store.Store, ns string, name string) *corev1
 func GetPersistentVolumeClaims(store store.Store, ns string) []*corev1.PersistentVolumeClaim {
 	return GetResources[*corev1.PersistentVolumeClaim](store, ns)
 }
+func CountPersistentVolumeClaims(store store.Store, ns string) uint {
+	return CountResources[*corev1.PersistentVolumeClaim](store, ns)
+}
+
+// CSIStorageCapacity
+
+func GetCSIStorageCapacity(store store.Store, ns string, name string) *storagev1.CSIStorageCapacity {
+	return GetResource[*storagev1.CSIStorageCapacity](store, ns, name)
+}
+func GetCSIStorageCapacities(store store.Store, ns string) []*storagev1.CSIStorageCapacity {
+	return GetResources[*storagev1.CSIStorageCapacity](store, ns)
+}
 
 // ServiceAccount
 
@@ -351,6 +368,9 @@ func GetServiceAccount(store store.Store, ns string, name string) *corev1.Servic
 func GetServiceAccounts(store store.Store, ns string) []*corev1.ServiceAccount {
 	return GetResources[*corev1.ServiceAccount](store, ns)
 }
+func CountServiceAccounts(store store.Store, ns string) uint {
+	return CountResources[*corev1.ServiceAccount](store, ns)
+}
 
 // Role
 
@@ -360,6 +380,9 @@ func GetRole(store store.Store, ns string, name string) *rbacv1.Role {
 func GetRoles(store store.Store, ns string) []*rbacv1.Role {
 	return GetResources[*rbacv1.Role](store, ns)
 }
+func CountRoles(store store.Store, ns string) uint {
+	return CountResources[*rbacv1.Role](store, ns)
+}
 
 // RoleBinding
 
@@ -369,6 +392,9 @@ func GetRoleBinding(store store.Store, ns string, name string) *rbacv1.RoleBindi
 func GetRoleBindings(store store.Store, ns string) []*rbacv1.RoleBinding {
 	return GetResources[*rbacv1.RoleBinding](store, ns)
 }
+func CountRoleBindings(store store.Store, ns string) uint {
+	return CountResources[*rbacv1.RoleBinding](store, ns)
+}
 
 // HorizontalPodAutoscaler
 
@@ -0,0 +1,346 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"polar-bear/internal/store"
+)
+
+// Store index names, the store parameters of the functions below shadow the package.
+const (
+	indexOwner     = store.IndexOwner
+	indexNode      = store.IndexNode
+	indexLabel     = store.IndexLabel
+	indexRegarding = store.IndexRegarding
+)
+
+var (
+	labelIndexValue     = store.LabelIndexValue
+	regardingIndexValue = store.RegardingIndexValue
+)
+
+// LookupResources returns the resources of kind T found under value in a
+// store index, sorted by key. An empty ns matches all namespaces.
+func LookupResources[T KubernetesResource](
+	store store.Store,
+	ns string,
+	index string,
+	value string,
+) []T {
+	resourceKind := getResourceKind[T]()
+	logger := slog.With("component", fmt.Sprintf("core-%s", resourceKind))
+
+	keys, err := store.Lookup(index, value)
+	if err != nil {
+		logger.Error(
+			"error on lookup from db",
+			"index", index,
+			"value", value,
+			"error", err,
+		)
+		return nil
+	}
+
+	return getIndexedResources[T](logger, store, resourceKind, ns, keys)
+}
+
+// SelectResources returns the resources of kind T carrying all labels of the
+// selector, sorted by key. An empty selector matches nothing.
+func SelectResources[T KubernetesResource](
+	store store.Store,
+	ns string,
+	selector map[string]string,
+) []T {
+	resourceKind := getResourceKind[T]()
+	logger := slog.With("component", fmt.Sprintf("core-%s", resourceKind))
+
+	var keys [][]byte
+	for i, k := range slices.Sorted(maps.Keys(selector)) {
+		found, err := store.Lookup(indexLabel, labelIndexValue(k, selector[k]))
+		if err != nil {
+			logger.Error(
+				"error on lookup from db",
+				"index", indexLabel,
+				"label", k,
+				"error", err,
+			)
+			return nil
+		}
+
+		if i == 0 {
+			keys = found
+			continue
+		}
+		set := make(map[string]struct{}, len(found))
+		for _, f := range found {
+			set[string(f)] = struct{}{}
+		}
+		keys = slices.DeleteFunc(keys, func(key []byte) bool {
+			_, ok := set[string(key)]
+			return !ok
+		})
+	}
+
+	return getIndexedResources[T](logger, store, resourceKind, ns, keys)
+}
+
+// getIndexedResources loads the resources of the given keys. Indexes span all
+// kinds, so keys of other kinds or namespaces are dropped by their prefix
+// before anything is decoded.
+func getIndexedResources[T KubernetesResource](
+	logger *slog.Logger,
+	store store.Store,
+	resourceKind string,
+	ns string,
+	keys [][]byte,
+) []T {
+	match, err := indexedKeyFilter(resourceKind, ns)
+	if err != nil {
+		logger.Error(
+			"unable to get resource key",
+			"kind", resourceKind,
+			"namespace", ns,
+			"error", err,
+		)
+		return nil
+	}
+
+	defer observeDecode(store, resourceKind, time.Now())
+
+	resources := make([]T, 0, len(keys))
+	for _, key := range keys {
+		if !match(string(key)) {
+			continue
+		}
+		resource, err := getValue[T](store, key)
+		if err != nil {
+			logger.Debug(
+				"skipping indexed key",
+				"key", string(key),
+				"error", err,
+			)
+			continue
+		}
+		resources = append(resources, resource)
+	}
+
+	return resources
+}
+
+// indexedKeyFilter selects the keys of a kind in a namespace, an empty ns
+// selects all namespaces.
+func indexedKeyFilter(resourceKind string, ns string) (keyFilter, error) {
+	if ns == "" {
+		ns = AllNamespaces
+	}
+	prefix, match, err := kindPrefix(func(ns string) ([]byte, error) { return ResourceKey(resourceKind, ns, "") }, ns)
+	if err != nil {
+		return nil, err
+	}
+	return func(key string) bool {
+		return strings.HasPrefix(key, string(prefix)) && (match == nil || match(key))
+	}, nil
+}
+
+// LookupKeys returns the store keys found under value in a store index, sorted.
+// Unlike LookupResources nothing is decoded, so the keys of deleted resources
+// are gone as well.
+func LookupKeys(store store.Store, index string, value string) []string {
+	keys, err := store.Lookup(index, value)
+	if err != nil {
+		slog.With("component", "core-index").Error(
+			"error on lookup from db",
+			"index", index,
+			"value", value,
+			"error", err,
+		)
+		return nil
+	}
+
+	strs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		strs = append(strs, string(key))
+	}
+	return strs
+}
+
+// GetPodKeysOnNode returns the store keys of the pods bound to a node.
+func GetPodKeysOnNode(store store.Store, node string) []string {
+	return LookupKeys(store, indexNode, node)
+}
+
+// GetPodsOnNode returns the pods of all namespaces bound to a node.
+func GetPodsOnNode(store store.Store, node string) []*corev1.Pod {
+	return LookupResources[*corev1.Pod](store, "", indexNode, node)
+}
+
+// GetPodsOwnedBy returns the pods with an owner reference to uid.
+func GetPodsOwnedBy(store store.Store, ns string, uid types.UID) []*corev1.Pod {
+	return LookupResources[*corev1.Pod](store, ns, indexOwner, string(uid))
+}
+
+// GetReplicaSetsOwnedBy returns the replica sets with an owner reference to uid.
+func GetReplicaSetsOwnedBy(store store.Store, ns string, uid types.UID) []*appsv1.ReplicaSet {
+	return LookupResources[*appsv1.ReplicaSet](store, ns, indexOwner, string(uid))
+}
+
+// GetDeploymentPods returns the pods owned by the replica sets of a deployment.
+func GetDeploymentPods(store store.Store, deploy *appsv1.Deployment) []*corev1.Pod {
+	pds := make([]*corev1.Pod, 0)
+	for _, rs := range GetReplicaSetsOwnedBy(store, deploy.Namespace, deploy.UID) {
+		pds = append(pds, GetPodsOwnedBy(store, deploy.Namespace, rs.UID)...)
+	}
+	return pds
+}
+
+// GetJobsOwnedBy returns the jobs with an owner reference to uid.
+func GetJobsOwnedBy(store store.Store, ns string, uid types.UID) []*batchv1.Job {
+	return LookupResources[*batchv1.Job](store, ns, indexOwner, string(uid))
+}
+
+// GetCronJobPods returns the pods owned by the jobs of a cron job.
+func GetCronJobPods(store store.Store, cj *batchv1.CronJob) []*corev1.Pod {
+	pds := make([]*corev1.Pod, 0)
+	for _, job := range GetJobsOwnedBy(store, cj.Namespace, cj.UID) {
+		pds = append(pds, GetPodsOwnedBy(store, cj.Namespace, job.UID)...)
+	}
+	return pds
+}
+
+// GetControllerRevisionsOwnedBy returns the revisions of a stateful set or
+// daemon set.
+func GetControllerRevisionsOwnedBy(store store.Store, ns string, uid types.UID) []*appsv1.ControllerRevision {
+	return LookupResources[*appsv1.ControllerRevision](store, ns, indexOwner, string(uid))
+}
+
+// GetPodsSelectedBy returns the pods of a namespace carrying all labels of a
+// service selector. Services without a selector select no pods.
+func GetPodsSelectedBy(store store.Store, ns string, selector map[string]string) []*corev1.Pod {
+	return SelectResources[*corev1.Pod](store, ns, selector)
+}
+
+// GetPodsMatching returns the pods of a namespace matched by a label selector
+// with expressions, an empty selector matches all of them.
+func GetPodsMatching(store store.Store, ns string, sel *metav1.LabelSelector) []*corev1.Pod {
+	selector, err := metav1.LabelSelectorAsSelector(sel)
+	if err != nil {
+		slog.Error("invalid label selector", "component", "core-pod", "error", err)
+		return nil
+	}
+
+	pds := GetPods(store, ns)
+	return slices.DeleteFunc(pds, func(pd *corev1.Pod) bool {
+		return !selector.Matches(labels.Set(pd.Labels))
+	})
+}
+
+// GetEndpointSlicesOfService returns the endpoint slices of a service.
+func GetEndpointSlicesOfService(store store.Store, ns string, name string) []*discoveryv1.EndpointSlice {
+	return SelectResources[*discoveryv1.EndpointSlice](store, ns, map[string]string{
+		discoveryv1.LabelServiceName: name,
+	})
+}
+
+// GetPodsUsingClaim returns the pods of a namespace mounting a persistent
+// volume claim, directly or as a generic ephemeral volume.
+func GetPodsUsingClaim(store store.Store, ns string, claim string) []*corev1.Pod {
+	pds := GetPods(store, ns)
+	return slices.DeleteFunc(pds, func(pd *corev1.Pod) bool {
+		return !slices.ContainsFunc(pd.Spec.Volumes, func(v corev1.Volume) bool {
+			return ClaimName(pd, v) == claim
+		})
+	})
+}
+
+// ClaimName returns the persistent volume claim a pod volume refers to, the
+// claims of ephemeral volumes are named after the pod and the volume. It is
+// empty for volumes of other types.
+func ClaimName(pd *corev1.Pod, v corev1.Volume) string {
+	switch {
+	case v.PersistentVolumeClaim != nil:
+		return v.PersistentVolumeClaim.ClaimName
+	case v.Ephemeral != nil:
+		return pd.Name + "-" + v.Name
+	default:
+		return ""
+	}
+}
+
+// GetPersistentVolumesOfClass returns the persistent volumes of a storage class.
+func GetPersistentVolumesOfClass(store store.Store, class string) []*corev1.PersistentVolume {
+	pvs := GetPersistentVolumes(store)
+	return slices.DeleteFunc(pvs, func(pv *corev1.PersistentVolume) bool {
+		return pv.Spec.StorageClassName != class
+	})
+}
+
+// GetStorageClassesOfProvisioner returns the storage classes provisioned by a
+// CSI driver.
+func GetStorageClassesOfProvisioner(store store.Store, provisioner string) []*storagev1.StorageClass {
+	scs := GetStorageClasses(store)
+	return slices.DeleteFunc(scs, func(sc *storagev1.StorageClass) bool {
+		return sc.Provisioner != provisioner
+	})
+}
+
+// GetVolumeAttachmentsOfVolume returns the attachments of a persistent volume.
+func GetVolumeAttachmentsOfVolume(store store.Store, pv string) []*storagev1.VolumeAttachment {
+	vas := GetVolumeAttachments(store)
+	return slices.DeleteFunc(vas, func(va *storagev1.VolumeAttachment) bool {
+		return va.Spec.Source.PersistentVolumeName == nil || *va.Spec.Source.PersistentVolumeName != pv
+	})
+}
+
+// GetVolumeAttachmentsOfAttacher returns the attachments made by a CSI driver.
+func GetVolumeAttachmentsOfAttacher(store store.Store, attacher string) []*storagev1.VolumeAttachment {
+	vas := GetVolumeAttachments(store)
+	return slices.DeleteFunc(vas, func(va *storagev1.VolumeAttachment) bool {
+		return va.Spec.Attacher != attacher
+	})
+}
+
+// GetCSINodesWithDriver returns the CSI nodes a driver is installed on.
+func GetCSINodesWithDriver(store store.Store, driver string) []*storagev1.CSINode {
+	cns := GetCSINodes(store)
+	return slices.DeleteFunc(cns, func(cn *storagev1.CSINode) bool {
+		return !slices.ContainsFunc(cn.Spec.Drivers, func(d storagev1.CSINodeDriver) bool {
+			return d.Name == driver
+		})
+	})
+}
+
+// GetCSIStorageCapacitiesOfClass returns the capacities CSI drivers report for
+// a storage class, they are published in the namespaces of the drivers.
+func GetCSIStorageCapacitiesOfClass(store store.Store, class string) []*storagev1.CSIStorageCapacity {
+	cscs := GetCSIStorageCapacities(store, AllNamespaces)
+	return slices.DeleteFunc(cscs, func(csc *storagev1.CSIStorageCapacity) bool {
+		return csc.StorageClassName != class
+	})
+}
+
+// GetPodsOfServiceAccount returns the pods of a namespace running as a service
+// account, pods without one run as the default service account.
+func GetPodsOfServiceAccount(store store.Store, ns string, sa string) []*corev1.Pod {
+	pds := GetPods(store, ns)
+	return slices.DeleteFunc(pds, func(pd *corev1.Pod) bool {
+		name := pd.Spec.ServiceAccountName
+		if name == "" {
+			name = "default"
+		}
+		return name != sa
+	})
+}
@@ -35,8 +35,8 @@ func ResourceKey(kind string, ns string, name string) ([]byte, error) {
 		return fmt.Appendf(nil, "csidriver/%s", name), nil
 	case "csinode":
 		return fmt.Appendf(nil, "csinode/%s", name), nil
-	case "csistoragecapacity":
-		return fmt.Appendf(nil, "csistoragecapacity/%s", name), nil
+	case "nodeusage":
+		return fmt.Appendf(nil, "nodeusage/%s", name), nil
 	}
 
 	// Namespaced resource kinds
@@ -82,6 +82,8 @@ func ResourceKey(kind string, ns string, name string) ([]byte, error) {
 		return fmt.Appendf(nil, "ns/%s/secret/%s", ns, name), nil
 	case "persistentvolumeclaim":
 		return fmt.Appendf(nil, "ns/%s/pvc/%s", ns, name), nil
+	case "csistoragecapacity":
+		return fmt.Appendf(nil, "ns/%s/csisc/%s", ns, name), nil
 
 	// Authorization resources
 	case "serviceaccount":
@@ -112,6 +114,8 @@ func ResourceKey(kind string, ns string, name string) ([]byte, error) {
 		return fmt.Appendf(nil, "ns/%s/event/%s", ns, name), nil
 	case "lease":
 		return fmt.Appendf(nil, "ns/%s/lease/%s", ns, name), nil
+	case "podusage":
+		return fmt.Appendf(nil, "ns/%s/podusage/%s", ns, name), nil
 
 	// Extension resources
 	case "mutatingwebhookconfiguration":
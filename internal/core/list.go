@@ -0,0 +1,325 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Sort orders of list pages, prefixed with "-" to reverse them.
+const (
+	SortName     = "name"
+	SortAge      = "age"
+	SortRestarts = "restarts"
+	SortStatus   = "status"
+)
+
+// ListOptions filter and sort the objects of a list page, they are kept in
+// the URL of the page. The zero value keeps all objects in key order.
+type ListOptions struct {
+	// Selector is a label selector as in app=web,tier!=cache
+	Selector string
+	// Fields is a field selector on the fields of ObjectFields
+	Fields string
+	// MinRestarts keeps pods with at least as many container restarts
+	MinRestarts int
+	// Namespaces keeps objects in one of the namespaces, on lists of all
+	// namespaces
+	Namespaces []string
+	// Sort is one of the sort orders, optionally prefixed with "-"
+	Sort string
+
+	labels labels.Selector
+	fields fields.Selector
+}
+
+// ParseListOptions returns the list options of the selector, field, restarts,
+// namespace and sort query parameters. Namespaces are separated by commas.
+func ParseListOptions(query url.Values) (ListOptions, error) {
+	opts := ListOptions{
+		Selector: strings.TrimSpace(query.Get("selector")),
+		Fields:   strings.TrimSpace(query.Get("field")),
+		Sort:     query.Get("sort"),
+	}
+
+	var err error
+	if opts.labels, err = labels.Parse(opts.Selector); err != nil {
+		return opts, fmt.Errorf("invalid label selector: %w", err)
+	}
+	if opts.fields, err = fields.ParseSelector(opts.Fields); err != nil {
+		return opts, fmt.Errorf("invalid field selector: %w", err)
+	}
+	for ns := range strings.SplitSeq(query.Get("namespace"), ",") {
+		if ns = strings.TrimSpace(ns); ns != "" && !slices.Contains(opts.Namespaces, ns) {
+			opts.Namespaces = append(opts.Namespaces, ns)
+		}
+	}
+	if restarts := query.Get("restarts"); restarts != "" {
+		if opts.MinRestarts, err = strconv.Atoi(restarts); err != nil || opts.MinRestarts < 0 {
+			return opts, fmt.Errorf("invalid restart count %q", restarts)
+		}
+	}
+	switch strings.TrimPrefix(opts.Sort, "-") {
+	case "", SortName, SortAge, SortRestarts, SortStatus:
+	default:
+		return opts, fmt.Errorf("invalid sort order %q", opts.Sort)
+	}
+	return opts, nil
+}
+
+// Query returns the query parameters of the options.
+func (o ListOptions) Query() url.Values {
+	query := url.Values{}
+	if o.Selector != "" {
+		query.Set("selector", o.Selector)
+	}
+	if o.Fields != "" {
+		query.Set("field", o.Fields)
+	}
+	if o.MinRestarts > 0 {
+		query.Set("restarts", strconv.Itoa(o.MinRestarts))
+	}
+	if len(o.Namespaces) > 0 {
+		query.Set("namespace", strings.Join(o.Namespaces, ","))
+	}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	return query
+}
+
+// IsZero reports whether the options keep all objects in key order.
+func (o ListOptions) IsZero() bool {
+	return o.Selector == "" && o.Fields == "" && o.MinRestarts == 0 && len(o.Namespaces) == 0 && o.Sort == ""
+}
+
+// LabelRequirements returns the requirements of the label selector.
+func (o ListOptions) LabelRequirements() []string {
+	if o.labels == nil {
+		return nil
+	}
+	reqs, _ := o.labels.Requirements()
+	strs := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		strs = append(strs, req.String())
+	}
+	return strs
+}
+
+// FieldRequirements returns the requirements of the field selector.
+func (o ListOptions) FieldRequirements() []string {
+	if o.fields == nil {
+		return nil
+	}
+	reqs := o.fields.Requirements()
+	strs := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		strs = append(strs, req.Field+string(req.Operator)+req.Value)
+	}
+	return strs
+}
+
+// Matches reports whether an object passes the filters.
+func (o ListOptions) Matches(obj metav1.Object) bool {
+	if o.labels != nil && !o.labels.Matches(labels.Set(obj.GetLabels())) {
+		return false
+	}
+	if o.fields != nil && !o.fields.Empty() && !o.fields.Matches(ObjectFields(obj)) {
+		return false
+	}
+	if len(o.Namespaces) > 0 && !slices.Contains(o.Namespaces, obj.GetNamespace()) {
+		return false
+	}
+	return o.MinRestarts == 0 || ObjectRestarts(obj) >= o.MinRestarts
+}
+
+// FilterObjects returns the objects passing the filters of the options, in
+// their sort order.
+func FilterObjects[T metav1.Object](objs []T, o ListOptions) []T {
+	objs = slices.DeleteFunc(objs, func(obj T) bool { return !o.Matches(obj) })
+
+	sort, desc := strings.CutPrefix(o.Sort, "-")
+	var compare func(a, b metav1.Object) int
+	switch sort {
+	case SortName:
+		compare = func(a, b metav1.Object) int { return strings.Compare(a.GetName(), b.GetName()) }
+	case SortAge:
+		// Youngest first, like the ascending age column of kubectl
+		compare = func(a, b metav1.Object) int {
+			return b.GetCreationTimestamp().Compare(a.GetCreationTimestamp().Time)
+		}
+	case SortRestarts:
+		compare = func(a, b metav1.Object) int { return cmp.Compare(ObjectRestarts(a), ObjectRestarts(b)) }
+	case SortStatus:
+		compare = func(a, b metav1.Object) int { return strings.Compare(ObjectStatus(a), ObjectStatus(b)) }
+	default:
+		return objs
+	}
+	slices.SortStableFunc(objs, func(a, b T) int {
+		if desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return objs
+}
+
+// ObjectFields returns the fields of an object the field selector of list
+// pages can match on. All objects have metadata.name, metadata.namespace and
+// status, see ObjectStatus.
+func ObjectFields(obj metav1.Object) fields.Set {
+	set := fields.Set{
+		"metadata.name":      obj.GetName(),
+		"metadata.namespace": obj.GetNamespace(),
+		"status":             ObjectStatus(obj),
+	}
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		set["status.phase"] = string(o.Status.Phase)
+		set["spec.nodeName"] = o.Spec.NodeName
+		set["spec.serviceAccountName"] = o.Spec.ServiceAccountName
+		set["status.podIP"] = o.Status.PodIP
+	case *corev1.Node:
+		set["spec.unschedulable"] = strconv.FormatBool(o.Spec.Unschedulable)
+	case *corev1.Namespace:
+		set["status.phase"] = string(o.Status.Phase)
+	case *corev1.PersistentVolume:
+		set["status.phase"] = string(o.Status.Phase)
+		set["spec.storageClassName"] = o.Spec.StorageClassName
+	case *corev1.PersistentVolumeClaim:
+		set["status.phase"] = string(o.Status.Phase)
+		set["spec.volumeName"] = o.Spec.VolumeName
+		if o.Spec.StorageClassName != nil {
+			set["spec.storageClassName"] = *o.Spec.StorageClassName
+		}
+	case *corev1.Service:
+		set["spec.type"] = string(o.Spec.Type)
+	case *corev1.Secret:
+		set["type"] = string(o.Type)
+	}
+	return set
+}
+
+// ObjectStatus returns a one word status of an object: the status of pods,
+// Ready or NotReady for nodes, Available or Unavailable for workloads, the
+// phase of jobs and of objects having one, and an empty string otherwise.
+func ObjectStatus(obj metav1.Object) string {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		return PodStatus(o)
+	case *corev1.Node:
+		if NodeReady(o) {
+			return "Ready"
+		}
+		return "NotReady"
+	case *corev1.Namespace:
+		return string(o.Status.Phase)
+	case *corev1.PersistentVolume:
+		return string(o.Status.Phase)
+	case *corev1.PersistentVolumeClaim:
+		return string(o.Status.Phase)
+	case *appsv1.Deployment:
+		return availability(o.Spec.Replicas, o.Status.AvailableReplicas)
+	case *appsv1.StatefulSet:
+		return availability(o.Spec.Replicas, o.Status.AvailableReplicas)
+	case *appsv1.ReplicaSet:
+		return availability(o.Spec.Replicas, o.Status.AvailableReplicas)
+	case *appsv1.DaemonSet:
+		return availability(&o.Status.DesiredNumberScheduled, o.Status.NumberAvailable)
+	case *batchv1.Job:
+		return JobStatus(o)
+	case *batchv1.CronJob:
+		if o.Spec.Suspend != nil && *o.Spec.Suspend {
+			return "Suspended"
+		}
+		return "Active"
+	}
+	return ""
+}
+
+func availability(desired *int32, available int32) string {
+	want := int32(1)
+	if desired != nil {
+		want = *desired
+	}
+	if available < want {
+		return "Unavailable"
+	}
+	return "Available"
+}
+
+// ObjectRestarts returns the container restarts of a pod, zero for other
+// objects.
+func ObjectRestarts(obj metav1.Object) int {
+	if pd, ok := obj.(*corev1.Pod); ok {
+		return PodRestarts(pd)
+	}
+	return 0
+}
+
+// PodStatus returns the status of a pod as kubectl shows it: Terminating,
+// the reason a container is waiting or terminated with, or its phase.
+func PodStatus(pd *corev1.Pod) string {
+	if pd.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	for _, cs := range slices.Concat(pd.Status.InitContainerStatuses, pd.Status.ContainerStatuses) {
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "PodInitializing":
+			return cs.State.Waiting.Reason
+		case cs.State.Terminated != nil && cs.State.Terminated.Reason != "" && cs.State.Terminated.Reason != "Completed":
+			return cs.State.Terminated.Reason
+		}
+	}
+	if pd.Status.Reason != "" {
+		return pd.Status.Reason
+	}
+	return string(pd.Status.Phase)
+}
+
+// PodRestarts returns the sum of the restarts of the containers of a pod.
+func PodRestarts(pd *corev1.Pod) int {
+	restarts := 0
+	for _, cs := range slices.Concat(pd.Status.InitContainerStatuses, pd.Status.ContainerStatuses) {
+		restarts += int(cs.RestartCount)
+	}
+	return restarts
+}
+
+// NodeReady reports whether the Ready condition of a node is true.
+func NodeReady(no *corev1.Node) bool {
+	for _, cond := range no.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// JobStatus returns whether a job is Complete, Failed, Suspended or Running.
+func JobStatus(job *batchv1.Job) string {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return "Complete"
+		case batchv1.JobFailed:
+			return "Failed"
+		case batchv1.JobSuspended:
+			return "Suspended"
+		}
+	}
+	return "Running"
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseListOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    ListOptions
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			query: "",
+			want:  ListOptions{},
+		},
+		{
+			name:  "all parameters",
+			query: "selector=app%3Dweb&field=status.phase%3DRunning&restarts=3&namespace=a,b&sort=-age",
+			want: ListOptions{
+				Selector:    "app=web",
+				Fields:      "status.phase=Running",
+				MinRestarts: 3,
+				Namespaces:  []string{"a", "b"},
+				Sort:        "-age",
+			},
+		},
+		{
+			name:  "selectors are trimmed",
+			query: "selector=+app%3Dweb+&field=+status%3DReady+",
+			want:  ListOptions{Selector: "app=web", Fields: "status=Ready"},
+		},
+		{
+			name:  "namespaces are trimmed and deduplicated",
+			query: "namespace=+a+,,b,a,",
+			want:  ListOptions{Namespaces: []string{"a", "b"}},
+		},
+		{
+			name:  "zero restarts",
+			query: "restarts=0",
+			want:  ListOptions{},
+		},
+		{
+			name:    "invalid label selector",
+			query:   "selector=app%3D%3D%3Dweb",
+			wantErr: true,
+		},
+		{
+			name:    "invalid field selector",
+			query:   "field=status",
+			wantErr: true,
+		},
+		{
+			name:    "restarts not a number",
+			query:   "restarts=many",
+			wantErr: true,
+		},
+		{
+			name:    "negative restarts",
+			query:   "restarts=-1",
+			wantErr: true,
+		},
+		{
+			name:    "unknown sort order",
+			query:   "sort=size",
+			wantErr: true,
+		},
+		{
+			name:    "unknown reverse sort order",
+			query:   "sort=-size",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("invalid query %q: %v", tt.query, err)
+			}
+
+			got, err := ParseListOptions(query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Selector != tt.want.Selector || got.Fields != tt.want.Fields ||
+				got.MinRestarts != tt.want.MinRestarts || got.Sort != tt.want.Sort ||
+				!slices.Equal(got.Namespaces, tt.want.Namespaces) {
+				t.Errorf("got options %+v, want %+v", got, tt.want)
+			}
+
+			// The query of the options parses to the same options
+			again, err := ParseListOptions(got.Query())
+			if err != nil {
+				t.Fatalf("unable to parse query %q of options: %v", got.Query().Encode(), err)
+			}
+			if again.Query().Encode() != got.Query().Encode() {
+				t.Errorf("got query %q after round trip, want %q", again.Query().Encode(), got.Query().Encode())
+			}
+		})
+	}
+}
+
+func testPod(ns string, name string, age time.Duration, restarts int32, phase corev1.PodPhase, labels map[string]string) *corev1.Pod {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         ns,
+			Name:              name,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+		},
+		Status: corev1.PodStatus{
+			Phase:             phase,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: restarts}},
+		},
+	}
+}
+
+func TestFilterObjects(t *testing.T) {
+	pods := func() []*corev1.Pod {
+		return []*corev1.Pod{
+			testPod("a", "web-1", 2*time.Hour, 0, corev1.PodRunning, map[string]string{"app": "web"}),
+			testPod("b", "db-1", time.Hour, 5, corev1.PodRunning, map[string]string{"app": "db"}),
+			testPod("a", "web-2", 3*time.Hour, 1, corev1.PodPending, map[string]string{"app": "web", "tier": "front"}),
+			testPod("c", "job-1", 30*time.Minute, 2, corev1.PodSucceeded, nil),
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "no options keep the order",
+			query: "",
+			want:  []string{"web-1", "db-1", "web-2", "job-1"},
+		},
+		{
+			name:  "label selector",
+			query: "selector=app%3Dweb",
+			want:  []string{"web-1", "web-2"},
+		},
+		{
+			name:  "label selector without label",
+			query: "selector=!tier",
+			want:  []string{"web-1", "db-1", "job-1"},
+		},
+		{
+			name:  "field selector on the phase",
+			query: "field=status.phase%3DRunning",
+			want:  []string{"web-1", "db-1"},
+		},
+		{
+			name:  "field selector on the name",
+			query: "field=metadata.name!%3Dweb-1",
+			want:  []string{"db-1", "web-2", "job-1"},
+		},
+		{
+			name:  "minimum restarts",
+			query: "restarts=2",
+			want:  []string{"db-1", "job-1"},
+		},
+		{
+			name:  "namespaces",
+			query: "namespace=a,c",
+			want:  []string{"web-1", "web-2", "job-1"},
+		},
+		{
+			name:  "combined filters",
+			query: "selector=app%3Dweb&restarts=1",
+			want:  []string{"web-2"},
+		},
+		{
+			name:  "sort by name",
+			query: "sort=name",
+			want:  []string{"db-1", "job-1", "web-1", "web-2"},
+		},
+		{
+			name:  "sort by name reversed",
+			query: "sort=-name",
+			want:  []string{"web-2", "web-1", "job-1", "db-1"},
+		},
+		{
+			name:  "sort by age youngest first",
+			query: "sort=age",
+			want:  []string{"job-1", "db-1", "web-1", "web-2"},
+		},
+		{
+			name:  "sort by restarts",
+			query: "sort=-restarts",
+			want:  []string{"db-1", "job-1", "web-2", "web-1"},
+		},
+		{
+			name:  "sort by status is stable",
+			query: "sort=status",
+			want:  []string{"web-2", "web-1", "db-1", "job-1"},
+		},
+		{
+			name:  "filter and sort",
+			query: "namespace=a&sort=-age",
+			want:  []string{"web-2", "web-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("invalid query %q: %v", tt.query, err)
+			}
+			opts, err := ParseListOptions(query)
+			if err != nil {
+				t.Fatalf("unable to parse options: %v", err)
+			}
+
+			var got []string
+			for _, pd := range FilterObjects(pods(), opts) {
+				got = append(got, pd.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+// manifestScheme knows the kinds of all typed resources in the store.
+var manifestScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(manifestScheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(manifestScheme))
+}
+
+// Manifest returns a stored object the way kubectl get -o shows it: with the
+// apiVersion and kind that informers drop from typed objects, and without
+// managed fields. The stored object is not modified.
+func Manifest(obj runtime.Object) (map[string]any, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		gvks, _, err := manifestScheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kind: %v", err)
+		}
+		gvk = gvks[0]
+	}
+
+	// Unstructured objects are converted to their own content, not a copy
+	if _, ok := obj.(runtime.Unstructured); ok {
+		obj = obj.DeepCopyObject()
+	}
+
+	res, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert object: %v", err)
+	}
+
+	res["apiVersion"], res["kind"] = gvk.GroupVersion().String(), gvk.Kind
+	unstructured.RemoveNestedField(res, "metadata", "managedFields")
+	return res, nil
+}
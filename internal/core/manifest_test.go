@@ -0,0 +1,114 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type unknownObject struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
+
+func (o *unknownObject) DeepCopyObject() runtime.Object {
+	c := *o
+	return &c
+}
+
+func TestManifest(t *testing.T) {
+	managedFields := []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}}
+
+	tests := []struct {
+		name           string
+		obj            func() runtime.Object
+		wantAPIVersion string
+		wantKind       string
+		wantErr        bool
+	}{
+		{
+			name: "core kind",
+			obj: func() runtime.Object {
+				return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "x", ManagedFields: managedFields}}
+			},
+			wantAPIVersion: "v1",
+			wantKind:       "Pod",
+		},
+		{
+			name: "kind of a group",
+			obj: func() runtime.Object {
+				return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "x", ManagedFields: managedFields}}
+			},
+			wantAPIVersion: "apps/v1",
+			wantKind:       "Deployment",
+		},
+		{
+			name: "kind already set",
+			obj: func() runtime.Object {
+				return &corev1.ConfigMap{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+					ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "x"},
+				}
+			},
+			wantAPIVersion: "v1",
+			wantKind:       "ConfigMap",
+		},
+		{
+			name: "custom resource",
+			obj: func() runtime.Object {
+				u := &unstructured.Unstructured{Object: map[string]any{
+					"apiVersion": "example.com/v1",
+					"kind":       "Widget",
+					"metadata":   map[string]any{"namespace": "a", "name": "x"},
+					"spec":       map[string]any{"size": int64(3)},
+				}}
+				u.SetManagedFields(managedFields)
+				return u
+			},
+			wantAPIVersion: "example.com/v1",
+			wantKind:       "Widget",
+		},
+		{
+			name: "kind unknown to the scheme",
+			obj: func() runtime.Object {
+				return &unknownObject{ObjectMeta: metav1.ObjectMeta{Name: "x"}}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := tt.obj()
+
+			res, err := Manifest(obj)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if res["apiVersion"] != tt.wantAPIVersion || res["kind"] != tt.wantKind {
+				t.Errorf("got %v %v, want %s %s", res["apiVersion"], res["kind"], tt.wantAPIVersion, tt.wantKind)
+			}
+			if _, found, _ := unstructured.NestedFieldNoCopy(res, "metadata", "managedFields"); found {
+				t.Error("kept managed fields")
+			}
+			if name, _, _ := unstructured.NestedString(res, "metadata", "name"); name != "x" {
+				t.Errorf("got name %q, want the name of the object", name)
+			}
+
+			// The stored object is not modified
+			fresh := tt.obj()
+			if !reflect.DeepEqual(obj, fresh) {
+				t.Errorf("object was modified: got %+v, want %+v", obj, fresh)
+			}
+		})
+	}
+}
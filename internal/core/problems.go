@@ -0,0 +1,214 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"polar-bear/internal/store"
+)
+
+// HighRestartCount is the number of container restarts from which a pod is
+// reported as restarting often.
+const HighRestartCount = 5
+
+// Problem is an object that needs attention.
+type Problem struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Reason is a short cause as in CrashLoopBackOff
+	Reason string
+	// Message explains the reason, it may be empty
+	Message string
+}
+
+// ProblemGroup are the problems of one kind of failure.
+type ProblemGroup struct {
+	Title    string
+	Problems []Problem
+}
+
+// GetProblems returns the problems of all namespaces and nodes, grouped in a
+// fixed order. Groups without problems are returned as well.
+func GetProblems(store store.Store) []ProblemGroup {
+	crashing := ProblemGroup{Title: "Crash Looping Pods"}
+	pulling := ProblemGroup{Title: "Image Pull Failures"}
+	restarting := ProblemGroup{Title: "Frequently Restarting Pods"}
+	unschedulable := ProblemGroup{Title: "Unschedulable Pods"}
+	for _, pd := range GetPods(store, AllNamespaces) {
+		if p, ok := waitingProblem(pd, "CrashLoopBackOff"); ok {
+			crashing.Problems = append(crashing.Problems, p)
+		}
+		if p, ok := waitingProblem(pd, "ErrImagePull", "ImagePullBackOff", "InvalidImageName"); ok {
+			pulling.Problems = append(pulling.Problems, p)
+		}
+		if restarts := PodRestarts(pd); restarts >= HighRestartCount {
+			restarting.Problems = append(restarting.Problems, Problem{
+				Kind:      "Pod",
+				Namespace: pd.Namespace,
+				Name:      pd.Name,
+				Reason:    fmt.Sprintf("%d restarts", restarts),
+				Message:   PodStatus(pd),
+			})
+		}
+		if cond := unschedulableCondition(pd); cond != nil {
+			unschedulable.Problems = append(unschedulable.Problems, Problem{
+				Kind:      "Pod",
+				Namespace: pd.Namespace,
+				Name:      pd.Name,
+				Reason:    cond.Reason,
+				Message:   cond.Message,
+			})
+		}
+	}
+
+	unavailable := ProblemGroup{Title: "Unavailable Workloads"}
+	for _, deploy := range GetDeployments(store, AllNamespaces) {
+		if p, ok := unavailableProblem("Deployment", deploy.Namespace, deploy.Name, deploy.Spec.Replicas, deploy.Status.AvailableReplicas); ok {
+			unavailable.Problems = append(unavailable.Problems, p)
+		}
+	}
+	for _, st := range GetStatefulSets(store, AllNamespaces) {
+		if p, ok := unavailableProblem("StatefulSet", st.Namespace, st.Name, st.Spec.Replicas, st.Status.AvailableReplicas); ok {
+			unavailable.Problems = append(unavailable.Problems, p)
+		}
+	}
+
+	nodes := ProblemGroup{Title: "Unhealthy Nodes"}
+	for _, no := range GetNodes(store) {
+		if p, ok := nodeProblem(no); ok {
+			nodes.Problems = append(nodes.Problems, p)
+		}
+	}
+
+	failed := ProblemGroup{Title: "Failed Jobs"}
+	for _, job := range GetJobs(store, AllNamespaces) {
+		if p, ok := failedJobProblem(job); ok {
+			failed.Problems = append(failed.Problems, p)
+		}
+	}
+
+	return []ProblemGroup{crashing, pulling, restarting, unavailable, unschedulable, nodes, failed}
+}
+
+// CountProblems returns the number of problems of all groups.
+func CountProblems(groups []ProblemGroup) int {
+	count := 0
+	for _, group := range groups {
+		count += len(group.Problems)
+	}
+	return count
+}
+
+// waitingProblem returns the problem of the first container of a pod waiting
+// for one of the reasons.
+func waitingProblem(pd *corev1.Pod, reasons ...string) (Problem, bool) {
+	for _, cs := range slices.Concat(pd.Status.InitContainerStatuses, pd.Status.ContainerStatuses) {
+		if cs.State.Waiting == nil || !slices.Contains(reasons, cs.State.Waiting.Reason) {
+			continue
+		}
+		msg := "Container " + cs.Name
+		if cs.State.Waiting.Message != "" {
+			msg += ": " + cs.State.Waiting.Message
+		}
+		return Problem{
+			Kind:      "Pod",
+			Namespace: pd.Namespace,
+			Name:      pd.Name,
+			Reason:    cs.State.Waiting.Reason,
+			Message:   msg,
+		}, true
+	}
+	return Problem{}, false
+}
+
+// unschedulableCondition returns the PodScheduled condition of a pending pod
+// the scheduler found no node for, nil otherwise.
+func unschedulableCondition(pd *corev1.Pod) *corev1.PodCondition {
+	if pd.Status.Phase != corev1.PodPending {
+		return nil
+	}
+	for i, cond := range pd.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse &&
+			cond.Reason == corev1.PodReasonUnschedulable {
+			return &pd.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// unavailableProblem reports a workload with fewer available replicas than
+// desired, which default to one.
+func unavailableProblem(kind string, ns string, name string, desired *int32, available int32) (Problem, bool) {
+	if availability(desired, available) == "Available" {
+		return Problem{}, false
+	}
+	want := int32(1)
+	if desired != nil {
+		want = *desired
+	}
+	return Problem{
+		Kind:      kind,
+		Namespace: ns,
+		Name:      name,
+		Reason:    "Unavailable",
+		Message:   fmt.Sprintf("%d of %d replicas available", available, want),
+	}, true
+}
+
+// nodeProblem reports a node that isn't ready or is under memory, disk or
+// process pressure.
+func nodeProblem(no *corev1.Node) (Problem, bool) {
+	reasons := make([]string, 0)
+	messages := make([]string, 0)
+	if !NodeReady(no) {
+		reasons = append(reasons, "NotReady")
+	}
+	for _, cond := range no.Status.Conditions {
+		switch {
+		case cond.Type == corev1.NodeReady && cond.Status != corev1.ConditionTrue:
+			// NotReady was added above, only keep its message
+		case cond.Type == corev1.NodeMemoryPressure && cond.Status == corev1.ConditionTrue:
+			reasons = append(reasons, string(cond.Type))
+		case cond.Type == corev1.NodeDiskPressure && cond.Status == corev1.ConditionTrue:
+			reasons = append(reasons, string(cond.Type))
+		case cond.Type == corev1.NodePIDPressure && cond.Status == corev1.ConditionTrue:
+			reasons = append(reasons, string(cond.Type))
+		default:
+			continue
+		}
+		if cond.Message != "" {
+			messages = append(messages, cond.Message)
+		}
+	}
+	if len(reasons) == 0 {
+		return Problem{}, false
+	}
+	return Problem{
+		Kind:    "Node",
+		Name:    no.Name,
+		Reason:  strings.Join(reasons, ", "),
+		Message: strings.Join(messages, "; "),
+	}, true
+}
+
+// failedJobProblem reports a job with a true Failed condition.
+func failedJobProblem(job *batchv1.Job) (Problem, bool) {
+	if JobStatus(job) != "Failed" {
+		return Problem{}, false
+	}
+	p := Problem{Kind: "Job", Namespace: job.Namespace, Name: job.Name, Reason: "Failed"}
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			if cond.Reason != "" {
+				p.Reason = cond.Reason
+			}
+			p.Message = cond.Message
+		}
+	}
+	return p, true
+}
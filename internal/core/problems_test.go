@@ -0,0 +1,322 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func nodeWithConditions(conds ...corev1.NodeCondition) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status:     corev1.NodeStatus{Conditions: conds},
+	}
+}
+
+func TestNodeProblem(t *testing.T) {
+	ready := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Message: "kubelet is posting ready status"}
+	notReady := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Message: "container runtime is down"}
+	unknown := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Message: "kubelet stopped posting"}
+	memory := corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue, Message: "memory low"}
+	disk := corev1.NodeCondition{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Message: "disk full"}
+	pid := corev1.NodeCondition{Type: corev1.NodePIDPressure, Status: corev1.ConditionTrue}
+	noMemory := corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse, Message: "enough memory"}
+
+	tests := []struct {
+		name        string
+		node        *corev1.Node
+		wantOK      bool
+		wantReason  string
+		wantMessage string
+	}{
+		{
+			name: "ready",
+			node: nodeWithConditions(ready, noMemory),
+		},
+		{
+			name:        "not ready",
+			node:        nodeWithConditions(notReady, noMemory),
+			wantOK:      true,
+			wantReason:  "NotReady",
+			wantMessage: "container runtime is down",
+		},
+		{
+			name:        "ready unknown",
+			node:        nodeWithConditions(unknown),
+			wantOK:      true,
+			wantReason:  "NotReady",
+			wantMessage: "kubelet stopped posting",
+		},
+		{
+			name:       "without ready condition",
+			node:       nodeWithConditions(),
+			wantOK:     true,
+			wantReason: "NotReady",
+		},
+		{
+			name:        "ready under pressure",
+			node:        nodeWithConditions(ready, memory, pid),
+			wantOK:      true,
+			wantReason:  "MemoryPressure, PIDPressure",
+			wantMessage: "memory low",
+		},
+		{
+			name:        "not ready and under pressure",
+			node:        nodeWithConditions(memory, notReady, disk),
+			wantOK:      true,
+			wantReason:  "NotReady, MemoryPressure, DiskPressure",
+			wantMessage: "memory low; container runtime is down; disk full",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := nodeProblem(tt.node)
+			if ok != tt.wantOK {
+				t.Fatalf("got problem %v, want %v", ok, tt.wantOK)
+			}
+			if p.Reason != tt.wantReason || p.Message != tt.wantMessage {
+				t.Errorf("got %q: %q, want %q: %q", p.Reason, p.Message, tt.wantReason, tt.wantMessage)
+			}
+			if ok && (p.Kind != "Node" || p.Name != "n1" || p.Namespace != "") {
+				t.Errorf("got problem of %s %s/%s, want the node", p.Kind, p.Namespace, p.Name)
+			}
+		})
+	}
+}
+
+func jobWithConditions(conds ...batchv1.JobCondition) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "backup"},
+		Status:     batchv1.JobStatus{Conditions: conds},
+	}
+}
+
+func TestFailedJobProblem(t *testing.T) {
+	tests := []struct {
+		name        string
+		job         *batchv1.Job
+		wantOK      bool
+		wantReason  string
+		wantMessage string
+	}{
+		{
+			name: "running",
+			job:  jobWithConditions(),
+		},
+		{
+			name: "complete",
+			job:  jobWithConditions(batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}),
+		},
+		{
+			name: "failed condition false",
+			job: jobWithConditions(batchv1.JobCondition{
+				Type: batchv1.JobFailed, Status: corev1.ConditionFalse, Reason: "BackoffLimitExceeded", Message: "old",
+			}),
+		},
+		{
+			name: "failed",
+			job: jobWithConditions(batchv1.JobCondition{
+				Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit",
+			}),
+			wantOK:      true,
+			wantReason:  "BackoffLimitExceeded",
+			wantMessage: "Job has reached the specified backoff limit",
+		},
+		{
+			name: "failed without reason",
+			job: jobWithConditions(
+				batchv1.JobCondition{Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"},
+				batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue},
+			),
+			wantOK:     true,
+			wantReason: "Failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := failedJobProblem(tt.job)
+			if ok != tt.wantOK {
+				t.Fatalf("got problem %v, want %v", ok, tt.wantOK)
+			}
+			if p.Reason != tt.wantReason || p.Message != tt.wantMessage {
+				t.Errorf("got %q: %q, want %q: %q", p.Reason, p.Message, tt.wantReason, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestUnschedulableCondition(t *testing.T) {
+	pending := func(conds ...corev1.PodCondition) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: conds}}
+	}
+	unschedulable := corev1.PodCondition{
+		Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable, Message: "0/3 nodes are available",
+	}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{
+			name: "unschedulable",
+			pod:  pending(corev1.PodCondition{Type: corev1.PodReady, Status: corev1.ConditionFalse}, unschedulable),
+			want: true,
+		},
+		{
+			name: "scheduled",
+			pod:  pending(corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}),
+		},
+		{
+			name: "gated",
+			pod: pending(corev1.PodCondition{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonSchedulingGated,
+			}),
+		},
+		{
+			name: "not pending",
+			pod: &corev1.Pod{Status: corev1.PodStatus{
+				Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{unschedulable},
+			}},
+		},
+		{
+			name: "without conditions",
+			pod:  pending(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := unschedulableCondition(tt.pod)
+			if (cond != nil) != tt.want {
+				t.Fatalf("got condition %v, want %v", cond, tt.want)
+			}
+			if cond != nil && cond.Message != unschedulable.Message {
+				t.Errorf("got message %q, want %q", cond.Message, unschedulable.Message)
+			}
+		})
+	}
+}
+
+func TestGetProblems(t *testing.T) {
+	db := newTestStore(t)
+
+	waiting := func(name string, reason string, restarts int32) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:         name,
+			RestartCount: restarts,
+			State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "back-off"}},
+		}
+	}
+	pod := func(name string, init []corev1.ContainerStatus, containers []corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: name},
+			Status: corev1.PodStatus{
+				Phase:                 corev1.PodPending,
+				InitContainerStatuses: init,
+				ContainerStatuses:     containers,
+			},
+		}
+	}
+
+	setTestObject(t, db, "pod", pod("init-crash", []corev1.ContainerStatus{waiting("migrate", "CrashLoopBackOff", 6)}, nil))
+	setTestObject(t, db, "pod", pod("crash", nil, []corev1.ContainerStatus{waiting("app", "CrashLoopBackOff", 2)}))
+	setTestObject(t, db, "pod", pod("pull", nil, []corev1.ContainerStatus{waiting("app", "ImagePullBackOff", 0)}))
+	setTestObject(t, db, "pod", pod("healthy", nil, []corev1.ContainerStatus{{Name: "app", Ready: true}}))
+	unschedulable := pod("pending", nil, nil)
+	unschedulable.Status.Conditions = []corev1.PodCondition{{
+		Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable, Message: "0/3 nodes are available",
+	}}
+	setTestObject(t, db, "pod", unschedulable)
+
+	deploy := func(name string, replicas *int32, available int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "b", Name: name},
+			Spec:       appsv1.DeploymentSpec{Replicas: replicas},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: available},
+		}
+	}
+	setTestObject(t, db, "deployment", deploy("default-down", nil, 0))
+	setTestObject(t, db, "deployment", deploy("default-up", nil, 1))
+	setTestObject(t, db, "deployment", deploy("scaled-down", new(int32), 0))
+	three := int32(3)
+	setTestObject(t, db, "deployment", deploy("partial", &three, 2))
+	setTestObject(t, db, "statefulset", &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "b", Name: "db"},
+		Status:     appsv1.StatefulSetStatus{AvailableReplicas: 0},
+	})
+
+	setTestObject(t, db, "node", nodeWithConditions(
+		corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+		corev1.NodeCondition{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+	))
+	setTestObject(t, db, "job", jobWithConditions(batchv1.JobCondition{
+		Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded",
+	}))
+
+	groups := GetProblems(db)
+
+	got := make(map[string][]string)
+	var titles []string
+	for _, group := range groups {
+		titles = append(titles, group.Title)
+		for _, p := range group.Problems {
+			got[group.Title] = append(got[group.Title], fmt.Sprintf("%s %s/%s %s: %s", p.Kind, p.Namespace, p.Name, p.Reason, p.Message))
+		}
+	}
+
+	wantTitles := []string{
+		"Crash Looping Pods",
+		"Image Pull Failures",
+		"Frequently Restarting Pods",
+		"Unavailable Workloads",
+		"Unschedulable Pods",
+		"Unhealthy Nodes",
+		"Failed Jobs",
+	}
+	if !slices.Equal(titles, wantTitles) {
+		t.Errorf("got groups %v, want %v", titles, wantTitles)
+	}
+
+	want := map[string][]string{
+		"Crash Looping Pods": {
+			"Pod a/crash CrashLoopBackOff: Container app: back-off",
+			"Pod a/init-crash CrashLoopBackOff: Container migrate: back-off",
+		},
+		"Image Pull Failures": {
+			"Pod a/pull ImagePullBackOff: Container app: back-off",
+		},
+		"Frequently Restarting Pods": {
+			"Pod a/init-crash 6 restarts: CrashLoopBackOff",
+		},
+		"Unavailable Workloads": {
+			"Deployment b/default-down Unavailable: 0 of 1 replicas available",
+			"Deployment b/partial Unavailable: 2 of 3 replicas available",
+			"StatefulSet b/db Unavailable: 0 of 1 replicas available",
+		},
+		"Unschedulable Pods": {
+			"Pod a/pending Unschedulable: 0/3 nodes are available",
+		},
+		"Unhealthy Nodes": {
+			"Node /n1 NotReady, DiskPressure: ",
+		},
+		"Failed Jobs": {
+			"Job a/backup BackoffLimitExceeded: ",
+		},
+	}
+	for _, title := range wantTitles {
+		if !slices.Equal(got[title], want[title]) {
+			t.Errorf("%s: got\n%v\nwant\n%v", title, got[title], want[title])
+		}
+	}
+	if count := CountProblems(groups); count != 10 {
+		t.Errorf("counted %d problems, want 10", count)
+	}
+}
@@ -0,0 +1,297 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"polar-bear/internal/store"
+)
+
+// Groups the API server adds to every authenticated user and service account.
+const (
+	groupAuthenticated   = "system:authenticated"
+	groupServiceAccounts = "system:serviceaccounts"
+	serviceAccountPrefix = "system:serviceaccount:"
+)
+
+// Grant is a rule granted to a subject by a binding.
+type Grant struct {
+	Rule rbacv1.PolicyRule
+	// RoleRef is the role the rule comes from, a role is in the namespace of
+	// its binding.
+	RoleRef rbacv1.RoleRef
+	// BindingKind is RoleBinding or ClusterRoleBinding, BindingNamespace is
+	// empty for the latter.
+	BindingKind      string
+	BindingNamespace string
+	BindingName      string
+}
+
+// Access is a grant allowing a subject to perform a request.
+type Access struct {
+	Subject rbacv1.Subject
+	Grant
+}
+
+// ParseSubject returns the subject of the given kind and name, service
+// accounts are named namespace:name. The user names of service accounts are
+// turned into service accounts.
+func ParseSubject(kind string, name string) rbacv1.Subject {
+	if kind == rbacv1.UserKind && strings.HasPrefix(name, serviceAccountPrefix) {
+		kind, name = rbacv1.ServiceAccountKind, strings.TrimPrefix(name, serviceAccountPrefix)
+	}
+	if kind == rbacv1.ServiceAccountKind {
+		ns, name, _ := strings.Cut(name, ":")
+		return rbacv1.Subject{Kind: kind, Namespace: ns, Name: name}
+	}
+	return rbacv1.Subject{Kind: kind, APIGroup: rbacv1.GroupName, Name: name}
+}
+
+// ClusterRoleRules returns the rules of a cluster role, including those of
+// the cluster roles it aggregates. The aggregation controller copies them
+// into the role, but it may lag behind or not run at all.
+func ClusterRoleRules(store store.Store, cr *rbacv1.ClusterRole) []rbacv1.PolicyRule {
+	rules := slices.Clone(cr.Rules)
+	for _, agg := range GetAggregatedClusterRoles(store, cr) {
+		for _, rule := range agg.Rules {
+			if !slices.ContainsFunc(rules, func(r rbacv1.PolicyRule) bool { return reflect.DeepEqual(r, rule) }) {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules
+}
+
+// GetAggregatedClusterRoles returns the cluster roles selected by the
+// aggregation rule of a cluster role, and those they aggregate in turn.
+func GetAggregatedClusterRoles(store store.Store, cr *rbacv1.ClusterRole) []*rbacv1.ClusterRole {
+	crs := GetClusterRoles(store)
+	visited := map[string]bool{cr.Name: true}
+	aggregated := make([]*rbacv1.ClusterRole, 0)
+
+	queue := []*rbacv1.ClusterRole{cr}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if next.AggregationRule == nil {
+			continue
+		}
+		for _, other := range crs {
+			if visited[other.Name] || !selectsAny(next.AggregationRule.ClusterRoleSelectors, other.Labels) {
+				continue
+			}
+			visited[other.Name] = true
+			aggregated = append(aggregated, other)
+			queue = append(queue, other)
+		}
+	}
+	return aggregated
+}
+
+func selectsAny(sels []metav1.LabelSelector, set map[string]string) bool {
+	for _, sel := range sels {
+		selector, err := metav1.LabelSelectorAsSelector(&sel)
+		if err == nil && !selector.Empty() && selector.Matches(labels.Set(set)) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetClusterRoleBindingsOfRole returns the cluster role bindings referring to
+// a cluster role.
+func GetClusterRoleBindingsOfRole(store store.Store, name string) []*rbacv1.ClusterRoleBinding {
+	crbs := GetClusterRoleBindings(store)
+	return slices.DeleteFunc(crbs, func(crb *rbacv1.ClusterRoleBinding) bool {
+		return crb.RoleRef.Name != name
+	})
+}
+
+// GetRoleBindingsOfRole returns the role bindings of a namespace referring to
+// a role of the given kind. Role bindings of cluster roles are searched in all
+// namespaces if ns is empty.
+func GetRoleBindingsOfRole(store store.Store, ns string, kind string, name string) []*rbacv1.RoleBinding {
+	rbs := make([]*rbacv1.RoleBinding, 0)
+	if ns == "" {
+		ns = AllNamespaces
+	}
+	for _, rb := range GetRoleBindings(store, ns) {
+		if rb.RoleRef.Kind == kind && rb.RoleRef.Name == name {
+			rbs = append(rbs, rb)
+		}
+	}
+	return rbs
+}
+
+// RoleRules returns the rules of the role a binding in namespace ns refers
+// to, nil if the role doesn't exist.
+func RoleRules(store store.Store, ns string, ref rbacv1.RoleRef) []rbacv1.PolicyRule {
+	switch ref.Kind {
+	case "ClusterRole":
+		if cr := GetClusterRole(store, ref.Name); cr != nil {
+			return ClusterRoleRules(store, cr)
+		}
+	case "Role":
+		if ns == "" {
+			return nil
+		}
+		if role := GetRole(store, ns, ref.Name); role != nil {
+			return role.Rules
+		}
+	}
+	return nil
+}
+
+// GrantsOf returns the rules granted to a subject in a namespace by the
+// cluster role bindings and the role bindings of the namespace. Only cluster
+// wide grants are returned if ns is empty.
+func GrantsOf(store store.Store, subject rbacv1.Subject, ns string) []Grant {
+	grants := make([]Grant, 0)
+	for _, crb := range GetClusterRoleBindings(store) {
+		if !slices.ContainsFunc(crb.Subjects, func(s rbacv1.Subject) bool { return SubjectMatches(s, "", subject) }) {
+			continue
+		}
+		for _, rule := range RoleRules(store, "", crb.RoleRef) {
+			grants = append(grants, Grant{
+				Rule:        rule,
+				RoleRef:     crb.RoleRef,
+				BindingKind: "ClusterRoleBinding",
+				BindingName: crb.Name,
+			})
+		}
+	}
+	if ns == "" {
+		return grants
+	}
+	for _, rb := range GetRoleBindings(store, ns) {
+		if !slices.ContainsFunc(rb.Subjects, func(s rbacv1.Subject) bool { return SubjectMatches(s, ns, subject) }) {
+			continue
+		}
+		for _, rule := range RoleRules(store, ns, rb.RoleRef) {
+			grants = append(grants, Grant{
+				Rule:             rule,
+				RoleRef:          rb.RoleRef,
+				BindingKind:      "RoleBinding",
+				BindingNamespace: ns,
+				BindingName:      rb.Name,
+			})
+		}
+	}
+	return grants
+}
+
+// SubjectsAllowed returns the subjects allowed to perform a verb on a
+// resource in a namespace, or cluster wide if ns is empty, with the grant
+// allowing it. The resource may name a subresource as in pods/log, an empty
+// name matches rules without resource names only.
+func SubjectsAllowed(
+	store store.Store,
+	verb string,
+	group string,
+	resource string,
+	name string,
+	ns string,
+) []Access {
+	accesses := make([]Access, 0)
+	add := func(subjects []rbacv1.Subject, bindingNs string, grant Grant, rules []rbacv1.PolicyRule) {
+		i := slices.IndexFunc(rules, func(rule rbacv1.PolicyRule) bool {
+			return RuleAllows(rule, verb, group, resource, name)
+		})
+		if i < 0 {
+			return
+		}
+		grant.Rule = rules[i]
+		for _, s := range subjects {
+			if s.Kind == rbacv1.ServiceAccountKind && s.Namespace == "" {
+				s.Namespace = bindingNs
+			}
+			accesses = append(accesses, Access{Subject: s, Grant: grant})
+		}
+	}
+
+	for _, crb := range GetClusterRoleBindings(store) {
+		add(crb.Subjects, "", Grant{
+			RoleRef:     crb.RoleRef,
+			BindingKind: "ClusterRoleBinding",
+			BindingName: crb.Name,
+		}, RoleRules(store, "", crb.RoleRef))
+	}
+	if ns == "" {
+		return accesses
+	}
+	for _, rb := range GetRoleBindings(store, ns) {
+		add(rb.Subjects, ns, Grant{
+			RoleRef:          rb.RoleRef,
+			BindingKind:      "RoleBinding",
+			BindingNamespace: ns,
+			BindingName:      rb.Name,
+		}, RoleRules(store, ns, rb.RoleRef))
+	}
+	return accesses
+}
+
+// SubjectMatches reports whether a subject of a binding in namespace ns
+// applies to the given subject, either directly or through the groups and
+// user name the API server assigns to it.
+func SubjectMatches(bound rbacv1.Subject, ns string, subject rbacv1.Subject) bool {
+	switch bound.Kind {
+	case rbacv1.ServiceAccountKind:
+		boundNs := bound.Namespace
+		if boundNs == "" {
+			boundNs = ns
+		}
+		return subject.Kind == rbacv1.ServiceAccountKind &&
+			bound.Name == subject.Name && boundNs == subject.Namespace
+	case rbacv1.UserKind:
+		switch subject.Kind {
+		case rbacv1.UserKind:
+			return bound.Name == subject.Name
+		case rbacv1.ServiceAccountKind:
+			return bound.Name == serviceAccountPrefix+subject.Namespace+":"+subject.Name
+		}
+	case rbacv1.GroupKind:
+		switch subject.Kind {
+		case rbacv1.GroupKind:
+			return bound.Name == subject.Name
+		case rbacv1.UserKind:
+			return bound.Name == groupAuthenticated
+		case rbacv1.ServiceAccountKind:
+			return bound.Name == groupAuthenticated ||
+				bound.Name == groupServiceAccounts ||
+				bound.Name == groupServiceAccounts+":"+subject.Namespace
+		}
+	}
+	return false
+}
+
+// RuleAllows reports whether a rule allows a verb on a resource, see
+// RuleAllows in k8s.io/kubernetes/pkg/apis/rbac/v1.
+func RuleAllows(rule rbacv1.PolicyRule, verb string, group string, resource string, name string) bool {
+	return matchesAny(rule.Verbs, verb) &&
+		matchesAny(rule.APIGroups, group) &&
+		resourceMatches(rule.Resources, resource) &&
+		(len(rule.ResourceNames) == 0 || (name != "" && slices.Contains(rule.ResourceNames, name)))
+}
+
+// matchesAny reports whether values contain the value or the wildcard.
+func matchesAny(values []string, value string) bool {
+	return slices.Contains(values, "*") || slices.Contains(values, value)
+}
+
+func resourceMatches(resources []string, resource string) bool {
+	_, sub, isSub := strings.Cut(resource, "/")
+	for _, r := range resources {
+		switch {
+		case r == rbacv1.ResourceAll, r == resource:
+			return true
+		case isSub && r == rbacv1.ResourceAll+"/"+sub:
+			return true
+		}
+	}
+	return false
+}
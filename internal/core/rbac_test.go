@@ -0,0 +1,261 @@
+package core
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestSubjectMatches(t *testing.T) {
+	user := rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"}
+	group := rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "devs"}
+	sa := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "apps", Name: "builder"}
+
+	tests := []struct {
+		name    string
+		bound   rbacv1.Subject
+		ns      string
+		subject rbacv1.Subject
+		want    bool
+	}{
+		{
+			name:    "same user",
+			bound:   rbacv1.Subject{Kind: rbacv1.UserKind, Name: "alice"},
+			subject: user,
+			want:    true,
+		},
+		{
+			name:    "other user",
+			bound:   rbacv1.Subject{Kind: rbacv1.UserKind, Name: "bob"},
+			subject: user,
+			want:    false,
+		},
+		{
+			name:    "same group",
+			bound:   rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "devs"},
+			subject: group,
+			want:    true,
+		},
+		{
+			name:    "user is not a group of the same name",
+			bound:   rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "alice"},
+			subject: user,
+			want:    false,
+		},
+		{
+			name:    "users are authenticated",
+			bound:   rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:authenticated"},
+			subject: user,
+			want:    true,
+		},
+		{
+			name:    "users are no service accounts",
+			bound:   rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts"},
+			subject: user,
+			want:    false,
+		},
+		{
+			name:    "same service account",
+			bound:   rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "apps", Name: "builder"},
+			ns:      "other",
+			subject: sa,
+			want:    true,
+		},
+		{
+			name:    "service account in the namespace of the binding",
+			bound:   rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "builder"},
+			ns:      "apps",
+			subject: sa,
+			want:    true,
+		},
+		{
+			name:    "service account in another namespace",
+			bound:   rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "builder"},
+			ns:      "other",
+			subject: sa,
+			want:    false,
+		},
+		{
+			name:    "service account by user name",
+			bound:   rbacv1.Subject{Kind: rbacv1.UserKind, Name: "system:serviceaccount:apps:builder"},
+			subject: sa,
+			want:    true,
+		},
+		{
+			name:    "service account by user name of another namespace",
+			bound:   rbacv1.Subject{Kind: rbacv1.UserKind, Name: "system:serviceaccount:other:builder"},
+			subject: sa,
+			want:    false,
+		},
+		{
+			name:    "service accounts are authenticated",
+			bound:   rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:authenticated"},
+			subject: sa,
+			want:    true,
+		},
+		{
+			name:    "all service accounts",
+			bound:   rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts"},
+			subject: sa,
+			want:    true,
+		},
+		{
+			name:    "service accounts of the namespace",
+			bound:   rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:apps"},
+			subject: sa,
+			want:    true,
+		},
+		{
+			name:    "service accounts of another namespace",
+			bound:   rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:other"},
+			subject: sa,
+			want:    false,
+		},
+		{
+			name:    "groups are no users",
+			bound:   rbacv1.Subject{Kind: rbacv1.UserKind, Name: "devs"},
+			subject: group,
+			want:    false,
+		},
+		{
+			name:    "unknown kind",
+			bound:   rbacv1.Subject{Kind: "Robot", Name: "alice"},
+			subject: user,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SubjectMatches(tt.bound, tt.ns, tt.subject); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     rbacv1.PolicyRule
+		verb     string
+		group    string
+		resource string
+		resName  string
+		want     bool
+	}{
+		{
+			name:     "exact match",
+			rule:     rbacv1.PolicyRule{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+			verb:     "get",
+			resource: "pods",
+			want:     true,
+		},
+		{
+			name:     "other verb",
+			rule:     rbacv1.PolicyRule{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+			verb:     "delete",
+			resource: "pods",
+			want:     false,
+		},
+		{
+			name:     "other group",
+			rule:     rbacv1.PolicyRule{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"deployments"}},
+			verb:     "get",
+			group:    "apps",
+			resource: "deployments",
+			want:     false,
+		},
+		{
+			name:     "other resource",
+			rule:     rbacv1.PolicyRule{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+			verb:     "get",
+			resource: "secrets",
+			want:     false,
+		},
+		{
+			name:     "wildcards",
+			rule:     rbacv1.PolicyRule{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+			verb:     "patch",
+			group:    "apps",
+			resource: "deployments/scale",
+			want:     true,
+		},
+		{
+			name:     "resource name listed",
+			rule:     rbacv1.PolicyRule{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"token"}},
+			verb:     "get",
+			resource: "secrets",
+			resName:  "token",
+			want:     true,
+		},
+		{
+			name:     "resource name not listed",
+			rule:     rbacv1.PolicyRule{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"token"}},
+			verb:     "get",
+			resource: "secrets",
+			resName:  "other",
+			want:     false,
+		},
+		{
+			name:     "resource names don't allow all names",
+			rule:     rbacv1.PolicyRule{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"token"}},
+			verb:     "list",
+			resource: "secrets",
+			want:     false,
+		},
+		{
+			name:     "no resource names allow any name",
+			rule:     rbacv1.PolicyRule{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}},
+			verb:     "get",
+			resource: "secrets",
+			resName:  "token",
+			want:     true,
+		},
+		{
+			name:     "empty rule",
+			rule:     rbacv1.PolicyRule{},
+			verb:     "get",
+			resource: "pods",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RuleAllows(tt.rule, tt.verb, tt.group, tt.resource, tt.resName); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceMatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []string
+		resource  string
+		want      bool
+	}{
+		{name: "none", resources: nil, resource: "pods", want: false},
+		{name: "exact", resources: []string{"services", "pods"}, resource: "pods", want: true},
+		{name: "other", resources: []string{"services"}, resource: "pods", want: false},
+		{name: "wildcard", resources: []string{"*"}, resource: "pods", want: true},
+		{name: "wildcard matches subresources", resources: []string{"*"}, resource: "pods/log", want: true},
+		{name: "exact subresource", resources: []string{"pods/log"}, resource: "pods/log", want: true},
+		{name: "resource doesn't match subresources", resources: []string{"pods"}, resource: "pods/log", want: false},
+		{name: "subresource doesn't match resource", resources: []string{"pods/log"}, resource: "pods", want: false},
+		{name: "other subresource", resources: []string{"pods/exec"}, resource: "pods/log", want: false},
+		{name: "subresource of all resources", resources: []string{"*/scale"}, resource: "deployments/scale", want: true},
+		{name: "other subresource of all resources", resources: []string{"*/scale"}, resource: "deployments/status", want: false},
+		{name: "subresource wildcard doesn't match resources", resources: []string{"*/scale"}, resource: "deployments", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resourceMatches(tt.resources, tt.resource); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,11 @@
 package core
 
 import (
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"reflect"
-	"sort"
 	"strings"
+	"time"
 
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -43,7 +42,6 @@ type KubernetesResource interface {
 		*storagev1.VolumeAttachment |
 		*storagev1.CSIDriver |
 		*storagev1.CSINode |
-		*storagev1.CSIStorageCapacity |
 
 		// Namespaced resources - Workloads
 		*corev1.Pod |
@@ -65,6 +63,7 @@ type KubernetesResource interface {
 		*corev1.ConfigMap |
 		*corev1.Secret |
 		*corev1.PersistentVolumeClaim |
+		*storagev1.CSIStorageCapacity |
 
 		// Namespaced resources - Authorization
 		*corev1.ServiceAccount |
@@ -86,6 +85,10 @@ type KubernetesResource interface {
 		*corev1.PodTemplate
 }
 
+// AllNamespaces selects the resources of a namespaced kind in all namespaces,
+// namespace names can't contain an underscore.
+const AllNamespaces = "_all"
+
 func getResourceKind[T KubernetesResource]() string {
 	var zero T
 	t := reflect.TypeOf(zero)
@@ -117,7 +120,9 @@ func GetResource[T KubernetesResource](
 		return zero
 	}
 
-	keyVal, err := store.Get(dbKey)
+	defer observeDecode(store, resourceKind, time.Now())
+
+	res, err := getValue[T](store, dbKey)
 	if err != nil {
 		logger.Error(
 			"error on get from db",
@@ -128,17 +133,6 @@ func GetResource[T KubernetesResource](
 		return zero
 	}
 
-	var res T
-	err = json.Unmarshal(keyVal, &res)
-	if err != nil {
-		logger.Error(
-			"error on unmarshal from json",
-			"namespace", ns,
-			"name", name,
-			"error", err,
-		)
-	}
-
 	return res
 }
 
@@ -149,7 +143,7 @@ func GetResources[T KubernetesResource](
 	resourceKind := getResourceKind[T]()
 	logger := slog.With("component", fmt.Sprintf("core-%s", resourceKind))
 
-	dbKey, err := ResourceKey(resourceKind, ns, "")
+	dbKey, match, err := kindPrefix(func(ns string) ([]byte, error) { return ResourceKey(resourceKind, ns, "") }, ns)
 	if err != nil {
 		logger.Error(
 			"unable to get resource key",
@@ -160,7 +154,16 @@ func GetResources[T KubernetesResource](
 		return nil
 	}
 
-	keyVals, err := store.GetAll(dbKey)
+	defer observeDecode(store, resourceKind, time.Now())
+
+	resources, err := getValues[T](store, dbKey, match, func(key string, err error) {
+		logger.Error(
+			"error on unmarshal from json",
+			"namespace", ns,
+			"key", key,
+			"error", err,
+		)
+	})
 	if err != nil {
 		logger.Error(
 			"error on get all from db",
@@ -170,28 +173,6 @@ func GetResources[T KubernetesResource](
 		return nil
 	}
 
-	names := make([]string, 0, len(keyVals))
-	for key := range keyVals {
-		names = append(names, key)
-	}
-	sort.Strings(names)
-
-	resources := make([]T, 0, len(names))
-	for _, name := range names {
-		var resource T
-		err := json.Unmarshal(keyVals[name], &resource)
-		if err != nil {
-			logger.Error(
-				"error on unmarshal from json",
-				"namespace", ns,
-				"name", name,
-				"error", err,
-			)
-			continue
-		}
-		resources = append(resources, resource)
-	}
-
 	return resources
 }
 
@@ -202,7 +183,7 @@ func CountResources[T KubernetesResource](
 	resourceKind := getResourceKind[T]()
 	logger := slog.With("component", fmt.Sprintf("core-%s", resourceKind))
 
-	dbKey, err := ResourceKey(resourceKind, ns, "")
+	dbKey, match, err := kindPrefix(func(ns string) ([]byte, error) { return ResourceKey(resourceKind, ns, "") }, ns)
 	if err != nil {
 		logger.Error(
 			"unable to get resource key",
@@ -213,7 +194,7 @@ func CountResources[T KubernetesResource](
 		return uint(0)
 	}
 
-	count, err := store.Count(dbKey)
+	count, err := countValues(store, dbKey, match)
 	if err != nil {
 		logger.Error(
 			"error on get from db",
@@ -0,0 +1,270 @@
+package core
+
+import (
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"polar-bear/internal/store"
+)
+
+// SearchResult is an object matching a search.
+type SearchResult struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Resource is the CRD name of custom resources, empty otherwise
+	Resource string
+	// Matches are the fields that matched, as in "label app=web"
+	Matches []string
+}
+
+// SearchGroup are the results of a search of one kind. Total counts all
+// matches, Results may be limited.
+type SearchGroup struct {
+	Kind    string
+	Results []SearchResult
+	Total   int
+}
+
+// searchKind lists the objects of a kind for the search.
+type searchKind struct {
+	kind       string
+	namespaced bool
+	list       func(store store.Store, ns string) []metav1.Object
+}
+
+func searchKindOf[T KubernetesResource](kind string, namespaced bool) searchKind {
+	return searchKind{
+		kind:       kind,
+		namespaced: namespaced,
+		list: func(store store.Store, ns string) []metav1.Object {
+			res := GetResources[T](store, ns)
+			objs := make([]metav1.Object, 0, len(res))
+			for _, obj := range res {
+				objs = append(objs, any(obj).(metav1.Object))
+			}
+			return objs
+		},
+	}
+}
+
+// searchKinds are the kinds with a detail page, in the order their results
+// are shown. Stored kinds without a page of their own, like events and
+// controller revisions, are not searched, results need a page to link to.
+var searchKinds = []searchKind{
+	searchKindOf[*corev1.Namespace]("Namespace", false),
+	searchKindOf[*corev1.Node]("Node", false),
+	searchKindOf[*corev1.Pod]("Pod", true),
+	searchKindOf[*appsv1.Deployment]("Deployment", true),
+	searchKindOf[*appsv1.ReplicaSet]("ReplicaSet", true),
+	searchKindOf[*appsv1.StatefulSet]("StatefulSet", true),
+	searchKindOf[*appsv1.DaemonSet]("DaemonSet", true),
+	searchKindOf[*batchv1.Job]("Job", true),
+	searchKindOf[*batchv1.CronJob]("CronJob", true),
+	searchKindOf[*corev1.Service]("Service", true),
+	searchKindOf[*discoveryv1.EndpointSlice]("EndpointSlice", true),
+	searchKindOf[*networkingv1.Ingress]("Ingress", true),
+	searchKindOf[*networkingv1.NetworkPolicy]("NetworkPolicy", true),
+	searchKindOf[*corev1.PersistentVolumeClaim]("PersistentVolumeClaim", true),
+	searchKindOf[*corev1.PersistentVolume]("PersistentVolume", false),
+	searchKindOf[*storagev1.StorageClass]("StorageClass", false),
+	searchKindOf[*storagev1.CSIDriver]("CSIDriver", false),
+	searchKindOf[*corev1.ConfigMap]("ConfigMap", true),
+	searchKindOf[*corev1.Secret]("Secret", true),
+	searchKindOf[*corev1.ServiceAccount]("ServiceAccount", true),
+	searchKindOf[*rbacv1.Role]("Role", true),
+	searchKindOf[*rbacv1.RoleBinding]("RoleBinding", true),
+	searchKindOf[*rbacv1.ClusterRole]("ClusterRole", false),
+	searchKindOf[*rbacv1.ClusterRoleBinding]("ClusterRoleBinding", false),
+}
+
+// Search returns the objects whose name, labels, annotations, container
+// images or IP addresses contain the query, ignoring case, grouped by kind.
+// Custom resources are grouped after the built-in kinds. At most limit
+// results are returned per kind, all of them if limit is zero.
+func Search(store store.Store, query string, limit int) []SearchGroup {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	groups := make([]SearchGroup, 0)
+	add := func(group SearchGroup) {
+		if group.Total == 0 {
+			return
+		}
+		if limit > 0 && len(group.Results) > limit {
+			group.Results = group.Results[:limit]
+		}
+		groups = append(groups, group)
+	}
+
+	for _, sk := range searchKinds {
+		group := SearchGroup{Kind: sk.kind}
+		for _, obj := range sk.list(store, searchNamespace(sk.namespaced)) {
+			if matches := searchObject(obj, query); len(matches) > 0 {
+				group.Total++
+				group.Results = append(group.Results, SearchResult{
+					Kind:      sk.kind,
+					Namespace: obj.GetNamespace(),
+					Name:      obj.GetName(),
+					Matches:   matches,
+				})
+			}
+		}
+		add(group)
+	}
+
+	for _, crt := range GetCustomResourceTypes(store) {
+		group := SearchGroup{Kind: crt.Kind}
+		for _, cr := range GetCustomResources(store, crt, searchNamespace(crt.Namespaced)) {
+			if matches := searchObject(cr, query); len(matches) > 0 {
+				group.Total++
+				group.Results = append(group.Results, SearchResult{
+					Kind:      crt.Kind,
+					Namespace: cr.GetNamespace(),
+					Name:      cr.GetName(),
+					Resource:  crt.Name,
+					Matches:   matches,
+				})
+			}
+		}
+		add(group)
+	}
+
+	return groups
+}
+
+// searchNamespace returns the namespace to list a kind in, all of them for
+// namespaced kinds.
+func searchNamespace(namespaced bool) string {
+	if namespaced {
+		return AllNamespaces
+	}
+	return ""
+}
+
+// searchObject returns the fields of an object containing the lower case
+// query.
+func searchObject(obj metav1.Object, query string) []string {
+	matches := make([]string, 0)
+	contains := func(value string) bool {
+		return strings.Contains(strings.ToLower(value), query)
+	}
+
+	if contains(obj.GetName()) {
+		matches = append(matches, "name")
+	}
+	for _, key := range sortedKeys(obj.GetLabels()) {
+		if value := obj.GetLabels()[key]; contains(key + "=" + value) {
+			matches = append(matches, "label "+key+"="+value)
+		}
+	}
+	annotations := obj.GetAnnotations()
+	if s, ok := obj.(*corev1.Secret); ok {
+		annotations = SecretAnnotations(s)
+	}
+	for _, key := range sortedKeys(annotations) {
+		// Values may be long documents, only name the key
+		if contains(key + "=" + annotations[key]) {
+			matches = append(matches, "annotation "+key)
+		}
+	}
+	for _, image := range searchImages(obj) {
+		if contains(image) {
+			matches = append(matches, "image "+image)
+		}
+	}
+	for _, ip := range searchIPs(obj) {
+		if contains(ip) {
+			matches = append(matches, "IP "+ip)
+		}
+	}
+	return matches
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// searchImages returns the container images of pods and workload templates.
+func searchImages(obj metav1.Object) []string {
+	var spec *corev1.PodSpec
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		spec = &o.Spec
+	case *appsv1.Deployment:
+		spec = &o.Spec.Template.Spec
+	case *appsv1.ReplicaSet:
+		spec = &o.Spec.Template.Spec
+	case *appsv1.StatefulSet:
+		spec = &o.Spec.Template.Spec
+	case *appsv1.DaemonSet:
+		spec = &o.Spec.Template.Spec
+	case *batchv1.Job:
+		spec = &o.Spec.Template.Spec
+	case *batchv1.CronJob:
+		spec = &o.Spec.JobTemplate.Spec.Template.Spec
+	default:
+		return nil
+	}
+	images := make([]string, 0)
+	for _, cnt := range specContainers(spec) {
+		if !slices.Contains(images, cnt.Image) {
+			images = append(images, cnt.Image)
+		}
+	}
+	return images
+}
+
+// searchIPs returns the IP addresses of pods, nodes, services, endpoint
+// slices and ingresses.
+func searchIPs(obj metav1.Object) []string {
+	ips := make([]string, 0)
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		ips = append(ips, o.Status.HostIP)
+		ips = append(ips, o.Status.PodIP)
+		for _, ip := range o.Status.PodIPs {
+			ips = append(ips, ip.IP)
+		}
+	case *corev1.Node:
+		for _, addr := range o.Status.Addresses {
+			if addr.Type == corev1.NodeInternalIP || addr.Type == corev1.NodeExternalIP {
+				ips = append(ips, addr.Address)
+			}
+		}
+	case *corev1.Service:
+		ips = append(ips, o.Spec.ClusterIPs...)
+		ips = append(ips, o.Spec.ExternalIPs...)
+		for _, ing := range o.Status.LoadBalancer.Ingress {
+			ips = append(ips, ing.IP)
+		}
+	case *discoveryv1.EndpointSlice:
+		for _, ep := range o.Endpoints {
+			ips = append(ips, ep.Addresses...)
+		}
+	case *networkingv1.Ingress:
+		for _, ing := range o.Status.LoadBalancer.Ingress {
+			ips = append(ips, ing.IP)
+		}
+	}
+	ips = slices.DeleteFunc(ips, func(ip string) bool {
+		return ip == "" || ip == corev1.ClusterIPNone
+	})
+	slices.Sort(ips)
+	return slices.Compact(ips)
+}
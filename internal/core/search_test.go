@@ -0,0 +1,263 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"polar-bear/internal/store"
+)
+
+func newTestStore(t *testing.T) store.Store {
+	t.Helper()
+
+	db, err := store.NewOtterStore(store.OtterOptions{Eviction: store.EvictionNone})
+	if err != nil {
+		t.Fatalf("unable to create store: %v", err)
+	}
+	return db
+}
+
+// setTestObject writes an object the way the informers do.
+func setTestObject(t *testing.T, db store.Store, kind string, obj metav1.Object) {
+	t.Helper()
+
+	key, err := ResourceKey(kind, obj.GetNamespace(), obj.GetName())
+	if err != nil {
+		t.Fatalf("invalid key: %v", err)
+	}
+	value, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("unable to marshal %s: %v", key, err)
+	}
+	if err := db.Set(key, value); err != nil {
+		t.Fatalf("unable to set %s: %v", key, err)
+	}
+}
+
+func seedSearchStore(t *testing.T) store.Store {
+	db := newTestStore(t)
+
+	setTestObject(t, db, "namespace", &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}})
+	setTestObject(t, db, "namespace", &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}})
+	setTestObject(t, db, "node", &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"zone": "eu-1"}},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			{Type: corev1.NodeHostName, Address: "worker-1.example.com"},
+		}},
+	})
+	setTestObject(t, db, "pod", &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "shop",
+			Name:        "Web-1",
+			Labels:      map[string]string{"App": "Frontend"},
+			Annotations: map[string]string{"team": "payments", "docs": "https://wiki/Frontend"},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.36"}},
+			Containers:     []corev1.Container{{Name: "app", Image: "registry/shop-web:1.2"}},
+		},
+		Status: corev1.PodStatus{HostIP: "10.0.0.1", PodIP: "10.1.0.5", PodIPs: []corev1.PodIP{{IP: "10.1.0.5"}, {IP: "fd00::5"}}},
+	})
+	setTestObject(t, db, "deployment", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "registry/shop-web:1.2"}},
+		}}},
+	})
+	setTestObject(t, db, "service", &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+		Spec:       corev1.ServiceSpec{ClusterIPs: []string{"10.96.0.10"}},
+	})
+	setTestObject(t, db, "service", &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "headless"},
+		Spec:       corev1.ServiceSpec{ClusterIPs: []string{corev1.ClusterIPNone}},
+	})
+	setTestObject(t, db, "secret", &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "shop",
+			Name:      "db",
+			Annotations: map[string]string{
+				"team":                "payments",
+				AnnotationSecretSizes: `{"password":12}`,
+				AnnotationSecretHash:  "0123456789abcdef",
+			},
+		},
+	})
+	for i := range 7 {
+		setTestObject(t, db, "configmap", &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: fmt.Sprintf("settings-%d", i)},
+		})
+	}
+
+	return db
+}
+
+// formatGroups returns the results of a search as "Kind ns/name: matches".
+func formatGroups(groups []SearchGroup) (results []string, totals map[string]int) {
+	totals = make(map[string]int)
+	for _, group := range groups {
+		totals[group.Kind] = group.Total
+		for _, res := range group.Results {
+			results = append(results, fmt.Sprintf("%s %s/%s: %s", res.Kind, res.Namespace, res.Name, strings.Join(res.Matches, ", ")))
+		}
+	}
+	return results, totals
+}
+
+func TestSearch(t *testing.T) {
+	db := seedSearchStore(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		limit      int
+		want       []string
+		wantTotals map[string]int
+	}{
+		{
+			name:  "empty query",
+			query: "  ",
+		},
+		{
+			name:  "nothing found",
+			query: "unknown",
+		},
+		{
+			name:  "names in kind order",
+			query: "web",
+			want: []string{
+				"Pod shop/Web-1: name, image registry/shop-web:1.2",
+				"Deployment shop/web: name, image registry/shop-web:1.2",
+				"Service shop/web: name",
+			},
+			wantTotals: map[string]int{"Pod": 1, "Deployment": 1, "Service": 1},
+		},
+		{
+			name:  "case is ignored",
+			query: " WEB-1 ",
+			want:  []string{"Pod shop/Web-1: name"},
+		},
+		{
+			name:  "label key and value",
+			query: "app=front",
+			want:  []string{"Pod shop/Web-1: label App=Frontend"},
+		},
+		{
+			name:  "label of a cluster object",
+			query: "eu-1",
+			want:  []string{"Node /worker-1: label zone=eu-1"},
+		},
+		{
+			name:  "annotation values name only the key",
+			query: "frontend",
+			want:  []string{"Pod shop/Web-1: label App=Frontend, annotation docs"},
+		},
+		{
+			name:  "annotations of secrets",
+			query: "payments",
+			want: []string{
+				"Pod shop/Web-1: annotation team",
+				"Secret shop/db: annotation team",
+			},
+		},
+		{
+			name:  "annotations added to secrets are not searched",
+			query: "polar-bear",
+		},
+		{
+			name:  "hash of secret data is not searched",
+			query: "0123456789",
+		},
+		{
+			name:  "images of init containers",
+			query: "busybox",
+			want:  []string{"Pod shop/Web-1: image busybox:1.36"},
+		},
+		{
+			name:  "IP of a pod and its node",
+			query: "10.0.0.1",
+			want: []string{
+				"Node /worker-1: IP 10.0.0.1",
+				"Pod shop/Web-1: IP 10.0.0.1",
+			},
+		},
+		{
+			name:  "IPv6 of a pod",
+			query: "FD00::",
+			want:  []string{"Pod shop/Web-1: IP fd00::5"},
+		},
+		{
+			name:  "IP prefix",
+			query: "10.96.",
+			want:  []string{"Service shop/web: IP 10.96.0.10"},
+		},
+		{
+			name:  "headless services have no IP",
+			query: "none",
+		},
+		{
+			name:  "node addresses other than IPs",
+			query: "example.com",
+		},
+		{
+			name:  "limit per kind",
+			query: "settings",
+			limit: 5,
+			want: []string{
+				"ConfigMap other/settings-0: name",
+				"ConfigMap other/settings-1: name",
+				"ConfigMap other/settings-2: name",
+				"ConfigMap other/settings-3: name",
+				"ConfigMap other/settings-4: name",
+			},
+			wantTotals: map[string]int{"ConfigMap": 7},
+		},
+		{
+			name:  "limit above the matches",
+			query: "web",
+			limit: 5,
+			want: []string{
+				"Pod shop/Web-1: name, image registry/shop-web:1.2",
+				"Deployment shop/web: name, image registry/shop-web:1.2",
+				"Service shop/web: name",
+			},
+			wantTotals: map[string]int{"Pod": 1, "Deployment": 1, "Service": 1},
+		},
+		{
+			name:  "no limit",
+			query: "settings-",
+			want: []string{
+				"ConfigMap other/settings-0: name",
+				"ConfigMap other/settings-1: name",
+				"ConfigMap other/settings-2: name",
+				"ConfigMap other/settings-3: name",
+				"ConfigMap other/settings-4: name",
+				"ConfigMap other/settings-5: name",
+				"ConfigMap other/settings-6: name",
+			},
+			wantTotals: map[string]int{"ConfigMap": 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, totals := formatGroups(Search(db, tt.query, tt.limit))
+			if !slices.Equal(results, tt.want) {
+				t.Errorf("got results\n%s\nwant\n%s", strings.Join(results, "\n"), strings.Join(tt.want, "\n"))
+			}
+			for kind, want := range tt.wantTotals {
+				if totals[kind] != want {
+					t.Errorf("got total %d of %s, want %d", totals[kind], kind, want)
+				}
+			}
+		})
+	}
+}
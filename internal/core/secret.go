@@ -0,0 +1,125 @@
+package core
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"polar-bear/internal/store"
+)
+
+// Annotations the secret informer replaces the values of a secret with.
+const (
+	AnnotationSecretSizes = "polar-bear/data-sizes"
+	AnnotationSecretHash  = "polar-bear/data-hash"
+)
+
+// SecretKey is a key of a stored secret, whose value is never stored.
+type SecretKey struct {
+	Name string
+	Size int
+}
+
+// SecretKeys returns the keys of a secret with the sizes of their values,
+// sorted by name.
+func SecretKeys(s *corev1.Secret) []SecretKey {
+	sizes := make(map[string]int)
+	_ = json.Unmarshal([]byte(s.Annotations[AnnotationSecretSizes]), &sizes)
+
+	keys := make([]SecretKey, 0, len(s.Data))
+	for _, name := range slices.Sorted(maps.Keys(s.Data)) {
+		keys = append(keys, SecretKey{Name: name, Size: sizes[name]})
+	}
+	return keys
+}
+
+// SecretHash returns a hash of all values of a secret, it changes whenever a
+// value does. The hash is keyed per process, so it changes on restarts too.
+func SecretHash(s *corev1.Secret) string {
+	return s.Annotations[AnnotationSecretHash]
+}
+
+// SecretAnnotations returns the annotations of a secret without those added
+// by polar-bear.
+func SecretAnnotations(s *corev1.Secret) map[string]string {
+	annotations := maps.Clone(s.Annotations)
+	maps.DeleteFunc(annotations, func(k string, _ string) bool {
+		return k == AnnotationSecretSizes || k == AnnotationSecretHash
+	})
+	return annotations
+}
+
+// GetPodsUsingSecret returns the pods of a namespace referring to a secret,
+// see SecretReferences.
+func GetPodsUsingSecret(store store.Store, ns string, name string) []*corev1.Pod {
+	pds := GetPods(store, ns)
+	return slices.DeleteFunc(pds, func(pd *corev1.Pod) bool {
+		return len(SecretReferences(&pd.Spec, name)) == 0
+	})
+}
+
+// SecretReferences returns how a pod spec refers to a secret, by volumes,
+// environment variables and image pull secrets.
+func SecretReferences(spec *corev1.PodSpec, name string) []string {
+	refs := make([]string, 0)
+	for _, v := range spec.Volumes {
+		switch {
+		case v.Secret != nil && v.Secret.SecretName == name:
+			refs = append(refs, "volume "+v.Name)
+		case v.Projected != nil && slices.ContainsFunc(v.Projected.Sources, func(src corev1.VolumeProjection) bool {
+			return src.Secret != nil && src.Secret.Name == name
+		}):
+			refs = append(refs, "projected volume "+v.Name)
+		}
+	}
+	refs = append(refs, envReferences(spec, func(env corev1.EnvFromSource) bool {
+		return env.SecretRef != nil && env.SecretRef.Name == name
+	}, func(src *corev1.EnvVarSource) bool {
+		return src.SecretKeyRef != nil && src.SecretKeyRef.Name == name
+	})...)
+	for _, ref := range spec.ImagePullSecrets {
+		if ref.Name == name {
+			refs = append(refs, "image pull secret")
+		}
+	}
+	return refs
+}
+
+// envReferences returns the containers of a pod spec taking all their
+// environment from a source matching envFrom, and the variables taken from a
+// source matching valueFrom.
+func envReferences(
+	spec *corev1.PodSpec,
+	envFrom func(env corev1.EnvFromSource) bool,
+	valueFrom func(src *corev1.EnvVarSource) bool,
+) []string {
+	refs := make([]string, 0)
+	for _, cnt := range specContainers(spec) {
+		if slices.ContainsFunc(cnt.EnvFrom, envFrom) {
+			refs = append(refs, "all env of "+cnt.Name)
+		}
+		vars := make([]string, 0)
+		for _, env := range cnt.Env {
+			if env.ValueFrom != nil && valueFrom(env.ValueFrom) {
+				vars = append(vars, env.Name)
+			}
+		}
+		if len(vars) > 0 {
+			refs = append(refs, "env "+strings.Join(vars, ", ")+" of "+cnt.Name)
+		}
+	}
+	return refs
+}
+
+// specContainers returns the init, regular and ephemeral containers of a pod
+// spec.
+func specContainers(spec *corev1.PodSpec) []corev1.Container {
+	cnts := slices.Concat(spec.InitContainers, spec.Containers)
+	for _, ec := range spec.EphemeralContainers {
+		cnts = append(cnts, corev1.Container(ec.EphemeralContainerCommon))
+	}
+	return cnts
+}
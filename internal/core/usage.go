@@ -0,0 +1,148 @@
+package core
+
+import (
+	"log/slog"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"polar-bear/internal/store"
+)
+
+// UsageHistory is how long usage samples are kept for sparklines.
+const UsageHistory = time.Hour
+
+// usageNames are the resources the metrics.k8s.io API reports usage of.
+var usageNames = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// Usage is the CPU and memory usage of a pod or node as reported by the
+// metrics.k8s.io API. Pods add up their containers.
+type Usage struct {
+	// Timestamp and Window are the end and length of the latest sample
+	Timestamp time.Time
+	Window    time.Duration
+	Usage     corev1.ResourceList
+	// History are the samples of the last UsageHistory, oldest first and
+	// ending with the latest one
+	History []UsageSample
+}
+
+// UsageSample is the usage at one point in time.
+type UsageSample struct {
+	Time  time.Time
+	Usage corev1.ResourceList
+}
+
+// Series returns the time and amount of a resource of each sample in the
+// history, samples without it are skipped.
+func (u *Usage) Series(name corev1.ResourceName) ([]time.Time, []float64) {
+	times := make([]time.Time, 0, len(u.History))
+	values := make([]float64, 0, len(u.History))
+	for _, sample := range u.History {
+		q, ok := sample.Usage[name]
+		if !ok {
+			continue
+		}
+		times = append(times, sample.Time)
+		values = append(values, q.AsApproximateFloat64())
+	}
+	return times, values
+}
+
+// ResourceUsage compares the usage of a resource with the requests and limits
+// of a pod, or of the pods on a node and its allocatable amount.
+type ResourceUsage struct {
+	Name     corev1.ResourceName
+	Usage    resource.Quantity
+	Requests resource.Quantity
+	Limits   resource.Quantity
+	// Allocatable is only set for nodes
+	Allocatable resource.Quantity
+}
+
+// RequestsRatio returns the share of the requests that is used, false if
+// nothing is requested.
+func (ru ResourceUsage) RequestsRatio() (float64, bool) {
+	return quantityRatio(ru.Usage, ru.Requests)
+}
+
+// LimitsRatio returns the share of the limits that is used, false if there
+// are no limits.
+func (ru ResourceUsage) LimitsRatio() (float64, bool) {
+	return quantityRatio(ru.Usage, ru.Limits)
+}
+
+// AllocatableRatio returns the share of the allocatable amount that is used,
+// false if nothing is allocatable.
+func (ru ResourceUsage) AllocatableRatio() (float64, bool) {
+	return quantityRatio(ru.Usage, ru.Allocatable)
+}
+
+// PodUsage compares the usage of a pod with its effective requests and limits.
+func PodUsage(pd *corev1.Pod, u *Usage) []ResourceUsage {
+	pr := GetPodResources(pd)
+	rus := make([]ResourceUsage, 0, len(usageNames))
+	for _, name := range usageNames {
+		rus = append(rus, ResourceUsage{
+			Name:     name,
+			Usage:    u.Usage[name],
+			Requests: pr.Requests[name],
+			Limits:   pr.Limits[name],
+		})
+	}
+	return rus
+}
+
+// NodeUsage compares the usage of a node with the requests and limits of its
+// pods and its allocatable amount.
+func NodeUsage(no *corev1.Node, c Capacity, u *Usage) []ResourceUsage {
+	rus := make([]ResourceUsage, 0, len(usageNames))
+	for _, name := range usageNames {
+		ru := ResourceUsage{
+			Name:        name,
+			Usage:       u.Usage[name],
+			Allocatable: no.Status.Allocatable[name],
+		}
+		for _, a := range c.Allocations {
+			if a.Name == name {
+				ru.Requests, ru.Limits = a.Requests, a.Limits
+			}
+		}
+		rus = append(rus, ru)
+	}
+	return rus
+}
+
+// GetPodUsage returns the usage of a pod, nil if there is no sample of it.
+func GetPodUsage(store store.Store, ns string, name string) *Usage {
+	return getUsage(store, "podusage", ns, name)
+}
+
+// GetNodeUsage returns the usage of a node, nil if there is no sample of it.
+func GetNodeUsage(store store.Store, name string) *Usage {
+	return getUsage(store, "nodeusage", "", name)
+}
+
+func getUsage(store store.Store, kind string, ns string, name string) *Usage {
+	dbKey, err := ResourceKey(kind, ns, name)
+	if err != nil {
+		slog.With("component", "core-"+kind).Error(
+			"unable to get usage key",
+			"kind", kind,
+			"namespace", ns,
+			"name", name,
+			"error", err,
+		)
+		return nil
+	}
+
+	defer observeDecode(store, kind, time.Now())
+
+	// Usage is optional, a missing sample is not an error
+	u, err := getValue[*Usage](store, dbKey)
+	if err != nil {
+		return nil
+	}
+	return u
+}
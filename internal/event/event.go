@@ -2,74 +2,169 @@ package event
 
 import (
 	"log/slog"
-	"slices"
+	"strings"
 	"sync"
+
+	"polar-bear/internal/metrics"
 )
 
+// Distribution passes the store keys of changed resources to subscribers.
 type Distribution interface {
-	Send(info string)
-	Register(ch chan string)
-	Unregister(ch chan string)
+	Send(key string)
+	Subscribe(topics ...string) *Subscription
+	Unsubscribe(sub *Subscription)
 }
 
+// Distributer delivers keys to the subscriptions whose topics match them.
+// Sending never blocks: each subscription buffers a bounded number of
+// distinct keys and drops everything beyond that until it is drained.
 type Distributer struct {
-	logger    *slog.Logger
-	mu        sync.Mutex
-	destChans []chan string
+	logger     *slog.Logger
+	bufferSize int
+
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
 }
 
-func NewDistributer(logger *slog.Logger) (Distribution, error) {
+func NewDistributer(logger *slog.Logger, bufferSize int) (Distribution, error) {
 	ed := &Distributer{
-		logger:    logger,
-		mu:        sync.Mutex{},
-		destChans: make([]chan string, 0),
+		logger:     logger,
+		bufferSize: max(bufferSize, 1),
+		subs:       make(map[*Subscription]struct{}),
 	}
 	return ed, nil
 }
 
-func (ed *Distributer) Send(payload string) {
+func (ed *Distributer) Send(key string) {
+	ed.mu.RLock()
+	defer ed.mu.RUnlock()
+
+	delivered := 0
+	for sub := range ed.subs {
+		if sub.matches(key) {
+			sub.push(key)
+			delivered++
+		}
+	}
+
 	ed.logger.Debug(
-		"sending events to channels",
-		"ch_count", len(ed.destChans),
+		"sent key to subscriptions",
+		"key", key,
+		"sub_count", len(ed.subs),
+		"delivered", delivered,
 	)
+}
 
-	ed.mu.Lock()
-	defer ed.mu.Unlock()
-
-	for _, destCh := range ed.destChans {
-		go func(ch chan string) {
-			ch <- payload
-		}(destCh)
+// Subscribe registers a subscription for all keys starting with one of the
+// topics. Without topics, or with an empty topic, it receives every key.
+func (ed *Distributer) Subscribe(topics ...string) *Subscription {
+	sub := &Subscription{
+		topics:  topics,
+		size:    ed.bufferSize,
+		ready:   make(chan struct{}, 1),
+		pending: make(map[string]struct{}),
 	}
-}
 
-func (ed *Distributer) Register(ch chan string) {
+	ed.mu.Lock()
+	ed.subs[sub] = struct{}{}
+	count := len(ed.subs)
+	ed.mu.Unlock()
+
+	metrics.EventSubscribers.Inc()
 	ed.logger.Info(
-		"registering channel",
-		"ch_count_before", len(ed.destChans),
-		"ch_count_after", len(ed.destChans)+1,
+		"registering subscription",
+		"topics", topics,
+		"sub_count", count,
 	)
 
-	ed.mu.Lock()
-	defer ed.mu.Unlock()
-
-	ed.destChans = append(ed.destChans, ch)
+	return sub
 }
 
-func (ed *Distributer) Unregister(ch chan string) {
+func (ed *Distributer) Unsubscribe(sub *Subscription) {
+	ed.mu.Lock()
+	delete(ed.subs, sub)
+	count := len(ed.subs)
+	ed.mu.Unlock()
+
+	metrics.EventSubscribers.Dec()
 	ed.logger.Info(
-		"unregistering channel",
-		"ch_count_before", len(ed.destChans),
-		"ch_count_after", len(ed.destChans)-1,
+		"unregistering subscription",
+		"topics", sub.topics,
+		"dropped", sub.Dropped(),
+		"sub_count", count,
 	)
+}
 
-	ed.mu.Lock()
-	defer ed.mu.Unlock()
+// Subscription buffers the keys sent for its topics until they are drained.
+// A key sent again while it is still pending is only delivered once.
+type Subscription struct {
+	topics []string
+	size   int
+	ready  chan struct{}
+
+	mu      sync.Mutex
+	keys    []string
+	pending map[string]struct{}
+	lagged  bool
+	dropped uint64
+}
 
-	for i, destCh := range ed.destChans {
-		if ch == destCh {
-			ed.destChans = slices.Delete(ed.destChans, i, i+1)
-			break
+func (sub *Subscription) matches(key string) bool {
+	if len(sub.topics) == 0 {
+		return true
+	}
+	for _, topic := range sub.topics {
+		if strings.HasPrefix(key, topic) {
+			return true
 		}
 	}
+	return false
+}
+
+func (sub *Subscription) push(key string) {
+	sub.mu.Lock()
+	switch _, ok := sub.pending[key]; {
+	case ok:
+		metrics.EventsCoalesced.Inc()
+	case len(sub.keys) >= sub.size:
+		sub.lagged = true
+		sub.dropped++
+		metrics.EventsDropped.Inc()
+	default:
+		sub.pending[key] = struct{}{}
+		sub.keys = append(sub.keys, key)
+	}
+	sub.mu.Unlock()
+
+	// Wake up the receiver unless it has been woken up already
+	select {
+	case sub.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready returns a channel that receives a value when keys are pending.
+func (sub *Subscription) Ready() <-chan struct{} {
+	return sub.ready
+}
+
+// Drain returns the pending keys in the order they were first sent. Lagged
+// reports whether keys were dropped since the last call because the buffer
+// was full, the receiver then has to assume that anything changed.
+func (sub *Subscription) Drain() (keys []string, lagged bool) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	keys, lagged = sub.keys, sub.lagged
+	sub.keys = nil
+	sub.pending = make(map[string]struct{}, len(keys))
+	sub.lagged = false
+	return keys, lagged
+}
+
+// Dropped returns the number of keys dropped over the subscription's lifetime.
+func (sub *Subscription) Dropped() uint64 {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return sub.dropped
 }
@@ -0,0 +1,208 @@
+package event
+
+import (
+	"log/slog"
+	"slices"
+	"testing"
+
+	"polar-bear/internal/core"
+)
+
+// Keys as the informers write them
+var (
+	podX  = resourceKey("pod", "a", "x")
+	podY  = resourceKey("pod", "a", "y")
+	podZ  = resourceKey("pod", "a", "z")
+	podB  = resourceKey("pod", "b", "y")
+	podAB = resourceKey("pod", "ab", "z")
+	nodeN = resourceKey("node", "", "n")
+	nodeM = resourceKey("node", "", "m")
+)
+
+func resourceKey(kind string, ns string, name string) string {
+	dbKey, err := core.ResourceKey(kind, ns, name)
+	if err != nil {
+		panic(err)
+	}
+	return string(dbKey)
+}
+
+func newTestDistributer(t *testing.T, bufferSize int) Distribution {
+	t.Helper()
+
+	ed, err := NewDistributer(slog.Default(), bufferSize)
+	if err != nil {
+		t.Fatalf("unable to create distributer: %v", err)
+	}
+	return ed
+}
+
+func TestDistributerDrain(t *testing.T) {
+	tests := []struct {
+		name        string
+		bufferSize  int
+		topics      []string
+		send        []string
+		want        []string
+		wantLagged  bool
+		wantDropped uint64
+	}{
+		{
+			name:       "nothing sent",
+			bufferSize: 10,
+		},
+		{
+			name:       "keys in the order they were first sent",
+			bufferSize: 10,
+			send:       []string{podX, podY, nodeN},
+			want:       []string{podX, podY, nodeN},
+		},
+		{
+			name:       "pending keys are coalesced",
+			bufferSize: 10,
+			send:       []string{podX, podY, podX, podX},
+			want:       []string{podX, podY},
+		},
+		{
+			name:       "coalesced keys don't fill the buffer",
+			bufferSize: 2,
+			send:       []string{podX, podY, podX, podY},
+			want:       []string{podX, podY},
+		},
+		{
+			name:        "keys beyond the buffer are dropped",
+			bufferSize:  2,
+			send:        []string{podX, podY, podZ, nodeN},
+			want:        []string{podX, podY},
+			wantLagged:  true,
+			wantDropped: 2,
+		},
+		{
+			name:       "buffer of at least one",
+			bufferSize: 0,
+			send:       []string{podX, podX},
+			want:       []string{podX},
+		},
+		{
+			name:       "only keys of the topics",
+			bufferSize: 10,
+			topics:     []string{"ns/a/", "node/"},
+			send:       []string{podX, podB, nodeN, podAB},
+			want:       []string{podX, nodeN},
+		},
+		{
+			name:       "empty topic matches everything",
+			bufferSize: 10,
+			topics:     []string{""},
+			send:       []string{podX, nodeN},
+			want:       []string{podX, nodeN},
+		},
+		{
+			name:        "keys of other topics don't fill the buffer",
+			bufferSize:  1,
+			topics:      []string{"node/"},
+			send:        []string{podX, podY, nodeN, nodeM},
+			want:        []string{nodeN},
+			wantLagged:  true,
+			wantDropped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ed := newTestDistributer(t, tt.bufferSize)
+			sub := ed.Subscribe(tt.topics...)
+			defer ed.Unsubscribe(sub)
+
+			for _, key := range tt.send {
+				ed.Send(key)
+			}
+
+			select {
+			case <-sub.Ready():
+				if len(tt.want) == 0 {
+					t.Error("ready without pending keys")
+				}
+			default:
+				if len(tt.want) > 0 {
+					t.Error("not ready with pending keys")
+				}
+			}
+
+			keys, lagged := sub.Drain()
+			if !slices.Equal(keys, tt.want) {
+				t.Errorf("drained %v, want %v", keys, tt.want)
+			}
+			if lagged != tt.wantLagged {
+				t.Errorf("lagged %v, want %v", lagged, tt.wantLagged)
+			}
+			if dropped := sub.Dropped(); dropped != tt.wantDropped {
+				t.Errorf("dropped %d, want %d", dropped, tt.wantDropped)
+			}
+
+			// Draining resets the buffer but not the dropped count
+			keys, lagged = sub.Drain()
+			if len(keys) != 0 || lagged {
+				t.Errorf("drained %v lagged %v again, want nothing", keys, lagged)
+			}
+			if dropped := sub.Dropped(); dropped != tt.wantDropped {
+				t.Errorf("dropped %d after draining again, want %d", dropped, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestDistributerCoalescesUntilDrained(t *testing.T) {
+	ed := newTestDistributer(t, 10)
+	sub := ed.Subscribe()
+	defer ed.Unsubscribe(sub)
+
+	ed.Send(podX)
+	ed.Send(podX)
+	if keys, _ := sub.Drain(); !slices.Equal(keys, []string{podX}) {
+		t.Fatalf("drained %v, want the key once", keys)
+	}
+
+	// A key sent again after draining is delivered again
+	ed.Send(podX)
+	if keys, _ := sub.Drain(); !slices.Equal(keys, []string{podX}) {
+		t.Errorf("drained %v after sending again, want the key", keys)
+	}
+}
+
+func TestDistributerSubscriptions(t *testing.T) {
+	ed := newTestDistributer(t, 10)
+	a := ed.Subscribe("ns/a/")
+	b := ed.Subscribe("ns/b/")
+	all := ed.Subscribe()
+
+	ed.Send(podX)
+	ed.Send(podB)
+
+	for _, tt := range []struct {
+		name string
+		sub  *Subscription
+		want []string
+	}{
+		{"a", a, []string{podX}},
+		{"b", b, []string{podB}},
+		{"all", all, []string{podX, podB}},
+	} {
+		if keys, _ := tt.sub.Drain(); !slices.Equal(keys, tt.want) {
+			t.Errorf("subscription %s drained %v, want %v", tt.name, keys, tt.want)
+		}
+	}
+
+	// Unsubscribed subscriptions receive nothing
+	ed.Unsubscribe(a)
+	ed.Send(podX)
+	if keys, _ := a.Drain(); len(keys) != 0 {
+		t.Errorf("unsubscribed subscription drained %v", keys)
+	}
+	if keys, _ := all.Drain(); !slices.Equal(keys, []string{podX}) {
+		t.Errorf("remaining subscription drained %v, want the key", keys)
+	}
+
+	ed.Unsubscribe(b)
+	ed.Unsubscribe(all)
+}
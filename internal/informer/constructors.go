@@ -1,6 +1,15 @@
 package informer
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
@@ -8,7 +17,6 @@ import (
 	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
-	eventsv1 "k8s.io/api/events/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	nodev1 "k8s.io/api/node/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -18,6 +26,7 @@ import (
 	"k8s.io/client-go/informers"
 	k8scache "k8s.io/client-go/tools/cache"
 
+	"polar-bear/internal/core"
 	"polar-bear/internal/event"
 	"polar-bear/internal/store"
 )
@@ -135,24 +144,6 @@ func NewClusterRoleBindingInformer(
 	)
 }
 
-// This doesn't work, the CRD informer must be handled separately from the main SharedInformerFactory.
-// https://chatgpt.com/share/6868f95a-946c-8000-a5cb-61f643958f0d
-
-// func NewCustomResourceDefinitionInformer(
-// 	factory informers.SharedInformerFactory,
-// 	store store.Store,
-// 	ed event.Distribution,
-// ) *ResourceInformer[*apiextensionsv1.CustomResourceDefinition] {
-// 	return NewTypedInformer(
-// 		factory.Apiextensions().V1().CustomResourceDefinitions().Informer(),
-// 		store,
-// 		ed,
-// 		"crd",
-// 		func(_ *apiextensionsv1.CustomResourceDefinition) string { return "" },
-// 		func(crd *apiextensionsv1.CustomResourceDefinition) string { return crd.Name },
-// 	)
-// }
-
 func NewMutatingWebhookConfigurationInformer(
 	factory informers.SharedInformerFactory,
 	store store.Store,
@@ -268,12 +259,7 @@ func NewCSIStorageCapacityInformer(
 		store,
 		ed,
 		"csistoragecapacity",
-		func(csc *storagev1.CSIStorageCapacity) string {
-			if csc.NodeTopology != nil {
-				return csc.NodeTopology.String()
-			}
-			return ""
-		},
+		func(csc *storagev1.CSIStorageCapacity) string { return csc.Namespace },
 		func(csc *storagev1.CSIStorageCapacity) string { return csc.Name },
 	)
 }
@@ -294,7 +280,15 @@ func NewPodInformer(
 		"pod",
 		func(p *corev1.Pod) string { return p.Namespace },
 		func(p *corev1.Pod) string { return p.Name },
-	)
+	).WithIndex(podNodeIndex)
+}
+
+// podNodeIndex indexes pods by the node they are bound to.
+func podNodeIndex(p *corev1.Pod) []store.IndexEntry {
+	if p.Spec.NodeName == "" {
+		return nil
+	}
+	return []store.IndexEntry{{Index: store.IndexNode, Value: p.Spec.NodeName}}
 }
 
 func NewDeploymentInformer(
@@ -489,7 +483,39 @@ func NewSecretInformer(
 		"secret",
 		func(obj *corev1.Secret) string { return obj.Namespace },
 		func(obj *corev1.Secret) string { return obj.Name },
-	)
+	).WithTransform(stripSecret)
+}
+
+// secretHashKey keys the hashes of secret values. It is random per process, so
+// the served hashes can't be used to guess short values offline.
+var secretHashKey = rand.Text()
+
+// stripSecret removes the values of a secret, polar-bear serves it without
+// authentication. The sizes of the values and a keyed hash of all of them are
+// kept as annotations, see core.SecretKeys.
+func stripSecret(s *corev1.Secret) *corev1.Secret {
+	sizes := make(map[string]int, len(s.Data))
+	hash := hmac.New(sha256.New, []byte(secretHashKey))
+	for _, key := range slices.Sorted(maps.Keys(s.Data)) {
+		sizes[key] = len(s.Data[key])
+		fmt.Fprintf(hash, "%s=%d:", key, len(s.Data[key]))
+		hash.Write(s.Data[key])
+		s.Data[key] = nil
+	}
+	s.StringData = nil
+
+	annotations := make(map[string]string, len(s.Annotations)+2)
+	for k, v := range s.Annotations {
+		// kubectl apply keeps the whole manifest, values included
+		if k != corev1.LastAppliedConfigAnnotation {
+			annotations[k] = v
+		}
+	}
+	sizesJSON, _ := json.Marshal(sizes)
+	annotations[core.AnnotationSecretSizes] = string(sizesJSON)
+	annotations[core.AnnotationSecretHash] = hex.EncodeToString(hash.Sum(nil))[:16]
+	s.Annotations = annotations
+	return s
 }
 
 func NewPersistentVolumeClaimInformer(
@@ -501,7 +527,7 @@ func NewPersistentVolumeClaimInformer(
 		factory.Core().V1().PersistentVolumeClaims().Informer(),
 		store,
 		ed,
-		"pvc",
+		"persistentvolumeclaim",
 		func(obj *corev1.PersistentVolumeClaim) string { return obj.Namespace },
 		func(obj *corev1.PersistentVolumeClaim) string { return obj.Name },
 	)
@@ -612,21 +638,6 @@ func NewLimitRangeInformer(
 	)
 }
 
-func NewEventInformer(
-	factory informers.SharedInformerFactory,
-	store store.Store,
-	ed event.Distribution,
-) *ResourceInformer[*eventsv1.Event] {
-	return NewTypedInformer(
-		factory.Events().V1().Events().Informer(),
-		store,
-		ed,
-		"event",
-		func(obj *eventsv1.Event) string { return obj.Namespace },
-		func(obj *eventsv1.Event) string { return obj.Name },
-	)
-}
-
 func NewLeaseInformer(
 	factory informers.SharedInformerFactory,
 	store store.Store,
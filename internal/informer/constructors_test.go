@@ -0,0 +1,151 @@
+package informer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"polar-bear/internal/core"
+)
+
+const secretValue = "hunter2-s3cr3t"
+
+func testSecret(data map[string]string, stringData map[string]string, annotations map[string]string) *corev1.Secret {
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "creds", Annotations: annotations},
+		StringData: stringData,
+	}
+	if data != nil {
+		s.Data = make(map[string][]byte, len(data))
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+	}
+	return s
+}
+
+func TestStripSecret(t *testing.T) {
+	lastApplied := `{"apiVersion":"v1","kind":"Secret","stringData":{"password":"` + secretValue + `"}}`
+
+	tests := []struct {
+		name            string
+		secret          *corev1.Secret
+		wantKeys        []string
+		wantSizes       map[string]int
+		wantAnnotations map[string]string // besides those added by polar-bear
+	}{
+		{
+			name:            "no data",
+			secret:          testSecret(nil, nil, nil),
+			wantSizes:       map[string]int{},
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name:            "data values",
+			secret:          testSecret(map[string]string{"password": secretValue, "user": "admin", "empty": ""}, nil, nil),
+			wantKeys:        []string{"empty", "password", "user"},
+			wantSizes:       map[string]int{"empty": 0, "password": len(secretValue), "user": 5},
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name:            "string data",
+			secret:          testSecret(map[string]string{"user": "admin"}, map[string]string{"password": secretValue}, nil),
+			wantKeys:        []string{"user"},
+			wantSizes:       map[string]int{"user": 5},
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name: "last applied configuration with the value",
+			secret: testSecret(map[string]string{"password": secretValue}, nil, map[string]string{
+				corev1.LastAppliedConfigAnnotation: lastApplied,
+				"team":                             "payments",
+			}),
+			wantKeys:        []string{"password"},
+			wantSizes:       map[string]int{"password": len(secretValue)},
+			wantAnnotations: map[string]string{"team": "payments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stripSecret(tt.secret)
+
+			if s.StringData != nil {
+				t.Errorf("kept string data %v", s.StringData)
+			}
+			for key, value := range s.Data {
+				if value != nil {
+					t.Errorf("kept value of %s", key)
+				}
+			}
+			if keys := slices.Sorted(maps.Keys(s.Data)); !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("got keys %v, want %v", keys, tt.wantKeys)
+			}
+			if annotations := core.SecretAnnotations(s); !maps.Equal(annotations, tt.wantAnnotations) {
+				t.Errorf("got annotations %v, want %v", annotations, tt.wantAnnotations)
+			}
+
+			var sizes map[string]int
+			if err := json.Unmarshal([]byte(s.Annotations[core.AnnotationSecretSizes]), &sizes); err != nil {
+				t.Fatalf("invalid sizes annotation: %v", err)
+			}
+			if !maps.Equal(sizes, tt.wantSizes) {
+				t.Errorf("got sizes %v, want %v", sizes, tt.wantSizes)
+			}
+			if hash := core.SecretHash(s); len(hash) != 16 {
+				t.Errorf("got hash %q, want 16 hex digits", hash)
+			}
+
+			// Nothing that is stored or served contains the value
+			stored, err := json.Marshal(s)
+			if err != nil {
+				t.Fatalf("unable to marshal secret: %v", err)
+			}
+			if strings.Contains(string(stored), secretValue) {
+				t.Errorf("stored secret contains the value: %s", stored)
+			}
+		})
+	}
+}
+
+func TestStripSecretHash(t *testing.T) {
+	hash := func(data map[string]string) string {
+		return core.SecretHash(stripSecret(testSecret(data, nil, nil)))
+	}
+
+	base := hash(map[string]string{"password": secretValue, "user": "admin"})
+	if again := hash(map[string]string{"user": "admin", "password": secretValue}); again != base {
+		t.Errorf("got hash %s for the same values, want %s", again, base)
+	}
+
+	for _, tt := range []struct {
+		name string
+		data map[string]string
+	}{
+		{"changed value", map[string]string{"password": secretValue + "!", "user": "admin"}},
+		{"renamed key", map[string]string{"pass": secretValue, "user": "admin"}},
+		{"removed key", map[string]string{"password": secretValue}},
+		{"value moved between keys", map[string]string{"password": "admin", "user": secretValue}},
+		{"no values", nil},
+	} {
+		if got := hash(tt.data); got == base {
+			t.Errorf("%s: got the same hash %s", tt.name, got)
+		}
+	}
+
+	// The hash is keyed, it can't be recomputed from guessed values
+	unkeyed := sha256.New()
+	fmt.Fprintf(unkeyed, "%s=%d:%s", "password", len(secretValue), secretValue)
+	fmt.Fprintf(unkeyed, "%s=%d:%s", "user", 5, "admin")
+	if hex.EncodeToString(unkeyed.Sum(nil))[:16] == base {
+		t.Error("got the unkeyed sha256 of the values")
+	}
+}
@@ -0,0 +1,80 @@
+package informer
+
+import (
+	"context"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/event"
+	"polar-bear/internal/store"
+)
+
+// The CRD informer can't come from the main SharedInformerFactory, it needs its
+// own factory built on the apiextensions clientset.
+
+func NewCustomResourceDefinitionInformer(
+	factory apiextensionsinformers.SharedInformerFactory,
+	store store.Store,
+	ed event.Distribution,
+) *ResourceInformer[*apiextensionsv1.CustomResourceDefinition] {
+	return NewTypedInformer(
+		factory.Apiextensions().V1().CustomResourceDefinitions().Informer(),
+		store,
+		ed,
+		"customresourcedefinition",
+		func(_ *apiextensionsv1.CustomResourceDefinition) string { return "" },
+		func(crd *apiextensionsv1.CustomResourceDefinition) string { return crd.Name },
+	)
+}
+
+func NewCustomResourceInformer(
+	factory dynamicinformer.DynamicSharedInformerFactory,
+	crt core.CustomResourceType,
+	store store.Store,
+	ed event.Distribution,
+) *ResourceInformer[*unstructured.Unstructured] {
+	informer := NewTypedInformer(
+		factory.ForResource(crt.GroupVersionResource()).Informer(),
+		store,
+		ed,
+		crt.Name,
+		func(obj *unstructured.Unstructured) string { return obj.GetNamespace() },
+		func(obj *unstructured.Unstructured) string { return obj.GetName() },
+	)
+	informer.getKey = func(ns string, name string) ([]byte, error) {
+		return core.CustomResourceKey(crt, ns, name)
+	}
+	return informer
+}
+
+// NewCustomResourceInformers discovers the CRDs installed in the cluster and
+// returns one informer per CRD. CRDs installed later are not picked up.
+func NewCustomResourceInformers(
+	ctx context.Context,
+	client apiextensionsclientset.Interface,
+	factory dynamicinformer.DynamicSharedInformerFactory,
+	store store.Store,
+	ed event.Distribution,
+) ([]Informer, error) {
+	crds, err := client.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	infs := make([]Informer, 0, len(crds.Items))
+	for i := range crds.Items {
+		crt, ok := core.NewCustomResourceType(&crds.Items[i])
+		if !ok {
+			continue
+		}
+		infs = append(infs, NewCustomResourceInformer(factory, crt, store, ed))
+	}
+
+	return infs, nil
+}
@@ -0,0 +1,107 @@
+package informer
+
+import (
+	"time"
+
+	eventsv1 "k8s.io/api/events/v1"
+	"k8s.io/client-go/informers"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/event"
+	"polar-bear/internal/store"
+)
+
+// EventInformer only keeps events younger than its retention in the store, so
+// noisy controllers can't push the actual resources out of it.
+type EventInformer struct {
+	*ResourceInformer[*eventsv1.Event]
+	retention time.Duration
+}
+
+func NewEventInformer(
+	factory informers.SharedInformerFactory,
+	store store.Store,
+	ed event.Distribution,
+	retention time.Duration,
+) *EventInformer {
+	inf := NewTypedInformer(
+		factory.Events().V1().Events().Informer(),
+		store,
+		ed,
+		"event",
+		func(obj *eventsv1.Event) string { return obj.Namespace },
+		func(obj *eventsv1.Event) string { return obj.Name },
+	)
+
+	inf.WithIndex(eventRegardingIndex)
+
+	ei := &EventInformer{
+		ResourceInformer: inf,
+		retention:        retention,
+	}
+	inf.WithFilter(func(ev *eventsv1.Event) bool { return !ei.expired(ev, time.Now()) })
+
+	return ei
+}
+
+// eventRegardingIndex indexes events by the object they are about.
+func eventRegardingIndex(ev *eventsv1.Event) []store.IndexEntry {
+	value := store.RegardingIndexValue(ev.Regarding.Kind, ev.Regarding.Namespace, ev.Regarding.Name)
+	return []store.IndexEntry{{Index: store.IndexRegarding, Value: value}}
+}
+
+func (informer *EventInformer) Run() error {
+	go informer.prune()
+	return informer.ResourceInformer.Run()
+}
+
+func (informer *EventInformer) expired(ev *eventsv1.Event, now time.Time) bool {
+	return now.Sub(core.EventTime(ev)) > informer.retention
+}
+
+// prune periodically removes events from the store that aged out while the
+// informer still holds them, the api-server only garbage collects them later.
+func (informer *EventInformer) prune() {
+	interval := max(informer.retention/10, time.Minute)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-informer.stopper:
+			return
+		case now := <-ticker.C:
+			pruned := 0
+			for _, obj := range informer.inf.GetStore().List() {
+				ev, ok := obj.(*eventsv1.Event)
+				if !ok || !informer.expired(ev, now) {
+					continue
+				}
+
+				dbKey, err := informer.getKey(ev.Namespace, ev.Name)
+				if err != nil {
+					continue
+				}
+				if _, err := informer.store.Get(dbKey); err != nil {
+					continue
+				}
+				if err := informer.store.Delete(dbKey); err != nil {
+					informer.logger.Error(
+						"unable to remove expired event from store",
+						"key", string(dbKey),
+						"error", err,
+					)
+					continue
+				}
+				informer.event.Send(string(dbKey))
+				pruned++
+			}
+
+			informer.logger.Debug(
+				"pruned expired events",
+				"count", pruned,
+				"retention", informer.retention.String(),
+			)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package fakemetrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	apiPath    = "/apis/metrics.k8s.io/v1beta1/"
+	apiVersion = "metrics.k8s.io/v1beta1"
+
+	// Window is the sampling window reported for all metrics.
+	Window = 15 * time.Second
+)
+
+type podMetrics struct {
+	Kind       string             `json:"kind"`
+	APIVersion string             `json:"apiVersion"`
+	Metadata   metav1.ObjectMeta  `json:"metadata"`
+	Timestamp  metav1.Time        `json:"timestamp"`
+	Window     metav1.Duration    `json:"window"`
+	Containers []containerMetrics `json:"containers"`
+}
+
+type containerMetrics struct {
+	Name  string              `json:"name"`
+	Usage corev1.ResourceList `json:"usage"`
+}
+
+type nodeMetrics struct {
+	Kind       string              `json:"kind"`
+	APIVersion string              `json:"apiVersion"`
+	Metadata   metav1.ObjectMeta   `json:"metadata"`
+	Timestamp  metav1.Time         `json:"timestamp"`
+	Window     metav1.Duration     `json:"window"`
+	Usage      corev1.ResourceList `json:"usage"`
+}
+
+type metricsList[T any] struct {
+	Kind       string          `json:"kind"`
+	APIVersion string          `json:"apiVersion"`
+	Metadata   metav1.ListMeta `json:"metadata"`
+	Items      []T             `json:"items"`
+}
+
+// Server is a fake metrics.k8s.io API to test the usage informer without a
+// cluster. It serves the pod and node metrics set on it from an httptest
+// server, timestamped when they are set unless a time is set with SetTime.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	pods        map[string]podMetrics // by namespace/name
+	nodes       map[string]nodeMetrics
+	now         time.Time // zero to use the current time
+	unavailable bool
+}
+
+func NewServer() *Server {
+	s := &Server{
+		pods:  make(map[string]podMetrics),
+		nodes: make(map[string]nodeMetrics),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Client returns a dynamic client of the server. It isn't rate limited, so
+// tests can poll with short intervals.
+func (s *Server) Client() (dynamic.Interface, error) {
+	return dynamic.NewForConfig(&rest.Config{Host: s.URL, QPS: -1})
+}
+
+// SetTime sets the timestamp of the samples set from now on, the zero time
+// uses the current time again.
+func (s *Server) SetTime(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// SetAvailable makes the server answer as if no metrics server was installed
+// while available is false.
+func (s *Server) SetAvailable(available bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unavailable = !available
+}
+
+// SetPodUsage sets the usage of the containers of a pod by name.
+func (s *Server) SetPodUsage(ns string, name string, containers map[string]corev1.ResourceList) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := podMetrics{
+		Kind:       "PodMetrics",
+		APIVersion: apiVersion,
+		Metadata:   metav1.ObjectMeta{Namespace: ns, Name: name},
+		Timestamp:  metav1.NewTime(s.timestamp()),
+		Window:     metav1.Duration{Duration: Window},
+	}
+	for _, cnt := range sortedNames(containers) {
+		m.Containers = append(m.Containers, containerMetrics{Name: cnt, Usage: containers[cnt]})
+	}
+	s.pods[ns+"/"+name] = m
+}
+
+// DeletePod removes the metrics of a pod.
+func (s *Server) DeletePod(ns string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pods, ns+"/"+name)
+}
+
+// SetNodeUsage sets the usage of a node.
+func (s *Server) SetNodeUsage(name string, usage corev1.ResourceList) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nodes[name] = nodeMetrics{
+		Kind:       "NodeMetrics",
+		APIVersion: apiVersion,
+		Metadata:   metav1.ObjectMeta{Name: name},
+		Timestamp:  metav1.NewTime(s.timestamp()),
+		Window:     metav1.Duration{Duration: Window},
+		Usage:      usage,
+	}
+}
+
+// DeleteNode removes the metrics of a node.
+func (s *Server) DeleteNode(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.nodes, name)
+}
+
+func (s *Server) timestamp() time.Time {
+	if s.now.IsZero() {
+		return time.Now().Truncate(time.Second)
+	}
+	return s.now
+}
+
+// serve answers lists of pods in all namespaces and of nodes.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resource, ok := strings.CutPrefix(r.URL.Path, apiPath)
+	if s.unavailable || !ok || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+
+	var list any
+	switch resource {
+	case "pods":
+		list = metricsList[podMetrics]{
+			Kind:       "PodMetricsList",
+			APIVersion: apiVersion,
+			Items:      sortedValues(s.pods),
+		}
+	case "nodes":
+		list = metricsList[nodeMetrics]{
+			Kind:       "NodeMetricsList",
+			APIVersion: apiVersion,
+			Items:      sortedValues(s.nodes),
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func sortedValues[T any](m map[string]T) []T {
+	values := make([]T, 0, len(m))
+	for _, name := range sortedNames(m) {
+		values = append(values, m[name])
+	}
+	return values
+}
@@ -3,8 +3,12 @@ package informer
 import (
 	"os"
 
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -12,10 +16,29 @@ type Informer interface {
 	Run() (err error)
 	Close() (err error)
 	Kind() string
+	HasSynced() bool
 }
 
-func NewInformerFactory(namespace string) (informers.SharedInformerFactory, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
+// Cluster selects the kubeconfig file and context to connect with. Empty
+// values fall back to KUBECONFIG and its current context.
+type Cluster struct {
+	Kubeconfig string
+	Context    string
+}
+
+func restConfig(cluster Cluster) (*rest.Config, error) {
+	var config *rest.Config
+	var err error
+
+	if cluster == (Cluster{}) && os.Getenv("KUBECONFIG") == "" {
+		// Without any kubeconfig polar-bear runs in the cluster it shows
+		config, err = rest.InClusterConfig()
+	} else {
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.ExplicitPath = cluster.Kubeconfig
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: cluster.Context}
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -23,6 +46,15 @@ func NewInformerFactory(namespace string) (informers.SharedInformerFactory, erro
 	config.QPS = 20
 	config.Burst = 100
 
+	return config, nil
+}
+
+func NewInformerFactory(cluster Cluster, namespace string) (informers.SharedInformerFactory, error) {
+	config, err := restConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+
 	client, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, err
@@ -36,3 +68,42 @@ func NewInformerFactory(namespace string) (informers.SharedInformerFactory, erro
 
 	return factory, nil
 }
+
+func NewDynamicInformerFactory(cluster Cluster, namespace string) (dynamicinformer.DynamicSharedInformerFactory, error) {
+	config, err := restConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		client,
+		0,
+		namespace, // can be empty string, watches everything it can watch
+		nil,
+	)
+
+	return factory, nil
+}
+
+func NewExtensionsClient(cluster Cluster) (apiextensionsclientset.Interface, error) {
+	config, err := restConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiextensionsclientset.NewForConfig(config)
+}
+
+func NewDynamicClient(cluster Cluster) (dynamic.Interface, error) {
+	config, err := restConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamic.NewForConfig(config)
+}
@@ -2,8 +2,11 @@ package informer
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"sync/atomic"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/tools/cache"
 
 	"polar-bear/internal/core"
@@ -11,6 +14,9 @@ import (
 	"polar-bear/internal/store"
 )
 
+// keyFunc builds the store key of a resource from its namespace and name.
+type keyFunc func(ns string, name string) ([]byte, error)
+
 type ResourceInformer[T any] struct {
 	logger       *slog.Logger
 	stopper      chan struct{}
@@ -20,8 +26,15 @@ type ResourceInformer[T any] struct {
 	resourceType string             // string representation of the resource (e.g., "namespace", "node")
 	getNamespace func(obj T) string // function that extracts the namespace from the resource
 	getName      func(obj T) string // function that extracts the name from the resource
+	getKey       keyFunc            // function that builds the store key from namespace and name
+	filter       func(obj T) bool   // optional function that decides whether to store the resource
+	index        indexFunc[T]       // optional function that adds kind specific index entries
+	registration atomic.Pointer[cache.ResourceEventHandlerRegistration]
 }
 
+// indexFunc returns index entries of a resource in addition to owners and labels.
+type indexFunc[T any] func(obj T) []store.IndexEntry
+
 func NewResourceInformer[T any](
 	stopper chan struct{},
 	inf cache.SharedIndexInformer,
@@ -40,16 +53,99 @@ func NewResourceInformer[T any](
 		resourceType: resourceType,
 		getNamespace: getNamespace,
 		getName:      getName,
+		getKey: func(ns string, name string) ([]byte, error) {
+			return core.ResourceKey(resourceType, ns, name)
+		},
+	}
+}
+
+// WithFilter makes the informer skip resources for which filter returns false.
+func (informer *ResourceInformer[T]) WithFilter(filter func(obj T) bool) *ResourceInformer[T] {
+	informer.filter = filter
+	return informer
+}
+
+// WithIndex makes the informer add the entries returned by index to the store indexes.
+func (informer *ResourceInformer[T]) WithIndex(index indexFunc[T]) *ResourceInformer[T] {
+	informer.index = index
+	return informer
+}
+
+// WithTransform makes the informer replace resources with the result of
+// transform before they are cached by client-go or written to the store. The
+// transform may modify the resource in place.
+func (informer *ResourceInformer[T]) WithTransform(transform func(obj T) T) *ResourceInformer[T] {
+	err := informer.inf.SetTransform(func(obj any) (any, error) {
+		// Tombstones of deletes are passed through, their object is already transformed
+		if resource, ok := obj.(T); ok {
+			return transform(resource), nil
+		}
+		return obj, nil
+	})
+	if err != nil {
+		informer.logger.Error(
+			"unable to set transform",
+			"kind", informer.resourceType,
+			"error", err,
+		)
+	}
+	return informer
+}
+
+// indexEntries returns the owner and label index entries of a resource and
+// those of the optional kind specific index function.
+func (informer *ResourceInformer[T]) indexEntries(resource T) []store.IndexEntry {
+	entries := make([]store.IndexEntry, 0)
+
+	if obj, err := meta.Accessor(resource); err == nil {
+		for _, ref := range obj.GetOwnerReferences() {
+			entries = append(entries, store.IndexEntry{Index: store.IndexOwner, Value: string(ref.UID)})
+		}
+		for k, v := range obj.GetLabels() {
+			entries = append(entries, store.IndexEntry{Index: store.IndexLabel, Value: store.LabelIndexValue(k, v)})
+		}
+	}
+
+	if informer.index != nil {
+		entries = append(entries, informer.index(resource)...)
+	}
+	return entries
+}
+
+// write stores a resource, typed stores keep the object itself instead of its JSON.
+func (informer *ResourceInformer[T]) write(dbKey []byte, resource T) error {
+	if os, ok := informer.store.(store.ObjectStore); ok {
+		return os.SetObject(dbKey, resource)
+	}
+
+	dbVal, err := json.Marshal(resource)
+	if err != nil {
+		return fmt.Errorf("unable to marshal to json: %v", err)
+	}
+	return informer.store.Set(dbKey, dbVal)
+}
+
+func (informer *ResourceInformer[T]) setIndex(dbKey []byte, resource T) {
+	err := informer.store.SetIndex(dbKey, informer.indexEntries(resource))
+	if err != nil {
+		informer.logger.Error(
+			"unable to index resource",
+			"kind", informer.resourceType,
+			"key", string(dbKey),
+			"error", err,
+		)
 	}
 }
 
 func (informer *ResourceInformer[T]) Run() error {
-	_, err := informer.inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	reg, err := informer.inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj any) {
 			resource := obj.(T)
+			if informer.filter != nil && !informer.filter(resource) {
+				return
+			}
 
-			dbKey, err := core.ResourceKey(
-				informer.resourceType,
+			dbKey, err := informer.getKey(
 				informer.getNamespace(resource),
 				informer.getName(resource),
 			)
@@ -69,18 +165,7 @@ func (informer *ResourceInformer[T]) Run() error {
 				"key", string(dbKey),
 			)
 
-			dbVal, err := json.Marshal(resource)
-			if err != nil {
-				informer.logger.Error(
-					"unable to marshal to json",
-					"kind", informer.resourceType,
-					"key", string(dbKey),
-					"error", err,
-				)
-				return
-			}
-
-			err = informer.store.Set(dbKey, dbVal)
+			err = informer.write(dbKey, resource)
 			if err != nil {
 				informer.logger.Error(
 					"unable to add to store",
@@ -88,14 +173,18 @@ func (informer *ResourceInformer[T]) Run() error {
 					"key", string(dbKey),
 					"error", err,
 				)
+				return
 			}
+			informer.setIndex(dbKey, resource)
 			informer.event.Send(string(dbKey))
 		},
 		UpdateFunc: func(_, newObj any) {
 			resource := newObj.(T)
+			if informer.filter != nil && !informer.filter(resource) {
+				return
+			}
 
-			dbKey, err := core.ResourceKey(
-				informer.resourceType,
+			dbKey, err := informer.getKey(
 				informer.getNamespace(resource),
 				informer.getName(resource),
 			)
@@ -115,33 +204,34 @@ func (informer *ResourceInformer[T]) Run() error {
 				"key", string(dbKey),
 			)
 
-			dbVal, err := json.Marshal(resource)
+			err = informer.write(dbKey, resource)
 			if err != nil {
 				informer.logger.Error(
-					"unable to marshal to json",
+					"unable to update in store",
 					"kind", informer.resourceType,
 					"key", string(dbKey),
 					"error", err,
 				)
 				return
 			}
-
-			err = informer.store.Set(dbKey, dbVal)
-			if err != nil {
+			informer.setIndex(dbKey, resource)
+			informer.event.Send(string(dbKey))
+		},
+		DeleteFunc: func(obj any) {
+			// Deletes missed while disconnected arrive as tombstones
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			resource, ok := obj.(T)
+			if !ok {
 				informer.logger.Error(
-					"unable to update in store",
+					"unexpected object type on delete",
 					"kind", informer.resourceType,
-					"key", string(dbKey),
-					"error", err,
 				)
+				return
 			}
-			informer.event.Send(string(dbKey))
-		},
-		DeleteFunc: func(obj any) {
-			resource := obj.(T)
 
-			dbKey, err := core.ResourceKey(
-				informer.resourceType,
+			dbKey, err := informer.getKey(
 				informer.getNamespace(resource),
 				informer.getName(resource),
 			)
@@ -176,6 +266,7 @@ func (informer *ResourceInformer[T]) Run() error {
 	if err != nil {
 		return err
 	}
+	informer.registration.Store(&reg)
 	informer.inf.Run(informer.stopper)
 	return nil
 }
@@ -186,6 +277,12 @@ func (informer *ResourceInformer[T]) Close() error {
 	return nil
 }
 
+// HasSynced reports whether the initial list of resources was written to the store.
+func (informer *ResourceInformer[T]) HasSynced() bool {
+	reg := informer.registration.Load()
+	return reg != nil && (*reg).HasSynced()
+}
+
 func (informer *ResourceInformer[T]) Kind() string {
 	return informer.resourceType
 }
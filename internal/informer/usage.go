@@ -0,0 +1,293 @@
+package informer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/event"
+	"polar-bear/internal/store"
+)
+
+var (
+	podMetricsResource  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	nodeMetricsResource = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+)
+
+// metricsObject has the fields of PodMetrics and NodeMetrics, the
+// metrics.k8s.io types are decoded from unstructured objects into it.
+type metricsObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Timestamp         metav1.Time         `json:"timestamp"`
+	Window            metav1.Duration     `json:"window"`
+	Usage             corev1.ResourceList `json:"usage"` // nodes only
+	Containers        []struct {
+		Name  string              `json:"name"`
+		Usage corev1.ResourceList `json:"usage"`
+	} `json:"containers"` // pods only
+}
+
+// UsageInformer polls the metrics.k8s.io API for the usage of pods and nodes,
+// which can't be watched. It keeps the samples of the last core.UsageHistory
+// in memory and writes them with the latest usage to the store.
+type UsageInformer struct {
+	logger    *slog.Logger
+	stopper   chan struct{}
+	client    dynamic.Interface
+	store     store.Store
+	event     event.Distribution
+	interval  time.Duration
+	histories map[string]*usageRing // by store key, only used by the polling goroutine
+	synced    atomic.Bool
+	failing   bool // whether the last poll failed, to only log the first failure
+}
+
+func NewUsageInformer(
+	client dynamic.Interface,
+	store store.Store,
+	ed event.Distribution,
+	interval time.Duration,
+) *UsageInformer {
+	return &UsageInformer{
+		logger:    slog.With("component", "informer"),
+		stopper:   make(chan struct{}),
+		client:    client,
+		store:     store,
+		event:     ed,
+		interval:  interval,
+		histories: make(map[string]*usageRing),
+	}
+}
+
+func (informer *UsageInformer) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-informer.stopper
+		cancel()
+	}()
+
+	ticker := time.NewTicker(informer.interval)
+	defer ticker.Stop()
+
+	for {
+		informer.poll(ctx)
+		informer.synced.Store(true)
+
+		select {
+		case <-informer.stopper:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (informer *UsageInformer) Close() error {
+	informer.logger.Info("closing usage informer")
+	close(informer.stopper)
+	return nil
+}
+
+// HasSynced reports whether the API was polled once, even if that failed
+// because the cluster has no metrics server.
+func (informer *UsageInformer) HasSynced() bool {
+	return informer.synced.Load()
+}
+
+func (informer *UsageInformer) Kind() string {
+	return "usage"
+}
+
+// poll writes the usage of all pods and nodes and removes the usage of those
+// that are gone. Usage is kept as it is while the API is unavailable.
+func (informer *UsageInformer) poll(ctx context.Context) {
+	seen := make(map[string]bool)
+	var errs []error
+
+	pods, err := informer.list(ctx, podMetricsResource)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, m := range pods {
+		usage := corev1.ResourceList{}
+		for _, cnt := range m.Containers {
+			for name, q := range cnt.Usage {
+				sum := usage[name]
+				sum.Add(q)
+				usage[name] = sum
+			}
+		}
+		informer.update(seen, "podusage", m, usage)
+	}
+
+	nodes, err := informer.list(ctx, nodeMetricsResource)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, m := range nodes {
+		informer.update(seen, "nodeusage", m, m.Usage)
+	}
+
+	if len(errs) > 0 {
+		if !informer.failing {
+			informer.logger.Warn(
+				"unable to poll metrics api, is a metrics server installed?",
+				"kind", informer.Kind(),
+				"errors", errs,
+			)
+		}
+		informer.failing = true
+		return
+	}
+	if informer.failing {
+		informer.logger.Info("polling metrics api again", "kind", informer.Kind())
+		informer.failing = false
+	}
+
+	for key := range informer.histories {
+		if seen[key] {
+			continue
+		}
+		delete(informer.histories, key)
+		if err := informer.store.Delete([]byte(key)); err != nil {
+			informer.logger.Error(
+				"unable to remove usage from store",
+				"key", key,
+				"error", err,
+			)
+		}
+		informer.event.Send(key)
+	}
+}
+
+func (informer *UsageInformer) list(ctx context.Context, gvr schema.GroupVersionResource) ([]metricsObject, error) {
+	list, err := informer.client.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list %s: %v", gvr.String(), err)
+	}
+
+	objs := make([]metricsObject, 0, len(list.Items))
+	for _, item := range list.Items {
+		var m metricsObject
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &m); err != nil {
+			informer.logger.Error(
+				"unable to decode metrics",
+				"resource", gvr.String(),
+				"namespace", item.GetNamespace(),
+				"name", item.GetName(),
+				"error", err,
+			)
+			continue
+		}
+		objs = append(objs, m)
+	}
+	return objs, nil
+}
+
+// update adds a sample to the history of a pod or node and writes its usage,
+// unless the metrics server didn't take a new sample since the last poll.
+func (informer *UsageInformer) update(seen map[string]bool, kind string, m metricsObject, usage corev1.ResourceList) {
+	dbKey, err := core.ResourceKey(kind, m.Namespace, m.Name)
+	if err != nil {
+		informer.logger.Error(
+			"unable to get resource key",
+			"kind", kind,
+			"namespace", m.Namespace,
+			"name", m.Name,
+			"error", err,
+		)
+		return
+	}
+	key := string(dbKey)
+	seen[key] = true
+
+	ring, ok := informer.histories[key]
+	if !ok {
+		ring = newUsageRing(core.UsageHistory, informer.interval)
+		informer.histories[key] = ring
+	}
+	sample := core.UsageSample{Time: m.Timestamp.Time, Usage: usage}
+	if !ring.add(sample) {
+		return
+	}
+
+	u := &core.Usage{
+		Timestamp: m.Timestamp.Time,
+		Window:    m.Window.Duration,
+		Usage:     usage,
+		History:   ring.samples(),
+	}
+	if err := informer.write(dbKey, u); err != nil {
+		informer.logger.Error(
+			"unable to add usage to store",
+			"key", key,
+			"error", err,
+		)
+		return
+	}
+	informer.event.Send(key)
+}
+
+// write stores usage, typed stores keep the object itself instead of its JSON.
+func (informer *UsageInformer) write(dbKey []byte, u *core.Usage) error {
+	if os, ok := informer.store.(store.ObjectStore); ok {
+		return os.SetObject(dbKey, u)
+	}
+
+	dbVal, err := json.Marshal(u)
+	if err != nil {
+		return fmt.Errorf("unable to marshal to json: %v", err)
+	}
+	return informer.store.Set(dbKey, dbVal)
+}
+
+// usageRing is a ring buffer of the samples of one pod or node.
+type usageRing struct {
+	history time.Duration
+	buf     []core.UsageSample
+	next    int // index the next sample is written to
+	count   int
+}
+
+// newUsageRing returns a ring that holds enough samples polled every interval
+// to cover history.
+func newUsageRing(history time.Duration, interval time.Duration) *usageRing {
+	size := int(history/interval) + 1
+	return &usageRing{history: history, buf: make([]core.UsageSample, size)}
+}
+
+// add appends a sample, false if it isn't newer than the last one.
+func (r *usageRing) add(sample core.UsageSample) bool {
+	if r.count > 0 && !sample.Time.After(r.buf[(r.next+len(r.buf)-1)%len(r.buf)].Time) {
+		return false
+	}
+	r.buf[r.next] = sample
+	r.next = (r.next + 1) % len(r.buf)
+	r.count = min(r.count+1, len(r.buf))
+	return true
+}
+
+// samples returns the samples up to history before the latest one, oldest
+// first. Old samples are dropped by age as well, polls may have failed in
+// between.
+func (r *usageRing) samples() []core.UsageSample {
+	samples := make([]core.UsageSample, 0, r.count)
+	last := r.buf[(r.next+len(r.buf)-1)%len(r.buf)].Time
+	for i := range r.count {
+		sample := r.buf[(r.next+len(r.buf)-r.count+i)%len(r.buf)]
+		if last.Sub(sample.Time) <= r.history {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
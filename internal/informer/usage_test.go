@@ -0,0 +1,282 @@
+package informer
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/event"
+	"polar-bear/internal/informer/fakemetrics"
+	"polar-bear/internal/store"
+)
+
+var usageStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func usage(cpu string, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+// newTestUsageInformer returns an informer polling a fake metrics server
+// into a typed store, and a subscription to all keys it sends.
+func newTestUsageInformer(t *testing.T) (*UsageInformer, *fakemetrics.Server, store.Store, *event.Subscription) {
+	t.Helper()
+
+	srv := fakemetrics.NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+	db, err := store.NewTypedStore(nil)
+	if err != nil {
+		t.Fatalf("unable to create store: %v", err)
+	}
+	ed, err := event.NewDistributer(slog.Default(), 100)
+	if err != nil {
+		t.Fatalf("unable to create distributer: %v", err)
+	}
+
+	return NewUsageInformer(client, db, ed, time.Minute), srv, db, ed.Subscribe()
+}
+
+func TestUsageInformerPoll(t *testing.T) {
+	inf, srv, db, sub := newTestUsageInformer(t)
+	ctx := context.Background()
+
+	srv.SetTime(usageStart)
+	srv.SetPodUsage("default", "web", map[string]corev1.ResourceList{
+		"app":     usage("100m", "64Mi"),
+		"sidecar": usage("20m", "16Mi"),
+	})
+	srv.SetNodeUsage("node-1", usage("1500m", "2Gi"))
+	inf.poll(ctx)
+
+	keys, _ := sub.Drain()
+	if want := []string{"ns/default/podusage/web", "nodeusage/node-1"}; !slices.Equal(keys, want) {
+		t.Errorf("sent keys %v, want %v", keys, want)
+	}
+
+	pu := core.GetPodUsage(db, "default", "web")
+	if pu == nil {
+		t.Fatal("no pod usage after poll")
+	}
+	if cpu := pu.Usage[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("120m")) != 0 {
+		t.Errorf("pod cpu %s, want the sum of its containers 120m", cpu.String())
+	}
+	if mem := pu.Usage[corev1.ResourceMemory]; mem.Cmp(resource.MustParse("80Mi")) != 0 {
+		t.Errorf("pod memory %s, want the sum of its containers 80Mi", mem.String())
+	}
+	if !pu.Timestamp.Equal(usageStart) || pu.Window != fakemetrics.Window {
+		t.Errorf("pod sample at %s over %s, want %s over %s", pu.Timestamp, pu.Window, usageStart, fakemetrics.Window)
+	}
+
+	nu := core.GetNodeUsage(db, "node-1")
+	if nu == nil {
+		t.Fatal("no node usage after poll")
+	}
+	if cpu := nu.Usage[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("1500m")) != 0 {
+		t.Errorf("node cpu %s, want 1500m", cpu.String())
+	}
+
+	// The metrics server didn't take a new sample, nothing changes
+	inf.poll(ctx)
+	if keys, _ := sub.Drain(); len(keys) != 0 {
+		t.Errorf("sent keys %v without new samples", keys)
+	}
+
+	srv.SetTime(usageStart.Add(time.Minute))
+	srv.SetNodeUsage("node-1", usage("500m", "1Gi"))
+	inf.poll(ctx)
+	if keys, _ := sub.Drain(); !slices.Equal(keys, []string{"nodeusage/node-1"}) {
+		t.Errorf("sent keys %v, want only the node with a new sample", keys)
+	}
+	nu = core.GetNodeUsage(db, "node-1")
+	if len(nu.History) != 2 || !nu.Timestamp.Equal(usageStart.Add(time.Minute)) {
+		t.Errorf("node history has %d samples up to %s, want 2 up to %s", len(nu.History), nu.Timestamp, usageStart.Add(time.Minute))
+	}
+
+	srv.DeletePod("default", "web")
+	inf.poll(ctx)
+	if keys, _ := sub.Drain(); !slices.Equal(keys, []string{"ns/default/podusage/web"}) {
+		t.Errorf("sent keys %v, want the removed pod", keys)
+	}
+	if pu := core.GetPodUsage(db, "default", "web"); pu != nil {
+		t.Errorf("pod usage %v kept after the pod is gone", pu)
+	}
+	if _, ok := inf.histories["ns/default/podusage/web"]; ok {
+		t.Error("pod history kept after the pod is gone")
+	}
+}
+
+func TestUsageInformerUnavailable(t *testing.T) {
+	inf, srv, db, sub := newTestUsageInformer(t)
+	ctx := context.Background()
+
+	srv.SetAvailable(false)
+	inf.poll(ctx)
+	if !inf.failing {
+		t.Error("not failing without a metrics api")
+	}
+	if keys, _ := sub.Drain(); len(keys) != 0 {
+		t.Errorf("sent keys %v without a metrics api", keys)
+	}
+
+	srv.SetAvailable(true)
+	srv.SetTime(usageStart)
+	srv.SetNodeUsage("node-1", usage("1", "1Gi"))
+	inf.poll(ctx)
+	if inf.failing {
+		t.Error("still failing once the metrics api is available")
+	}
+	if core.GetNodeUsage(db, "node-1") == nil {
+		t.Fatal("no node usage once the metrics api is available")
+	}
+	if keys, _ := sub.Drain(); !slices.Equal(keys, []string{"nodeusage/node-1"}) {
+		t.Errorf("sent keys %v, want the node once the metrics api is available", keys)
+	}
+
+	// Usage is kept while the API is unavailable, even of deleted nodes
+	srv.DeleteNode("node-1")
+	srv.SetAvailable(false)
+	inf.poll(ctx)
+	if core.GetNodeUsage(db, "node-1") == nil {
+		t.Error("node usage removed while the metrics api is unavailable")
+	}
+	if keys, _ := sub.Drain(); len(keys) != 0 {
+		t.Errorf("sent keys %v while the metrics api is unavailable", keys)
+	}
+}
+
+func TestUsageInformerRun(t *testing.T) {
+	inf, srv, db, _ := newTestUsageInformer(t)
+	inf.interval = 10 * time.Millisecond
+	srv.SetNodeUsage("node-1", usage("1", "1Gi"))
+
+	done := make(chan error)
+	go func() { done <- inf.Run() }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !inf.HasSynced() || core.GetNodeUsage(db, "node-1") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("no node usage polled in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := inf.Close(); err != nil {
+		t.Fatalf("unable to close informer: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("run returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run didn't return after close")
+	}
+}
+
+func TestUsageRing(t *testing.T) {
+	sample := func(minutes int) core.UsageSample {
+		return core.UsageSample{Time: usageStart.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	tests := []struct {
+		name     string
+		history  time.Duration
+		interval time.Duration
+		add      []int // sample times in minutes
+		want     []int // times of the samples kept, oldest first
+		rejected []int // times of the samples add returns false for
+	}{
+		{
+			name:     "empty",
+			history:  time.Hour,
+			interval: time.Minute,
+		},
+		{
+			name:     "within history",
+			history:  5 * time.Minute,
+			interval: time.Minute,
+			add:      []int{0, 1, 2},
+			want:     []int{0, 1, 2},
+		},
+		{
+			name:     "full",
+			history:  2 * time.Minute,
+			interval: time.Minute,
+			add:      []int{0, 1, 2},
+			want:     []int{0, 1, 2},
+		},
+		{
+			name:     "wraparound evicts the oldest",
+			history:  2 * time.Minute,
+			interval: time.Minute,
+			add:      []int{0, 1, 2, 3, 4},
+			want:     []int{2, 3, 4},
+		},
+		{
+			name:     "wraparound more than once",
+			history:  2 * time.Minute,
+			interval: time.Minute,
+			add:      []int{0, 1, 2, 3, 4, 5, 6, 7},
+			want:     []int{5, 6, 7},
+		},
+		{
+			name:     "samples older than history are dropped after failed polls",
+			history:  5 * time.Minute,
+			interval: time.Minute,
+			add:      []int{0, 1, 2, 6, 7},
+			want:     []int{2, 6, 7},
+		},
+		{
+			name:     "all but the latest sample are too old",
+			history:  5 * time.Minute,
+			interval: time.Minute,
+			add:      []int{0, 1, 30},
+			want:     []int{30},
+		},
+		{
+			name:     "samples not newer than the last are rejected",
+			history:  5 * time.Minute,
+			interval: time.Minute,
+			add:      []int{0, 1, 1, 0, 2},
+			want:     []int{0, 1, 2},
+			rejected: []int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newUsageRing(tt.history, tt.interval)
+
+			var rejected []int
+			for _, minutes := range tt.add {
+				if !r.add(sample(minutes)) {
+					rejected = append(rejected, minutes)
+				}
+			}
+			if !slices.Equal(rejected, tt.rejected) {
+				t.Errorf("rejected %v, want %v", rejected, tt.rejected)
+			}
+
+			var got []int
+			for _, s := range r.samples() {
+				got = append(got, int(s.Time.Sub(usageStart)/time.Minute))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("samples %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
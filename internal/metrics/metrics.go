@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "polar_bear"
+
+// Buckets from 10µs to ~1.3s, rendering and decoding is usually well below a millisecond.
+var durationBuckets = prometheus.ExponentialBuckets(0.00001, 4, 9)
+
+var (
+	// DecodeDuration observes how long reading resources from the store took,
+	// by resource kind and store mode (json or typed).
+	DecodeDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "store_decode_duration_seconds",
+			Help:      "Time spent reading and decoding resources from the store.",
+			Buckets:   durationBuckets,
+		},
+		[]string{"kind", "mode"},
+	)
+
+	// RenderDuration observes how long rendering a view took, by view name.
+	RenderDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "render_duration_seconds",
+			Help:      "Time spent rendering views for pages and websocket updates.",
+			Buckets:   durationBuckets,
+		},
+		[]string{"view"},
+	)
+)
+
+// StoreEvictions counts resources the store dropped because it was full.
+var StoreEvictions = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "store_evictions_total",
+		Help:      "Resources evicted from the store because its capacity was exceeded.",
+	},
+)
+
+var (
+	// EventSubscribers is the number of open subscriptions for changed resources.
+	EventSubscribers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "event_subscribers",
+			Help:      "Subscriptions currently receiving changed resources, one per live view connection.",
+		},
+	)
+
+	// EventsDropped counts changed resources not delivered to a subscription
+	// because its buffer was full.
+	EventsDropped = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_dropped_total",
+			Help:      "Changed resources dropped because the buffer of a subscription was full.",
+		},
+	)
+
+	// EventsCoalesced counts changed resources merged into an update still
+	// pending for a subscription.
+	EventsCoalesced = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_coalesced_total",
+			Help:      "Changed resources merged into an update still pending for a subscription.",
+		},
+	)
+)
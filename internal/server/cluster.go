@@ -0,0 +1,23 @@
+package server
+
+import (
+	"net/http"
+
+	"polar-bear/internal/event"
+	"polar-bear/internal/store"
+	"polar-bear/internal/web/view/shared"
+)
+
+// Cluster is a cluster served by polar-bear, with its own store and updates.
+type Cluster struct {
+	Name   string
+	Store  store.Store
+	Events event.Distribution
+}
+
+// clusterMiddleware makes the pages of a request link to the pages of its cluster.
+func clusterMiddleware(clusters shared.Clusters, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(shared.WithClusters(r.Context(), clusters)))
+	})
+}
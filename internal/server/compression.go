@@ -27,6 +27,19 @@ func (w *compressedResponseWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
 
+// Flush writes the data buffered by the compressor to the client, so
+// streamed responses are not held back.
+func (w *compressedResponseWriter) Flush() {
+	if f, ok := w.Writer.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func getCompressionType(acceptEncoding string) CompressionType {
 	for encoding := range strings.SplitSeq(acceptEncoding, ",") {
 		trimmed := strings.TrimSpace(encoding)
@@ -23,6 +23,10 @@ func (re *responseRecorder) Write(data []byte) (int, error) {
 	return re.ResponseWriter.Write(data)
 }
 
+func (re *responseRecorder) Unwrap() http.ResponseWriter {
+	return re.ResponseWriter
+}
+
 func loggingMiddleware(next http.Handler) http.Handler {
 	logger := slog.With("component", "request-logger")
 
@@ -11,9 +11,40 @@ import (
 	"polar-bear/internal/runtimemeta"
 	"polar-bear/internal/store"
 	"polar-bear/internal/web/handler"
+	"polar-bear/internal/web/view/shared"
 )
 
+// GetRoutes serves the pages of every cluster below /c/{cluster} if there is
+// more than one. The first cluster is also served without prefix.
 func GetRoutes(
+	rm *runtimemeta.RuntimeMeta,
+	cfg *config.Config,
+	metricsMiddleware middleware.Middleware,
+	clusters []Cluster,
+) http.Handler {
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+
+	rootMux := http.NewServeMux()
+	for i, cluster := range clusters {
+		routes := clusterRoutes(rm, cfg, metricsMiddleware, cluster.Store, cluster.Events)
+
+		if i == 0 {
+			rootMux.Handle("/", clusterMiddleware(shared.Clusters{Names: names, Current: cluster.Name}, routes))
+		}
+		if len(clusters) > 1 {
+			prefix := "/c/" + cluster.Name
+			info := shared.Clusters{Names: names, Current: cluster.Name, Prefix: prefix}
+			rootMux.Handle(prefix+"/", http.StripPrefix(prefix, clusterMiddleware(info, routes)))
+		}
+	}
+
+	return rootMux
+}
+
+func clusterRoutes(
 	rm *runtimemeta.RuntimeMeta,
 	cfg *config.Config,
 	metricsMiddleware middleware.Middleware,
@@ -28,6 +59,37 @@ func GetRoutes(
 	mwMux.Handle("GET /no/{no}", handler.Node(cfg, rm, store))
 	mwMux.Handle("GET /no/{no}/", handler.Node(cfg, rm, store))
 
+	mwMux.Handle("GET /pv", handler.PersistentVolumes(cfg, rm, store))
+	mwMux.Handle("GET /pv/", handler.PersistentVolumes(cfg, rm, store))
+	mwMux.Handle("GET /pv/{pv}", handler.PersistentVolume(cfg, rm, store))
+	mwMux.Handle("GET /pv/{pv}/", handler.PersistentVolume(cfg, rm, store))
+
+	mwMux.Handle("GET /sc", handler.StorageClasses(cfg, rm, store))
+	mwMux.Handle("GET /sc/", handler.StorageClasses(cfg, rm, store))
+	mwMux.Handle("GET /sc/{sc}", handler.StorageClass(cfg, rm, store))
+	mwMux.Handle("GET /sc/{sc}/", handler.StorageClass(cfg, rm, store))
+
+	mwMux.Handle("GET /csidriver", handler.CSIDrivers(cfg, rm, store))
+	mwMux.Handle("GET /csidriver/", handler.CSIDrivers(cfg, rm, store))
+	mwMux.Handle("GET /csidriver/{csidriver}", handler.CSIDriver(cfg, rm, store))
+	mwMux.Handle("GET /csidriver/{csidriver}/", handler.CSIDriver(cfg, rm, store))
+
+	mwMux.Handle("GET /clusterrole", handler.ClusterRoles(cfg, rm, store))
+	mwMux.Handle("GET /clusterrole/", handler.ClusterRoles(cfg, rm, store))
+	mwMux.Handle("GET /clusterrole/{clusterrole}", handler.ClusterRole(cfg, rm, store))
+	mwMux.Handle("GET /clusterrole/{clusterrole}/", handler.ClusterRole(cfg, rm, store))
+
+	mwMux.Handle("GET /clusterrolebinding", handler.ClusterRoleBindings(cfg, rm, store))
+	mwMux.Handle("GET /clusterrolebinding/", handler.ClusterRoleBindings(cfg, rm, store))
+	mwMux.Handle("GET /clusterrolebinding/{clusterrolebinding}", handler.ClusterRoleBinding(cfg, rm, store))
+	mwMux.Handle("GET /clusterrolebinding/{clusterrolebinding}/", handler.ClusterRoleBinding(cfg, rm, store))
+
+	mwMux.Handle("GET /rbac", handler.RBAC(cfg, rm, store))
+	mwMux.Handle("GET /rbac/", handler.RBAC(cfg, rm, store))
+
+	mwMux.Handle("GET /search", handler.Search(cfg, rm, store))
+	mwMux.Handle("GET /search/", handler.Search(cfg, rm, store))
+
 	mwMux.Handle("GET /ns/{ns}", handler.Namespace(cfg, rm, store))
 	mwMux.Handle("GET /ns/{ns}/", handler.Namespace(cfg, rm, store))
 
@@ -36,6 +98,37 @@ func GetRoutes(
 	mwMux.Handle("GET /ns/{ns}/{res}/{name}", handler.Resource(cfg, rm, store))
 	mwMux.Handle("GET /ns/{ns}/{res}/{name}/", handler.Resource(cfg, rm, store))
 
+	mwMux.Handle("GET /events", handler.Events(cfg, rm, store))
+	mwMux.Handle("GET /events/", handler.Events(cfg, rm, store))
+
+	mwMux.Handle("GET /crd", handler.CustomResourceDefinitions(cfg, rm, store))
+	mwMux.Handle("GET /crd/", handler.CustomResourceDefinitions(cfg, rm, store))
+	mwMux.Handle("GET /cr/{res}", handler.ClusterCustomResources(cfg, rm, store))
+	mwMux.Handle("GET /cr/{res}/", handler.ClusterCustomResources(cfg, rm, store))
+	mwMux.Handle("GET /cr/{res}/{name}", handler.ClusterCustomResource(cfg, rm, store))
+	mwMux.Handle("GET /cr/{res}/{name}/", handler.ClusterCustomResource(cfg, rm, store))
+
+	mwMux.Handle("GET /api/v1/no", handler.APINodes(store))
+	mwMux.Handle("GET /api/v1/no/{no}", handler.APINode(store))
+	mwMux.Handle("GET /api/v1/pv", handler.APIClusterResources(store, "pv"))
+	mwMux.Handle("GET /api/v1/pv/{name}", handler.APIClusterResource(store, "pv"))
+	mwMux.Handle("GET /api/v1/sc", handler.APIClusterResources(store, "sc"))
+	mwMux.Handle("GET /api/v1/sc/{name}", handler.APIClusterResource(store, "sc"))
+	mwMux.Handle("GET /api/v1/csidriver", handler.APIClusterResources(store, "csidriver"))
+	mwMux.Handle("GET /api/v1/csidriver/{name}", handler.APIClusterResource(store, "csidriver"))
+	mwMux.Handle("GET /api/v1/clusterrole", handler.APIClusterResources(store, "clusterrole"))
+	mwMux.Handle("GET /api/v1/clusterrole/{name}", handler.APIClusterResource(store, "clusterrole"))
+	mwMux.Handle("GET /api/v1/clusterrolebinding", handler.APIClusterResources(store, "clusterrolebinding"))
+	mwMux.Handle("GET /api/v1/clusterrolebinding/{name}", handler.APIClusterResource(store, "clusterrolebinding"))
+	mwMux.Handle("GET /api/v1/ns", handler.APINamespaces(store))
+	mwMux.Handle("GET /api/v1/ns/{ns}", handler.APINamespace(store))
+	mwMux.Handle("GET /api/v1/ns/{ns}/{res}", handler.APIResources(store))
+	mwMux.Handle("GET /api/v1/ns/{ns}/{res}/{name}", handler.APIResource(store))
+	mwMux.Handle("GET /api/v1/events", handler.APIWarningEvents(store))
+	mwMux.Handle("GET /api/v1/crd", handler.APICustomResourceDefinitions(store))
+	mwMux.Handle("GET /api/v1/cr/{res}", handler.APIClusterCustomResources(store))
+	mwMux.Handle("GET /api/v1/cr/{res}/{name}", handler.APIClusterCustomResource(store))
+
 	mwMux.Handle("GET /health", handler.Health(rm))
 	mwMux.Handle("GET /info", handler.Info(cfg, rm, store))
 
@@ -43,11 +136,13 @@ func GetRoutes(
 
 	mwMux.Handle("GET /_open-sidebar", handler.HTMXOpenSidebar(rm, store))
 	mwMux.Handle("GET /_close-sidebar", handler.HTMXCloseSidebar(rm, store))
+	mwMux.Handle("GET /_search", handler.HTMXSearch(store))
 
 	mwMux.Handle("GET /", handler.Cluster(cfg, rm, store))
 
 	// Middlewares to apply
-	mwHnd := loggingMiddleware(mwMux)
+	mwHnd := transportMiddleware(cfg.LiveTransport, mwMux)
+	mwHnd = loggingMiddleware(mwHnd)
 	mwHnd = compressionMiddleware(mwHnd)
 	mwHnd = std.Handler("", metricsMiddleware, mwHnd)
 
@@ -55,7 +150,11 @@ func GetRoutes(
 	rootMux := http.NewServeMux()
 	rootMux.Handle("/", mwHnd)
 
-	rootMux.Handle("GET /ws/{ns}", handler.Websocket(event, store))
+	rootMux.Handle("GET /ws", handler.Websocket(event, store))
+
+	// Event streams are long-lived, they are compressed and logged but not
+	// measured, the metrics middleware can't extend their write deadline
+	rootMux.Handle("GET /sse", compressionMiddleware(loggingMiddleware(handler.EventStream(event, store))))
 
 	return rootMux
 }
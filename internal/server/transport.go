@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+
+	"polar-bear/internal/web/view/shared"
+)
+
+// Name of the query parameter and cookie selecting the live update transport.
+const transportParam = "live"
+
+// transportMiddleware selects how pages receive live updates. A transport
+// chosen with ?live=sse or ?live=ws is remembered in a cookie, otherwise the
+// configured default applies.
+func transportMiddleware(defaultTransport string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport := defaultTransport
+
+		if c, err := r.Cookie(transportParam); err == nil && validTransport(c.Value) {
+			transport = c.Value
+		}
+		if q := r.URL.Query().Get(transportParam); validTransport(q) {
+			transport = q
+			http.SetCookie(w, &http.Cookie{
+				Name:     transportParam,
+				Value:    q,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		next.ServeHTTP(w, r.WithContext(shared.WithTransport(r.Context(), transport)))
+	})
+}
+
+func validTransport(transport string) bool {
+	return transport == shared.TransportWebsocket || transport == shared.TransportSSE
+}
@@ -0,0 +1,287 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	resourcesBucket = []byte("resources")
+	indexesBucket   = []byte("indexes")
+)
+
+// BoltStore keeps resources in an on-disk bbolt database, so the UI has data
+// right after a restart. Keys loaded from disk that are not written again by
+// the informers until Reconcile is called are considered stale and removed.
+type BoltStore struct {
+	logger  *slog.Logger
+	db      *bolt.DB
+	indexes *Indexes
+
+	mu    sync.Mutex
+	stale map[string]struct{} // keys loaded from disk, nil once reconciled
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	loadSuccesses uint64
+	loadFailures  uint64
+	loadTime      time.Duration
+}
+
+func NewBoltStore(path string) (Store, error) {
+	logger := slog.With("component", "bolt-store")
+
+	db, err := openBolt(path)
+	if err != nil {
+		// The database only caches the cluster state, start over instead of failing
+		logger.Warn("unable to open database, recreating it", "path", path, "error", err)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove database: %v", err)
+		}
+		db, err = openBolt(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bs := &BoltStore{
+		logger:  logger,
+		db:      db,
+		indexes: NewIndexes(),
+		stale:   make(map[string]struct{}),
+	}
+
+	if err := bs.load(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to load database: %v", err)
+	}
+
+	logger.Info(
+		"loaded database",
+		"path", path,
+		"keys", bs.loadSuccesses,
+		"failures", bs.loadFailures,
+		"duration", bs.loadTime.String(),
+	)
+
+	return bs, nil
+}
+
+func openBolt(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{
+		Timeout: time.Second,
+		// Losing the last writes on a crash is fine, the informers resync anyway
+		NoSync:         true,
+		NoFreelistSync: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{resourcesBucket, indexesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// load marks all stored keys as stale and restores their index entries.
+func (bs *BoltStore) load() error {
+	start := time.Now()
+	defer func() { bs.loadTime = time.Since(start) }()
+
+	return bs.db.View(func(tx *bolt.Tx) error {
+		err := tx.Bucket(resourcesBucket).ForEach(func(k, _ []byte) error {
+			bs.stale[string(k)] = struct{}{}
+			bs.loadSuccesses++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(indexesBucket).ForEach(func(k, v []byte) error {
+			var entries []IndexEntry
+			if err := json.Unmarshal(v, &entries); err != nil {
+				bs.loadFailures++
+				return nil
+			}
+			bs.indexes.Set(string(k), entries)
+			return nil
+		})
+	})
+}
+
+func (bs *BoltStore) Set(key []byte, value []byte) error {
+	bs.mu.Lock()
+	delete(bs.stale, string(key))
+	bs.mu.Unlock()
+
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(resourcesBucket).Put(key, value)
+	})
+}
+
+func (bs *BoltStore) Get(key []byte) ([]byte, error) {
+	var value []byte
+
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(resourcesBucket).Get(key); v != nil {
+			value = bytes.Clone(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		bs.misses.Add(1)
+		return nil, fmt.Errorf("not found")
+	}
+	bs.hits.Add(1)
+	return value, nil
+}
+
+func (bs *BoltStore) GetAll(prefix []byte) (map[string][]byte, error) {
+	results := make(map[string][]byte)
+
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(resourcesBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			results[string(k)] = bytes.Clone(v)
+		}
+		return nil
+	})
+
+	return results, err
+}
+
+func (bs *BoltStore) Count(prefix []byte) (uint, error) {
+	count := uint(0)
+
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(resourcesBucket).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			count++
+		}
+		return nil
+	})
+
+	return count, err
+}
+
+func (bs *BoltStore) Delete(key []byte) error {
+	bs.mu.Lock()
+	delete(bs.stale, string(key))
+	bs.mu.Unlock()
+
+	bs.indexes.Remove(string(key))
+
+	deleted := false
+	err := bs.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(indexesBucket).Delete(key); err != nil {
+			return err
+		}
+
+		b := tx.Bucket(resourcesBucket)
+		if b.Get(key) == nil {
+			return nil
+		}
+		deleted = true
+		return b.Delete(key)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("not deleted")
+	}
+	return nil
+}
+
+func (bs *BoltStore) SetIndex(key []byte, entries []IndexEntry) error {
+	bs.indexes.Set(string(key), entries)
+
+	value, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(indexesBucket).Put(key, value)
+	})
+}
+
+func (bs *BoltStore) Lookup(index string, value string) ([][]byte, error) {
+	return bs.indexes.Lookup(index, value), nil
+}
+
+// IndexEntries returns the index entries restored from disk and set since.
+func (bs *BoltStore) IndexEntries() map[string][]IndexEntry {
+	return bs.indexes.Entries()
+}
+
+// Reconcile removes the keys loaded from disk that were not written since.
+// It must only be called once all informers have synced.
+func (bs *BoltStore) Reconcile() (uint, error) {
+	bs.mu.Lock()
+	stale := bs.stale
+	bs.stale = nil
+	bs.mu.Unlock()
+
+	for key := range stale {
+		bs.indexes.Remove(key)
+	}
+
+	err := bs.db.Update(func(tx *bolt.Tx) error {
+		for key := range stale {
+			if err := tx.Bucket(resourcesBucket).Delete([]byte(key)); err != nil {
+				return err
+			}
+			if err := tx.Bucket(indexesBucket).Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(len(stale)), nil
+}
+
+func (bs *BoltStore) Close() error {
+	return bs.db.Close()
+}
+
+// Stats reports reads as hits and misses, loads refer to the keys read from
+// disk on start. Nothing is ever evicted.
+func (bs *BoltStore) Stats() Stats {
+	return Stats{
+		Hits:          bs.hits.Load(),
+		Misses:        bs.misses.Load(),
+		LoadSuccesses: bs.loadSuccesses,
+		LoadFailures:  bs.loadFailures,
+		TotalLoadTime: bs.loadTime,
+	}
+}
@@ -0,0 +1,140 @@
+package store_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"polar-bear/internal/store"
+)
+
+func TestBoltStoreReconcile(t *testing.T) {
+	tests := []struct {
+		name      string
+		stored    []string // keys written before the restart
+		written   []string // keys written again after the restart
+		deleted   []string // keys deleted after the restart
+		want      []string // keys left after reconciling
+		wantCount uint     // keys removed by reconciling
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:    "all written again",
+			stored:  []string{podX, nodeN},
+			written: []string{podX, nodeN},
+			want:    []string{nodeN, podX},
+		},
+		{
+			name:      "stale keys are removed",
+			stored:    []string{podX, podY, nodeN},
+			written:   []string{podX},
+			want:      []string{podX},
+			wantCount: 2,
+		},
+		{
+			name:      "new keys are kept",
+			stored:    []string{podX},
+			written:   []string{podZ},
+			want:      []string{podZ},
+			wantCount: 1,
+		},
+		{
+			name:    "deleted keys are not counted",
+			stored:  []string{podX, podY},
+			written: []string{podX},
+			deleted: []string{podY},
+			want:    []string{podX},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.db")
+
+			db := newTestBoltStore(t, path)
+			for _, key := range tt.stored {
+				setWithNode(t, db, key)
+			}
+			if err := db.Close(); err != nil {
+				t.Fatalf("unable to close store: %v", err)
+			}
+
+			db = newTestBoltStore(t, path)
+			defer db.Close()
+			if keys := storedKeys(t, db); !slices.Equal(keys, sortedCopy(tt.stored)) {
+				t.Fatalf("got keys %v after restart, want %v", keys, sortedCopy(tt.stored))
+			}
+			for _, key := range tt.written {
+				setWithNode(t, db, key)
+			}
+			for _, key := range tt.deleted {
+				if err := db.Delete([]byte(key)); err != nil {
+					t.Fatalf("unable to delete %s: %v", key, err)
+				}
+			}
+
+			removed, err := db.(store.Reconciler).Reconcile()
+			if err != nil {
+				t.Fatalf("unable to reconcile: %v", err)
+			}
+			if removed != tt.wantCount {
+				t.Errorf("removed %d keys, want %d", removed, tt.wantCount)
+			}
+			if keys := storedKeys(t, db); !slices.Equal(keys, sortedCopy(tt.want)) {
+				t.Errorf("got keys %v, want %v", keys, sortedCopy(tt.want))
+			}
+			if keys := lookupKeys(t, db, store.IndexNode, "n"); !slices.Equal(keys, sortedCopy(tt.want)) {
+				t.Errorf("got indexed keys %v, want %v", keys, sortedCopy(tt.want))
+			}
+
+			// Reconciling again has nothing left to remove
+			if removed, err := db.(store.Reconciler).Reconcile(); err != nil || removed != 0 {
+				t.Errorf("removed %d keys reconciling again, error %v", removed, err)
+			}
+		})
+	}
+}
+
+func newTestBoltStore(t *testing.T, path string) store.Store {
+	t.Helper()
+
+	db, err := store.NewBoltStore(path)
+	if err != nil {
+		t.Fatalf("unable to open store: %v", err)
+	}
+	return db
+}
+
+func setWithNode(t *testing.T, db store.Store, key string) {
+	t.Helper()
+
+	if err := db.Set([]byte(key), []byte(`{}`)); err != nil {
+		t.Fatalf("unable to set %s: %v", key, err)
+	}
+	if err := db.SetIndex([]byte(key), []store.IndexEntry{{Index: store.IndexNode, Value: "n"}}); err != nil {
+		t.Fatalf("unable to index %s: %v", key, err)
+	}
+}
+
+func storedKeys(t *testing.T, db store.Store) []string {
+	t.Helper()
+
+	keyVals, err := db.GetAll(nil)
+	if err != nil {
+		t.Fatalf("unable to get keys: %v", err)
+	}
+	keys := make([]string, 0, len(keyVals))
+	for key := range keyVals {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func sortedCopy(keys []string) []string {
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	return sorted
+}
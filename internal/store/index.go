@@ -0,0 +1,121 @@
+package store
+
+import (
+	"slices"
+	"sync"
+)
+
+// Names of the secondary indexes maintained by the informers.
+const (
+	IndexOwner     = "owner"     // owner reference UID
+	IndexNode      = "node"      // name of the node a pod is bound to
+	IndexLabel     = "label"     // label in "key=value" form
+	IndexRegarding = "regarding" // object an event is about, see RegardingIndexValue
+)
+
+// IndexEntry is a single value a key is found under in a secondary index.
+type IndexEntry struct {
+	Index string
+	Value string
+}
+
+// LabelIndexValue returns the value labels are indexed under.
+func LabelIndexValue(key string, value string) string {
+	return key + "=" + value
+}
+
+// RegardingIndexValue returns the value events about an object are indexed
+// under, the namespace is empty for cluster-scoped objects.
+func RegardingIndexValue(kind string, ns string, name string) string {
+	return kind + "/" + ns + "/" + name
+}
+
+// Indexes maps index values to store keys. It does not hold the stored values
+// itself, store implementations embed it and keep it in sync with their keys.
+type Indexes struct {
+	mu      sync.RWMutex
+	values  map[string]map[string]map[string]struct{} // index -> value -> keys
+	entries map[string][]IndexEntry                   // key -> entries, for removal
+}
+
+func NewIndexes() *Indexes {
+	return &Indexes{
+		values:  make(map[string]map[string]map[string]struct{}),
+		entries: make(map[string][]IndexEntry),
+	}
+}
+
+// Set replaces all index entries of key.
+func (idx *Indexes) Set(key string, entries []IndexEntry) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(key)
+	if len(entries) == 0 {
+		return
+	}
+
+	for _, entry := range entries {
+		values, ok := idx.values[entry.Index]
+		if !ok {
+			values = make(map[string]map[string]struct{})
+			idx.values[entry.Index] = values
+		}
+		keys, ok := values[entry.Value]
+		if !ok {
+			keys = make(map[string]struct{})
+			values[entry.Value] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	idx.entries[key] = slices.Clone(entries)
+}
+
+// Remove drops all index entries of key.
+func (idx *Indexes) Remove(key string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(key)
+}
+
+func (idx *Indexes) remove(key string) {
+	for _, entry := range idx.entries[key] {
+		keys := idx.values[entry.Index][entry.Value]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(idx.values[entry.Index], entry.Value)
+		}
+	}
+	delete(idx.entries, key)
+}
+
+// Entries returns a copy of the index entries of all keys.
+func (idx *Indexes) Entries() map[string][]IndexEntry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	entries := make(map[string][]IndexEntry, len(idx.entries))
+	for key, e := range idx.entries {
+		entries[key] = slices.Clone(e)
+	}
+	return entries
+}
+
+// Lookup returns the sorted keys found under value in index.
+func (idx *Indexes) Lookup(index string, value string) [][]byte {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	keys := make([]string, 0, len(idx.values[index][value]))
+	for key := range idx.values[index][value] {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	results := make([][]byte, len(keys))
+	for i, key := range keys {
+		results[i] = []byte(key)
+	}
+	return results
+}
@@ -0,0 +1,276 @@
+package store_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/store"
+)
+
+// Keys as the informers write them
+var (
+	podX  = resourceKey("pod", "a", "x")
+	podY  = resourceKey("pod", "a", "y")
+	podZ  = resourceKey("pod", "a", "z")
+	nodeN = resourceKey("node", "", "n")
+)
+
+func resourceKey(kind string, ns string, name string) string {
+	dbKey, err := core.ResourceKey(kind, ns, name)
+	if err != nil {
+		panic(err)
+	}
+	return string(dbKey)
+}
+
+var (
+	onNodeN  = store.IndexEntry{Index: store.IndexNode, Value: "n"}
+	onNodeM  = store.IndexEntry{Index: store.IndexNode, Value: "m"}
+	appWeb   = store.IndexEntry{Index: store.IndexLabel, Value: store.LabelIndexValue("app", "web")}
+	ownedByD = store.IndexEntry{Index: store.IndexOwner, Value: "d"}
+	ownedByN = store.IndexEntry{Index: store.IndexOwner, Value: "n"}
+)
+
+func TestIndexes(t *testing.T) {
+	type step struct {
+		key     string
+		entries []store.IndexEntry // nil with remove
+		remove  bool
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+		want  map[store.IndexEntry][]string
+	}{
+		{
+			name: "nothing indexed",
+			want: map[store.IndexEntry][]string{onNodeN: {}},
+		},
+		{
+			name: "key under all its entries",
+			steps: []step{
+				{key: podX, entries: []store.IndexEntry{onNodeN, appWeb, ownedByD}},
+			},
+			want: map[store.IndexEntry][]string{
+				onNodeN:  {podX},
+				appWeb:   {podX},
+				ownedByD: {podX},
+				onNodeM:  {},
+			},
+		},
+		{
+			name: "keys are sorted",
+			steps: []step{
+				{key: podZ, entries: []store.IndexEntry{onNodeN}},
+				{key: nodeN, entries: []store.IndexEntry{onNodeN}},
+				{key: podX, entries: []store.IndexEntry{onNodeN}},
+			},
+			want: map[store.IndexEntry][]string{onNodeN: {nodeN, podX, podZ}},
+		},
+		{
+			name: "same value in different indexes",
+			steps: []step{
+				{key: podX, entries: []store.IndexEntry{onNodeN}},
+				{key: podY, entries: []store.IndexEntry{ownedByN}},
+			},
+			want: map[store.IndexEntry][]string{
+				onNodeN:  {podX},
+				ownedByN: {podY},
+			},
+		},
+		{
+			name: "re-indexing replaces all entries",
+			steps: []step{
+				{key: podX, entries: []store.IndexEntry{onNodeN, appWeb}},
+				{key: podY, entries: []store.IndexEntry{onNodeN}},
+				{key: podX, entries: []store.IndexEntry{onNodeM}},
+			},
+			want: map[store.IndexEntry][]string{
+				onNodeN: {podY},
+				onNodeM: {podX},
+				appWeb:  {},
+			},
+		},
+		{
+			name: "re-indexing without entries",
+			steps: []step{
+				{key: podX, entries: []store.IndexEntry{onNodeN}},
+				{key: podX},
+			},
+			want: map[store.IndexEntry][]string{onNodeN: {}},
+		},
+		{
+			name: "removing drops all entries",
+			steps: []step{
+				{key: podX, entries: []store.IndexEntry{onNodeN, appWeb}},
+				{key: podY, entries: []store.IndexEntry{onNodeN, appWeb}},
+				{key: podX, remove: true},
+			},
+			want: map[store.IndexEntry][]string{
+				onNodeN: {podY},
+				appWeb:  {podY},
+			},
+		},
+		{
+			name: "removing an unknown key",
+			steps: []step{
+				{key: podX, entries: []store.IndexEntry{onNodeN}},
+				{key: podY, remove: true},
+			},
+			want: map[store.IndexEntry][]string{onNodeN: {podX}},
+		},
+		{
+			name: "indexing again after removing",
+			steps: []step{
+				{key: podX, entries: []store.IndexEntry{onNodeN}},
+				{key: podX, remove: true},
+				{key: podX, entries: []store.IndexEntry{onNodeM}},
+			},
+			want: map[store.IndexEntry][]string{
+				onNodeN: {},
+				onNodeM: {podX},
+			},
+		},
+		{
+			name: "duplicate entries",
+			steps: []step{
+				{key: podX, entries: []store.IndexEntry{onNodeN, onNodeN}},
+				{key: podX, remove: true},
+			},
+			want: map[store.IndexEntry][]string{onNodeN: {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := store.NewIndexes()
+			for _, s := range tt.steps {
+				if s.remove {
+					idx.Remove(s.key)
+				} else {
+					idx.Set(s.key, s.entries)
+				}
+			}
+
+			for entry, want := range tt.want {
+				var keys []string
+				for _, key := range idx.Lookup(entry.Index, entry.Value) {
+					keys = append(keys, string(key))
+				}
+				if !slices.Equal(keys, want) {
+					t.Errorf("looked up %v under %s=%s, want %v", keys, entry.Index, entry.Value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreIndexes(t *testing.T) {
+	backends := []struct {
+		name string
+		open func(t *testing.T) store.Store
+	}{
+		{
+			name: "otter",
+			open: func(t *testing.T) store.Store {
+				db, err := store.NewOtterStore(store.OtterOptions{Eviction: store.EvictionNone})
+				if err != nil {
+					t.Fatalf("unable to create store: %v", err)
+				}
+				return db
+			},
+		},
+		{
+			name: "bolt",
+			open: func(t *testing.T) store.Store {
+				return newTestBoltStore(t, filepath.Join(t.TempDir(), "test.db"))
+			},
+		},
+		{
+			name: "typed",
+			open: func(t *testing.T) store.Store {
+				return newTestTypedStore(t, nil)
+			},
+		},
+		{
+			name: "typed over bolt",
+			open: func(t *testing.T) store.Store {
+				return newTestTypedStore(t, newTestBoltStore(t, filepath.Join(t.TempDir(), "test.db")))
+			},
+		},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			db := backend.open(t)
+			defer db.Close()
+
+			set := func(key string, entries ...store.IndexEntry) {
+				t.Helper()
+				if err := db.Set([]byte(key), []byte(`{}`)); err != nil {
+					t.Fatalf("unable to set %s: %v", key, err)
+				}
+				if err := db.SetIndex([]byte(key), entries); err != nil {
+					t.Fatalf("unable to index %s: %v", key, err)
+				}
+			}
+			lookup := func(step string, want map[store.IndexEntry][]string) {
+				t.Helper()
+				for entry, keys := range want {
+					if got := lookupKeys(t, db, entry.Index, entry.Value); !slices.Equal(got, keys) {
+						t.Errorf("%s: looked up %v under %s=%s, want %v", step, got, entry.Index, entry.Value, keys)
+					}
+				}
+			}
+
+			set(podX, onNodeN, appWeb)
+			set(podY, onNodeN, appWeb)
+			set(podZ, onNodeM)
+			lookup("indexed", map[store.IndexEntry][]string{
+				onNodeN: {podX, podY},
+				onNodeM: {podZ},
+				appWeb:  {podX, podY},
+			})
+
+			// podX moved to node m and lost its label
+			set(podX, onNodeM)
+			lookup("re-indexed", map[store.IndexEntry][]string{
+				onNodeN: {podY},
+				onNodeM: {podX, podZ},
+				appWeb:  {podY},
+			})
+
+			if err := db.Delete([]byte(podY)); err != nil {
+				t.Fatalf("unable to delete: %v", err)
+			}
+			lookup("deleted", map[store.IndexEntry][]string{
+				onNodeN: {},
+				onNodeM: {podX, podZ},
+				appWeb:  {},
+			})
+
+			// Rewriting the value keeps the entries
+			if err := db.Set([]byte(podZ), []byte(`{"changed":true}`)); err != nil {
+				t.Fatalf("unable to set: %v", err)
+			}
+			lookup("rewritten", map[store.IndexEntry][]string{onNodeM: {podX, podZ}})
+		})
+	}
+}
+
+func lookupKeys(t *testing.T, db store.Store, index string, value string) []string {
+	t.Helper()
+
+	found, err := db.Lookup(index, value)
+	if err != nil {
+		t.Fatalf("unable to look up %s=%s: %v", index, value, err)
+	}
+	keys := make([]string, 0, len(found))
+	for _, key := range found {
+		keys = append(keys, string(key))
+	}
+	return keys
+}
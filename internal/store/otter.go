@@ -3,39 +3,101 @@ package store
 import (
 	"fmt"
 	"log/slog"
+	"math"
 	"strings"
+	"sync/atomic"
 
 	"github.com/maypok86/otter/v2"
 	"github.com/maypok86/otter/v2/stats"
+
+	"polar-bear/internal/metrics"
+)
+
+// Eviction policies of the OtterStore.
+const (
+	EvictionCount = "count" // evict beyond a number of objects
+	EvictionSize  = "size"  // evict beyond a serialised size of all objects
+	EvictionNone  = "none"  // never evict, grows with the cluster
 )
 
+type OtterOptions struct {
+	Eviction   string
+	MaxObjects uint64 // used by EvictionCount
+	MaxBytes   uint64 // used by EvictionSize, counts keys and values
+}
+
 type OtterStore struct {
 	logger  *slog.Logger
 	cache   *otter.Cache[string, string]
 	counter *stats.Counter
+	indexes *Indexes
+	warned  atomic.Bool
 }
 
-func NewOtterStore() (Store, error) {
-	counter := stats.NewCounter()
+func NewOtterStore(opts OtterOptions) (Store, error) {
+	os := &OtterStore{
+		logger:  slog.With("component", "otter-store"),
+		counter: stats.NewCounter(),
+		indexes: NewIndexes(),
+	}
 
-	o, err := otter.New(&otter.Options[string, string]{
-		MaximumSize:   10_000,
-		StatsRecorder: counter,
-	})
+	options := &otter.Options[string, string]{
+		StatsRecorder: os.counter,
+		OnAtomicDeletion: func(e otter.DeletionEvent[string, string]) {
+			// Explicit deletes clean up in Delete, replacements keep their entries
+			if e.Cause.IsEviction() {
+				os.indexes.Remove(e.Key)
+			}
+		},
+		OnDeletion: func(e otter.DeletionEvent[string, string]) {
+			if e.Cause.IsEviction() {
+				os.evicted(e.Key)
+			}
+		},
+	}
 
-	if err != nil {
-		return nil, err
+	switch opts.Eviction {
+	case EvictionCount:
+		if opts.MaxObjects == 0 {
+			return nil, fmt.Errorf("maximum number of objects must be greater than zero")
+		}
+		options.MaximumSize = int(opts.MaxObjects)
+	case EvictionSize:
+		if opts.MaxBytes == 0 {
+			return nil, fmt.Errorf("maximum size must be greater than zero")
+		}
+		options.MaximumWeight = opts.MaxBytes
+		options.Weigher = func(key string, value string) uint32 {
+			return uint32(min(uint64(len(key)+len(value)), math.MaxUint32))
+		}
+	case EvictionNone:
+	default:
+		return nil, fmt.Errorf("unknown eviction %q, one of count/size/none", opts.Eviction)
 	}
 
-	os := &OtterStore{
-		logger:  slog.With("component", "otter-store"),
-		cache:   o,
-		counter: counter,
+	o, err := otter.New(options)
+	if err != nil {
+		return nil, err
 	}
+	os.cache = o
 
 	return os, nil
 }
 
+// evicted records that the store dropped a resource the informers still
+// hold, it will be missing from all pages until it changes again.
+func (os *OtterStore) evicted(key string) {
+	metrics.StoreEvictions.Inc()
+
+	if os.warned.CompareAndSwap(false, true) {
+		os.logger.Warn(
+			"store is full, evicting resources, pages will be incomplete",
+			"key", key,
+			"hint", "increase the store capacity or disable eviction",
+		)
+	}
+}
+
 func (os *OtterStore) Set(key []byte, value []byte) error {
 	os.cache.Set(string(key), string(value))
 	return nil
@@ -73,12 +135,22 @@ func (os *OtterStore) Count(prefix []byte) (uint, error) {
 }
 
 func (os *OtterStore) Delete(key []byte) error {
+	os.indexes.Remove(string(key))
 	if _, invalidated := os.cache.Invalidate(string(key)); invalidated {
 		return nil
 	}
 	return fmt.Errorf("not deleted")
 }
 
+func (os *OtterStore) SetIndex(key []byte, entries []IndexEntry) error {
+	os.indexes.Set(string(key), entries)
+	return nil
+}
+
+func (os *OtterStore) Lookup(index string, value string) ([][]byte, error) {
+	return os.indexes.Lookup(index, value), nil
+}
+
 func (os *OtterStore) Close() error {
 	return nil
 }
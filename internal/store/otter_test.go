@@ -0,0 +1,110 @@
+package store_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"polar-bear/internal/store"
+)
+
+func TestNewOtterStoreOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    store.OtterOptions
+		wantErr bool
+	}{
+		{"count", store.OtterOptions{Eviction: store.EvictionCount, MaxObjects: 10}, false},
+		{"count without maximum", store.OtterOptions{Eviction: store.EvictionCount}, true},
+		{"size", store.OtterOptions{Eviction: store.EvictionSize, MaxBytes: 1 << 20}, false},
+		{"size without maximum", store.OtterOptions{Eviction: store.EvictionSize}, true},
+		{"none", store.OtterOptions{Eviction: store.EvictionNone}, false},
+		{"unknown", store.OtterOptions{Eviction: "lru", MaxObjects: 10}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.NewOtterStore(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOtterStoreEviction(t *testing.T) {
+	const written = 200
+	value := `{"spec":"` + strings.Repeat("x", 90) + `"}`
+
+	tests := []struct {
+		name     string
+		opts     store.OtterOptions
+		maxCount uint
+	}{
+		{
+			name:     "count",
+			opts:     store.OtterOptions{Eviction: store.EvictionCount, MaxObjects: 20},
+			maxCount: 20,
+		},
+		{
+			// Keys and values of about 120 bytes
+			name:     "size",
+			opts:     store.OtterOptions{Eviction: store.EvictionSize, MaxBytes: 20 * 120},
+			maxCount: 20,
+		},
+		{
+			name:     "none",
+			opts:     store.OtterOptions{Eviction: store.EvictionNone},
+			maxCount: written,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := store.NewOtterStore(tt.opts)
+			if err != nil {
+				t.Fatalf("unable to create store: %v", err)
+			}
+			defer db.Close()
+
+			for i := range written {
+				key := resourceKey("pod", "a", fmt.Sprintf("pod-%03d", i))
+				if err := db.Set([]byte(key), []byte(value)); err != nil {
+					t.Fatalf("unable to set %s: %v", key, err)
+				}
+				if err := db.SetIndex([]byte(key), []store.IndexEntry{onNodeN}); err != nil {
+					t.Fatalf("unable to index %s: %v", key, err)
+				}
+			}
+
+			// Evictions are applied in the background
+			var count uint
+			for deadline := time.Now().Add(5 * time.Second); ; {
+				if count, err = db.Count(nil); err != nil {
+					t.Fatalf("unable to count: %v", err)
+				}
+				if count <= tt.maxCount && uint64(written-count) == db.Stats().Evictions {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("holding %d keys after %d evictions, want at most %d", count, db.Stats().Evictions, tt.maxCount)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if tt.maxCount < written && count == 0 {
+				t.Error("evicted all keys")
+			}
+			if evictions := db.Stats().Evictions; tt.maxCount == written && evictions != 0 {
+				t.Errorf("evicted %d keys without eviction", evictions)
+			}
+
+			// Evicted keys are no longer found in the indexes
+			if keys := lookupKeys(t, db, store.IndexNode, "n"); !slices.Equal(keys, storedKeys(t, db)) {
+				t.Errorf("looked up %d keys, want the %d stored keys", len(keys), count)
+			}
+		})
+	}
+}
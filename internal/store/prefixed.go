@@ -0,0 +1,110 @@
+package store
+
+import "strings"
+
+// PrefixedStore keeps the resources of one of several clusters in a shared
+// store, below a key prefix of its own. Keys are passed to and returned from
+// it without the prefix, so readers don't know about other clusters.
+type PrefixedStore struct {
+	next   Store
+	prefix string
+}
+
+// prefixedObjectStore is a PrefixedStore of an ObjectStore.
+type prefixedObjectStore struct {
+	*PrefixedStore
+	objects ObjectStore
+}
+
+func NewPrefixedStore(next Store, prefix string) Store {
+	ps := &PrefixedStore{
+		next:   next,
+		prefix: prefix,
+	}
+	if os, ok := next.(ObjectStore); ok {
+		return &prefixedObjectStore{PrefixedStore: ps, objects: os}
+	}
+	return ps
+}
+
+func (ps *PrefixedStore) key(key []byte) []byte {
+	return append([]byte(ps.prefix), key...)
+}
+
+func (ps *PrefixedStore) Set(key []byte, value []byte) error {
+	return ps.next.Set(ps.key(key), value)
+}
+
+func (ps *PrefixedStore) Get(key []byte) ([]byte, error) {
+	return ps.next.Get(ps.key(key))
+}
+
+func (ps *PrefixedStore) GetAll(prefix []byte) (map[string][]byte, error) {
+	keyVals, err := ps.next.GetAll(ps.key(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string][]byte, len(keyVals))
+	for key, value := range keyVals {
+		results[strings.TrimPrefix(key, ps.prefix)] = value
+	}
+	return results, nil
+}
+
+func (ps *PrefixedStore) Count(prefix []byte) (uint, error) {
+	return ps.next.Count(ps.key(prefix))
+}
+
+func (ps *PrefixedStore) Delete(key []byte) error {
+	return ps.next.Delete(ps.key(key))
+}
+
+func (ps *PrefixedStore) SetIndex(key []byte, entries []IndexEntry) error {
+	return ps.next.SetIndex(ps.key(key), entries)
+}
+
+// Lookup only returns the keys of this store, index values like node names
+// may exist in other clusters too.
+func (ps *PrefixedStore) Lookup(index string, value string) ([][]byte, error) {
+	keys, err := ps.next.Lookup(index, value)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		if rest, ok := strings.CutPrefix(string(key), ps.prefix); ok {
+			results = append(results, []byte(rest))
+		}
+	}
+	return results, nil
+}
+
+// Close does nothing, the shared store is closed by its owner.
+func (ps *PrefixedStore) Close() error {
+	return nil
+}
+
+// Stats reports the shared store.
+func (ps *PrefixedStore) Stats() Stats {
+	return ps.next.Stats()
+}
+
+func (ps *prefixedObjectStore) SetObject(key []byte, obj any) error {
+	return ps.objects.SetObject(ps.key(key), obj)
+}
+
+func (ps *prefixedObjectStore) GetObject(key []byte) (any, bool) {
+	return ps.objects.GetObject(ps.key(key))
+}
+
+func (ps *prefixedObjectStore) GetAllObjects(prefix []byte) map[string]any {
+	objs := ps.objects.GetAllObjects(ps.key(prefix))
+
+	results := make(map[string]any, len(objs))
+	for key, obj := range objs {
+		results[strings.TrimPrefix(key, ps.prefix)] = obj
+	}
+	return results
+}
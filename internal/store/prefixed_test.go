@@ -0,0 +1,153 @@
+package store_test
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"polar-bear/internal/store"
+)
+
+var (
+	webPod  = resourceKey("pod", "default", "web")
+	dbPod   = resourceKey("pod", "default", "db")
+	node1   = resourceKey("node", "", "n1")
+	xWebPod = resourceKey("pod", "x", "web")
+)
+
+func TestPrefixedStoreIsolation(t *testing.T) {
+	backends := []struct {
+		name string
+		open func() (store.Store, error)
+	}{
+		{
+			name: "otter",
+			open: func() (store.Store, error) {
+				return store.NewOtterStore(store.OtterOptions{Eviction: store.EvictionNone})
+			},
+		},
+		{
+			name: "typed",
+			open: func() (store.Store, error) { return store.NewTypedStore(nil) },
+		},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			shared, err := backend.open()
+			if err != nil {
+				t.Fatalf("unable to create store: %v", err)
+			}
+
+			// Cluster names that are prefixes of each other
+			a := store.NewPrefixedStore(shared, "c/a/")
+			ab := store.NewPrefixedStore(shared, "c/ab/")
+
+			for _, tt := range []struct {
+				db    store.Store
+				key   string
+				value string
+			}{
+				{a, webPod, `"a-web"`},
+				{a, node1, `"a-n1"`},
+				{ab, webPod, `"ab-web"`},
+				{ab, dbPod, `"ab-db"`},
+			} {
+				if err := tt.db.Set([]byte(tt.key), []byte(tt.value)); err != nil {
+					t.Fatalf("unable to set %s: %v", tt.key, err)
+				}
+				if err := tt.db.SetIndex([]byte(tt.key), []store.IndexEntry{{Index: store.IndexNode, Value: "n1"}}); err != nil {
+					t.Fatalf("unable to index %s: %v", tt.key, err)
+				}
+			}
+
+			if value, err := a.Get([]byte(webPod)); err != nil || string(value) != `"a-web"` {
+				t.Errorf("got %s (error %v) from a, want its own value", value, err)
+			}
+			if value, err := ab.Get([]byte(webPod)); err != nil || string(value) != `"ab-web"` {
+				t.Errorf("got %s (error %v) from ab, want its own value", value, err)
+			}
+			if _, err := ab.Get([]byte(node1)); err == nil {
+				t.Error("got a key of a from ab")
+			}
+
+			keyVals, err := a.GetAll([]byte("ns/"))
+			if err != nil {
+				t.Fatalf("unable to get all: %v", err)
+			}
+			if keys := slices.Sorted(maps.Keys(keyVals)); !slices.Equal(keys, []string{webPod}) {
+				t.Errorf("got keys %v from a, want only its own without prefix", keys)
+			}
+			if count, err := ab.Count([]byte("ns/")); err != nil || count != 2 {
+				t.Errorf("counted %d keys (error %v) in ab, want 2", count, err)
+			}
+			if count, err := shared.Count(nil); err != nil || count != 4 {
+				t.Errorf("counted %d keys (error %v) in the shared store, want 4", count, err)
+			}
+
+			if keys := lookupKeys(t, a, store.IndexNode, "n1"); !slices.Equal(keys, []string{node1, webPod}) {
+				t.Errorf("looked up %v in a, want only its own keys without prefix", keys)
+			}
+			if keys := lookupKeys(t, ab, store.IndexNode, "n1"); !slices.Equal(keys, []string{dbPod, webPod}) {
+				t.Errorf("looked up %v in ab, want only its own keys without prefix", keys)
+			}
+
+			if err := a.Delete([]byte(webPod)); err != nil {
+				t.Fatalf("unable to delete: %v", err)
+			}
+			if _, err := ab.Get([]byte(webPod)); err != nil {
+				t.Errorf("deleting from a removed the key of ab: %v", err)
+			}
+			if keys := lookupKeys(t, ab, store.IndexNode, "n1"); len(keys) != 2 {
+				t.Errorf("looked up %v in ab after deleting from a, want both its keys", keys)
+			}
+
+			if err := a.Close(); err != nil {
+				t.Errorf("unable to close a: %v", err)
+			}
+			if _, err := ab.Get([]byte(dbPod)); err != nil {
+				t.Errorf("closing a closed the shared store: %v", err)
+			}
+		})
+	}
+}
+
+func TestPrefixedObjectStore(t *testing.T) {
+	shared, err := store.NewTypedStore(nil)
+	if err != nil {
+		t.Fatalf("unable to create store: %v", err)
+	}
+	a, ok := store.NewPrefixedStore(shared, "c/a/").(store.ObjectStore)
+	if !ok {
+		t.Fatal("prefixed typed store is no object store")
+	}
+	b := store.NewPrefixedStore(shared, "c/b/").(store.ObjectStore)
+
+	type object struct{ Name string }
+	if err := a.SetObject([]byte(xWebPod), &object{Name: "a"}); err != nil {
+		t.Fatalf("unable to set object: %v", err)
+	}
+	if err := b.SetObject([]byte(xWebPod), &object{Name: "b"}); err != nil {
+		t.Fatalf("unable to set object: %v", err)
+	}
+
+	if obj, ok := a.GetObject([]byte(xWebPod)); !ok || obj.(*object).Name != "a" {
+		t.Errorf("got object %v from a, want its own", obj)
+	}
+
+	objs := b.GetAllObjects([]byte("ns/"))
+	if len(objs) != 1 || objs[xWebPod].(*object).Name != "b" {
+		t.Errorf("got objects %v from b, want only its own without prefix", objs)
+	}
+
+	// Plain reads of objects are marshalled
+	value, err := b.Get([]byte(xWebPod))
+	if err != nil {
+		t.Fatalf("unable to get: %v", err)
+	}
+	var obj object
+	if err := json.Unmarshal(value, &obj); err != nil || obj.Name != "b" {
+		t.Errorf("got %s (error %v) from b, want its own object", value, err)
+	}
+}
@@ -8,10 +8,18 @@ type Store interface {
 	GetAll(prefix []byte) (keyValues map[string][]byte, err error)
 	Count(prefix []byte) (count uint, err error)
 	Delete(key []byte) (err error)
+	SetIndex(key []byte, entries []IndexEntry) (err error)
+	Lookup(index string, value string) (keys [][]byte, err error)
 	Close() (err error)
 	Stats() Stats
 }
 
+// Reconciler is implemented by stores that keep data across restarts. Once
+// the informers have synced, Reconcile removes what was deleted meanwhile.
+type Reconciler interface {
+	Reconcile() (removed uint, err error)
+}
+
 type Stats struct {
 	Hits           uint64
 	Misses         uint64
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ObjectStore is implemented by stores that keep the decoded objects, so
+// readers don't need to unmarshal them again. Objects are shared between all
+// readers and the informers and must not be modified.
+type ObjectStore interface {
+	Store
+	SetObject(key []byte, obj any) (err error)
+	GetObject(key []byte) (obj any, ok bool)
+	GetAllObjects(prefix []byte) (objs map[string]any)
+}
+
+// indexSource is implemented by persistent stores that restore index entries
+// on start, so they can be restored into a TypedStore as well.
+type indexSource interface {
+	IndexEntries() map[string][]IndexEntry
+}
+
+// TypedStore keeps the objects written by the informers as they are and serves
+// all reads from memory. If it wraps a persistent store, objects are
+// serialised into it as well, and restored from it as json.RawMessage on
+// start until the informers write them again.
+type TypedStore struct {
+	logger  *slog.Logger
+	next    Store // optional persistent store, only written to after start
+	indexes *Indexes
+
+	mu      sync.RWMutex
+	objects map[string]any
+	stale   map[string]struct{} // keys restored from next, nil once reconciled
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func NewTypedStore(next Store) (Store, error) {
+	ts := &TypedStore{
+		logger:  slog.With("component", "typed-store"),
+		next:    next,
+		indexes: NewIndexes(),
+		objects: make(map[string]any),
+	}
+	if next == nil {
+		return ts, nil
+	}
+
+	if err := ts.restore(); err != nil {
+		return nil, fmt.Errorf("failed to restore from persistent store: %v", err)
+	}
+	return ts, nil
+}
+
+// restore loads all values and index entries of the persistent store.
+func (ts *TypedStore) restore() error {
+	keyVals, err := ts.next.GetAll(nil)
+	if err != nil {
+		return err
+	}
+
+	ts.stale = make(map[string]struct{}, len(keyVals))
+	for key, value := range keyVals {
+		ts.objects[key] = json.RawMessage(value)
+		ts.stale[key] = struct{}{}
+	}
+
+	if src, ok := ts.next.(indexSource); ok {
+		for key, entries := range src.IndexEntries() {
+			ts.indexes.Set(key, entries)
+		}
+	}
+	return nil
+}
+
+func (ts *TypedStore) SetObject(key []byte, obj any) error {
+	ts.mu.Lock()
+	ts.objects[string(key)] = obj
+	delete(ts.stale, string(key))
+	ts.mu.Unlock()
+
+	if ts.next == nil {
+		return nil
+	}
+
+	value, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return ts.next.Set(key, value)
+}
+
+func (ts *TypedStore) GetObject(key []byte) (any, bool) {
+	ts.mu.RLock()
+	obj, ok := ts.objects[string(key)]
+	ts.mu.RUnlock()
+
+	if !ok {
+		ts.misses.Add(1)
+		return nil, false
+	}
+
+	ts.hits.Add(1)
+	return obj, true
+}
+
+func (ts *TypedStore) GetAllObjects(prefix []byte) map[string]any {
+	results := make(map[string]any)
+
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	for key, obj := range ts.objects {
+		if strings.HasPrefix(key, string(prefix)) {
+			results[key] = obj
+		}
+	}
+
+	return results
+}
+
+func (ts *TypedStore) Set(key []byte, value []byte) error {
+	return ts.SetObject(key, json.RawMessage(value))
+}
+
+func (ts *TypedStore) Get(key []byte) ([]byte, error) {
+	obj, ok := ts.GetObject(key)
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return json.Marshal(obj)
+}
+
+func (ts *TypedStore) GetAll(prefix []byte) (map[string][]byte, error) {
+	results := make(map[string][]byte)
+
+	for key, obj := range ts.GetAllObjects(prefix) {
+		value, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		results[key] = value
+	}
+
+	return results, nil
+}
+
+func (ts *TypedStore) Count(prefix []byte) (uint, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	count := uint(0)
+	for key := range ts.objects {
+		if strings.HasPrefix(key, string(prefix)) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (ts *TypedStore) Delete(key []byte) error {
+	ts.mu.Lock()
+	_, ok := ts.objects[string(key)]
+	delete(ts.objects, string(key))
+	delete(ts.stale, string(key))
+	ts.mu.Unlock()
+
+	ts.indexes.Remove(string(key))
+	if ts.next != nil {
+		return ts.next.Delete(key)
+	}
+
+	if !ok {
+		return fmt.Errorf("not deleted")
+	}
+	return nil
+}
+
+func (ts *TypedStore) SetIndex(key []byte, entries []IndexEntry) error {
+	ts.indexes.Set(string(key), entries)
+
+	if ts.next != nil {
+		return ts.next.SetIndex(key, entries)
+	}
+	return nil
+}
+
+func (ts *TypedStore) Lookup(index string, value string) ([][]byte, error) {
+	return ts.indexes.Lookup(index, value), nil
+}
+
+// Reconcile removes the objects restored on start that were not written since
+// and passes through to the persistent store, see Reconciler.
+func (ts *TypedStore) Reconcile() (uint, error) {
+	ts.mu.Lock()
+	stale := ts.stale
+	ts.stale = nil
+	for key := range stale {
+		delete(ts.objects, key)
+	}
+	ts.mu.Unlock()
+
+	for key := range stale {
+		ts.indexes.Remove(key)
+	}
+
+	if rec, ok := ts.next.(Reconciler); ok {
+		return rec.Reconcile()
+	}
+	return uint(len(stale)), nil
+}
+
+func (ts *TypedStore) Close() error {
+	if ts.next != nil {
+		return ts.next.Close()
+	}
+	return nil
+}
+
+// Stats counts object reads as hits and misses, everything else is reported
+// by the persistent store. Nothing is ever evicted.
+func (ts *TypedStore) Stats() Stats {
+	stats := Stats{}
+	if ts.next != nil {
+		stats = ts.next.Stats()
+	}
+
+	stats.Hits = ts.hits.Load()
+	stats.Misses = ts.misses.Load()
+	return stats
+}
@@ -0,0 +1,180 @@
+package store_test
+
+import (
+	"encoding/json"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"polar-bear/internal/store"
+)
+
+type testObject struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func newTestTypedStore(t *testing.T, next store.Store) store.ObjectStore {
+	t.Helper()
+
+	db, err := store.NewTypedStore(next)
+	if err != nil {
+		t.Fatalf("unable to create store: %v", err)
+	}
+	return db.(store.ObjectStore)
+}
+
+func TestTypedStoreRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		next func(t *testing.T) store.Store
+	}{
+		{
+			name: "memory",
+			next: func(*testing.T) store.Store { return nil },
+		},
+		{
+			name: "bolt",
+			next: func(t *testing.T) store.Store {
+				return newTestBoltStore(t, filepath.Join(t.TempDir(), "test.db"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestTypedStore(t, tt.next(t))
+			defer db.Close()
+
+			obj := &testObject{Name: "x", Count: 1}
+			if err := db.SetObject([]byte(podX), obj); err != nil {
+				t.Fatalf("unable to set object: %v", err)
+			}
+			if err := db.Set([]byte(podY), []byte(`{"name":"y","count":2}`)); err != nil {
+				t.Fatalf("unable to set value: %v", err)
+			}
+			if err := db.SetObject([]byte(nodeN), &testObject{Name: "n"}); err != nil {
+				t.Fatalf("unable to set object: %v", err)
+			}
+
+			// Objects are returned as they are, not copies
+			if got, ok := db.GetObject([]byte(podX)); !ok || got != any(obj) {
+				t.Errorf("got object %v, want the object set", got)
+			}
+			if got, ok := db.GetObject([]byte(podY)); !ok || string(got.(json.RawMessage)) != `{"name":"y","count":2}` {
+				t.Errorf("got object %v, want the raw value set", got)
+			}
+			if _, ok := db.GetObject([]byte(podZ)); ok {
+				t.Error("got object of a missing key")
+			}
+
+			var decoded testObject
+			value, err := db.Get([]byte(podX))
+			if err != nil {
+				t.Fatalf("unable to get value: %v", err)
+			}
+			if err := json.Unmarshal(value, &decoded); err != nil || decoded != *obj {
+				t.Errorf("got value %s (error %v), want the marshalled object", value, err)
+			}
+			if _, err := db.Get([]byte(podZ)); err == nil {
+				t.Error("got value of a missing key")
+			}
+
+			objs := db.GetAllObjects([]byte("ns/"))
+			if keys := slices.Sorted(maps.Keys(objs)); !slices.Equal(keys, []string{podX, podY}) {
+				t.Errorf("got objects of %v, want the pods", keys)
+			}
+			keyVals, err := db.GetAll([]byte("ns/"))
+			if err != nil || string(keyVals[podY]) != `{"name":"y","count":2}` || len(keyVals) != 2 {
+				t.Errorf("got values %v (error %v), want the pods", keyVals, err)
+			}
+			if count, err := db.Count(nil); err != nil || count != 3 {
+				t.Errorf("counted %d (error %v), want 3", count, err)
+			}
+
+			if err := db.Delete([]byte(podX)); err != nil {
+				t.Fatalf("unable to delete: %v", err)
+			}
+			if _, ok := db.GetObject([]byte(podX)); ok {
+				t.Error("got object after deleting it")
+			}
+			if count, err := db.Count([]byte("ns/")); err != nil || count != 1 {
+				t.Errorf("counted %d (error %v) after deleting, want 1", count, err)
+			}
+			if err := db.Delete([]byte(podX)); err == nil {
+				t.Error("deleted a missing key")
+			}
+
+			if stats := db.Stats(); stats.Hits == 0 || stats.Misses == 0 {
+				t.Errorf("got stats %+v, want hits and misses", stats)
+			}
+		})
+	}
+}
+
+func TestTypedStoreRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	db := newTestTypedStore(t, newTestBoltStore(t, path))
+	for _, key := range []string{podX, podY, nodeN} {
+		if err := db.SetObject([]byte(key), &testObject{Name: key}); err != nil {
+			t.Fatalf("unable to set %s: %v", key, err)
+		}
+		if err := db.SetIndex([]byte(key), []store.IndexEntry{{Index: store.IndexNode, Value: "n"}}); err != nil {
+			t.Fatalf("unable to index %s: %v", key, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("unable to close store: %v", err)
+	}
+
+	bolt := newTestBoltStore(t, path)
+	db = newTestTypedStore(t, bolt)
+	defer db.Close()
+
+	// Restored objects are served from memory as raw JSON until written again
+	obj, ok := db.GetObject([]byte(podX))
+	if !ok {
+		t.Fatal("object not restored")
+	}
+	var decoded testObject
+	if err := json.Unmarshal(obj.(json.RawMessage), &decoded); err != nil || decoded.Name != podX {
+		t.Errorf("got restored object %s (error %v), want the object set", obj, err)
+	}
+	if count, err := db.Count(nil); err != nil || count != 3 {
+		t.Errorf("counted %d (error %v) after restart, want 3", count, err)
+	}
+	if keys := lookupKeys(t, db, store.IndexNode, "n"); !slices.Equal(keys, []string{nodeN, podX, podY}) {
+		t.Errorf("looked up %v after restart, want all restored keys", keys)
+	}
+
+	// podX is written again, podY was deleted meanwhile and nodeN is stale
+	updated := &testObject{Name: podX, Count: 2}
+	if err := db.SetObject([]byte(podX), updated); err != nil {
+		t.Fatalf("unable to set object: %v", err)
+	}
+	if err := db.Delete([]byte(podY)); err != nil {
+		t.Fatalf("unable to delete: %v", err)
+	}
+	if got, _ := db.GetObject([]byte(podX)); got != any(updated) {
+		t.Errorf("got object %v, want the object written after restart", got)
+	}
+
+	removed, err := db.(store.Reconciler).Reconcile()
+	if err != nil {
+		t.Fatalf("unable to reconcile: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed %d keys, want 1", removed)
+	}
+	if keys := slices.Sorted(maps.Keys(db.GetAllObjects(nil))); !slices.Equal(keys, []string{podX}) {
+		t.Errorf("got objects of %v after reconciling, want %v", keys, []string{podX})
+	}
+	if keys := lookupKeys(t, db, store.IndexNode, "n"); !slices.Equal(keys, []string{podX}) {
+		t.Errorf("looked up %v after reconciling, want %v", keys, []string{podX})
+	}
+	if keys := storedKeys(t, bolt); !slices.Equal(keys, []string{podX}) {
+		t.Errorf("got keys %v in bolt after reconciling, want %v", keys, []string{podX})
+	}
+}
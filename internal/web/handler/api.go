@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/store"
+)
+
+// apiKind reads the objects of a namespaced kind for the JSON API.
+type apiKind struct {
+	list func(store store.Store, ns string) []any
+	get  func(store store.Store, ns string, name string) (any, bool)
+}
+
+func apiKindOf[T core.KubernetesResource]() apiKind {
+	return apiKind{
+		list: func(store store.Store, ns string) []any {
+			return anySlice(core.GetResources[T](store, ns))
+		},
+		get: func(store store.Store, ns string, name string) (any, bool) {
+			var zero T
+			res := core.GetResource[T](store, ns, name)
+			return res, res != zero
+		},
+	}
+}
+
+// apiKinds are the namespaced kinds of the JSON API, by the short names used
+// in the URLs of the HTML views. Custom resources are looked up by type name.
+var apiKinds = map[string]apiKind{
+	"pd":      apiKindOf[*corev1.Pod](),
+	"rs":      apiKindOf[*appsv1.ReplicaSet](),
+	"sts":     apiKindOf[*appsv1.StatefulSet](),
+	"ds":      apiKindOf[*appsv1.DaemonSet](),
+	"deploy":  apiKindOf[*appsv1.Deployment](),
+	"job":     apiKindOf[*batchv1.Job](),
+	"cronjob": apiKindOf[*batchv1.CronJob](),
+	"svc":     apiKindOf[*corev1.Service](),
+	"epslice": apiKindOf[*discoveryv1.EndpointSlice](),
+	"ing":     apiKindOf[*networkingv1.Ingress](),
+	"netpol":  apiKindOf[*networkingv1.NetworkPolicy](),
+	"pvc":     apiKindOf[*corev1.PersistentVolumeClaim](),
+
+	"cm":          apiKindOf[*corev1.ConfigMap](),
+	"secret":      apiKindOf[*corev1.Secret](),
+	"sa":          apiKindOf[*corev1.ServiceAccount](),
+	"role":        apiKindOf[*rbacv1.Role](),
+	"rolebinding": apiKindOf[*rbacv1.RoleBinding](),
+}
+
+// apiClusterKinds are the cluster-scoped kinds of the JSON API besides nodes
+// and namespaces, by the short names used in the URLs of the HTML views.
+var apiClusterKinds = map[string]apiKind{
+	"pv":        apiKindOf[*corev1.PersistentVolume](),
+	"sc":        apiKindOf[*storagev1.StorageClass](),
+	"csidriver": apiKindOf[*storagev1.CSIDriver](),
+
+	"clusterrole":        apiKindOf[*rbacv1.ClusterRole](),
+	"clusterrolebinding": apiKindOf[*rbacv1.ClusterRoleBinding](),
+}
+
+func APINodes(store store.Store) http.Handler {
+	return apiList(func(_ *http.Request) ([]any, bool) {
+		return anySlice(core.GetNodes(store)), true
+	})
+}
+
+func APINode(store store.Store) http.Handler {
+	return apiObject(func(r *http.Request) (any, bool) {
+		no := core.GetNode(store, r.PathValue("no"))
+		return no, no != nil
+	})
+}
+
+func APINamespaces(store store.Store) http.Handler {
+	return apiList(func(_ *http.Request) ([]any, bool) {
+		return anySlice(core.GetNamespaces(store)), true
+	})
+}
+
+func APINamespace(store store.Store) http.Handler {
+	return apiObject(func(r *http.Request) (any, bool) {
+		ns := core.GetNamespace(store, r.PathValue("ns"))
+		return ns, ns != nil
+	})
+}
+
+func APIResources(store store.Store) http.Handler {
+	return apiList(func(r *http.Request) ([]any, bool) {
+		ns, res := r.PathValue("ns"), r.PathValue("res")
+		if kind, ok := apiKinds[res]; ok {
+			return kind.list(store, ns), true
+		}
+
+		crt, ok := core.GetCustomResourceType(store, res)
+		if !ok || !crt.Namespaced {
+			return nil, false
+		}
+		return anySlice(core.GetCustomResources(store, crt, ns)), true
+	})
+}
+
+func APIResource(store store.Store) http.Handler {
+	return apiObject(func(r *http.Request) (any, bool) {
+		ns, res, name := r.PathValue("ns"), r.PathValue("res"), r.PathValue("name")
+		if kind, ok := apiKinds[res]; ok {
+			return kind.get(store, ns, name)
+		}
+
+		crt, ok := core.GetCustomResourceType(store, res)
+		if !ok || !crt.Namespaced {
+			return nil, false
+		}
+		cr := core.GetCustomResource(store, crt, ns, name)
+		return cr, cr != nil
+	})
+}
+
+// APIClusterResources serves the objects of a kind of apiClusterKinds.
+func APIClusterResources(store store.Store, res string) http.Handler {
+	kind := apiClusterKinds[res]
+	return apiList(func(_ *http.Request) ([]any, bool) {
+		return kind.list(store, ""), true
+	})
+}
+
+// APIClusterResource serves an object of a kind of apiClusterKinds.
+func APIClusterResource(store store.Store, res string) http.Handler {
+	kind := apiClusterKinds[res]
+	return apiObject(func(r *http.Request) (any, bool) {
+		return kind.get(store, "", r.PathValue("name"))
+	})
+}
+
+func APIClusterCustomResources(store store.Store) http.Handler {
+	return apiList(func(r *http.Request) ([]any, bool) {
+		crt, ok := core.GetCustomResourceType(store, r.PathValue("res"))
+		if !ok || crt.Namespaced {
+			return nil, false
+		}
+		return anySlice(core.GetCustomResources(store, crt, "")), true
+	})
+}
+
+func APIClusterCustomResource(store store.Store) http.Handler {
+	return apiObject(func(r *http.Request) (any, bool) {
+		crt, ok := core.GetCustomResourceType(store, r.PathValue("res"))
+		if !ok || crt.Namespaced {
+			return nil, false
+		}
+		cr := core.GetCustomResource(store, crt, "", r.PathValue("name"))
+		return cr, cr != nil
+	})
+}
+
+func APICustomResourceDefinitions(store store.Store) http.Handler {
+	return apiList(func(_ *http.Request) ([]any, bool) {
+		return anySlice(core.GetCustomResourceDefinitions(store)), true
+	})
+}
+
+func APIWarningEvents(store store.Store) http.Handler {
+	return apiList(func(_ *http.Request) ([]any, bool) {
+		return anySlice(core.GetWarningEvents(store, warningEventsLimit)), true
+	})
+}
+
+// apiListResponse is shaped like the lists of the Kubernetes API.
+type apiListResponse struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Metadata   apiListMetadata `json:"metadata"`
+	Items      []any           `json:"items"`
+}
+
+type apiListMetadata struct {
+	Continue           string `json:"continue,omitempty"`
+	RemainingItemCount *int   `json:"remainingItemCount,omitempty"`
+}
+
+// apiList serves the objects returned by list, filtered by the labelSelector,
+// paginated by the limit and continue, and projected by the fields parameters.
+// list returns false if the resource doesn't exist.
+func apiList(list func(r *http.Request) ([]any, bool)) http.Handler {
+	logger := slog.With("component", "handler-api")
+
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			q, err := parseAPIQuery(r.URL.Query())
+			if err != nil {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			objs, ok := list(r)
+			if !ok {
+				writeAPIError(w, http.StatusNotFound, "resource not found")
+				return
+			}
+
+			page, next, remaining, err := q.page(objs)
+			if err != nil {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			items := make([]any, 0, len(page))
+			for _, obj := range page {
+				item, err := q.project(obj)
+				if err != nil {
+					logger.Error("unable to select fields", "error", err)
+					writeAPIError(w, http.StatusInternalServerError, "unable to select fields")
+					return
+				}
+				items = append(items, item)
+			}
+
+			resp := apiListResponse{
+				APIVersion: "v1",
+				Kind:       "List",
+				Items:      items,
+			}
+			if next != "" {
+				resp.Metadata = apiListMetadata{Continue: next, RemainingItemCount: &remaining}
+			}
+			writeAPIResponse(logger, w, resp)
+		},
+	)
+}
+
+// apiObject serves the object returned by get, projected by the fields
+// parameter. get returns false if the object doesn't exist.
+func apiObject(get func(r *http.Request) (any, bool)) http.Handler {
+	logger := slog.With("component", "handler-api")
+
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			q, err := parseAPIQuery(r.URL.Query())
+			if err != nil {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			obj, ok := get(r)
+			if !ok {
+				writeAPIError(w, http.StatusNotFound, "object not found")
+				return
+			}
+
+			item, err := q.project(obj)
+			if err != nil {
+				logger.Error("unable to select fields", "error", err)
+				writeAPIError(w, http.StatusInternalServerError, "unable to select fields")
+				return
+			}
+			writeAPIResponse(logger, w, item)
+		},
+	)
+}
+
+func writeAPIResponse(logger *slog.Logger, w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("unable to write response", "error", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+func anySlice[T any](objs []T) []any {
+	res := make([]any, 0, len(objs))
+	for _, obj := range objs {
+		res = append(res, obj)
+	}
+	return res
+}
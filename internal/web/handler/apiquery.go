@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// apiQuery holds the query parameters of the JSON API:
+//
+//	labelSelector  label selector like kubectl's -l, e.g. app=web,tier!=cache
+//	limit          maximum number of items per page
+//	continue       token of the next page, returned by the previous one
+//	fields         comma separated field paths to return, e.g. metadata.name
+type apiQuery struct {
+	selector labels.Selector
+	limit    int
+	after    string     // namespace/name of the last item of the previous page
+	fields   [][]string // split field paths, nil returns the whole object
+}
+
+func parseAPIQuery(values url.Values) (apiQuery, error) {
+	q := apiQuery{selector: labels.Everything()}
+
+	if s := values.Get("labelSelector"); s != "" {
+		selector, err := labels.Parse(s)
+		if err != nil {
+			return q, fmt.Errorf("invalid labelSelector: %v", err)
+		}
+		q.selector = selector
+	}
+
+	if s := values.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return q, fmt.Errorf("invalid limit %q", s)
+		}
+		q.limit = limit
+	}
+
+	if s := values.Get("continue"); s != "" {
+		after, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return q, fmt.Errorf("invalid continue token")
+		}
+		q.after = string(after)
+	}
+
+	if s := values.Get("fields"); s != "" {
+		for field := range strings.SplitSeq(s, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				q.fields = append(q.fields, strings.Split(field, "."))
+			}
+		}
+	}
+
+	return q, nil
+}
+
+// page returns the objects of the requested page matching the selector, the
+// continue token of the next page and the number of objects after this page.
+func (q apiQuery) page(objs []any) (page []any, next string, remaining int, err error) {
+	type item struct {
+		id  string
+		obj any
+	}
+
+	items := make([]item, 0, len(objs))
+	for _, obj := range objs {
+		acc, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		if !q.selector.Matches(labels.Set(acc.GetLabels())) {
+			continue
+		}
+
+		id := acc.GetNamespace() + "/" + acc.GetName()
+		if q.after != "" && id <= q.after {
+			continue
+		}
+		items = append(items, item{id: id, obj: obj})
+	}
+	slices.SortFunc(items, func(a, b item) int { return strings.Compare(a.id, b.id) })
+
+	if q.limit > 0 && len(items) > q.limit {
+		next = base64.RawURLEncoding.EncodeToString([]byte(items[q.limit-1].id))
+		remaining = len(items) - q.limit
+		items = items[:q.limit]
+	}
+
+	page = make([]any, 0, len(items))
+	for _, it := range items {
+		page = append(page, it.obj)
+	}
+	return page, next, remaining, nil
+}
+
+// project returns obj with only the selected fields. Paths through lists are
+// applied to each of their elements.
+func (q apiQuery) project(obj any) (any, error) {
+	if q.fields == nil {
+		return obj, nil
+	}
+
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var full map[string]any
+	if err := json.Unmarshal(raw, &full); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]any)
+	for _, path := range q.fields {
+		copyField(full, res, path)
+	}
+	return res, nil
+}
+
+func copyField(src map[string]any, dst map[string]any, path []string) {
+	name := path[0]
+	value, ok := src[name]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[name] = value
+		return
+	}
+
+	// Parents are only added once a field below them was found
+	switch child := value.(type) {
+	case map[string]any:
+		sub, ok := dst[name].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+		}
+		copyField(child, sub, path[1:])
+		if len(sub) > 0 {
+			dst[name] = sub
+		}
+	case []any:
+		subs, ok := dst[name].([]any)
+		if !ok {
+			subs = make([]any, len(child))
+			for i := range subs {
+				subs[i] = make(map[string]any)
+			}
+		}
+		found := ok
+		for i, elem := range child {
+			if m, ok := elem.(map[string]any); ok {
+				sub := subs[i].(map[string]any)
+				copyField(m, sub, path[1:])
+				found = found || len(sub) > 0
+			}
+		}
+		if found {
+			dst[name] = subs
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func parseTestAPIQuery(t *testing.T, query string) apiQuery {
+	t.Helper()
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatalf("invalid query %q: %v", query, err)
+	}
+	q, err := parseAPIQuery(values)
+	if err != nil {
+		t.Fatalf("unable to parse query %q: %v", query, err)
+	}
+	return q
+}
+
+func TestParseAPIQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantLimit  int
+		wantAfter  string
+		wantFields [][]string
+		wantErr    bool
+	}{
+		{
+			name:  "empty",
+			query: "",
+		},
+		{
+			name:      "limit",
+			query:     "limit=10",
+			wantLimit: 10,
+		},
+		{
+			name:    "zero limit",
+			query:   "limit=0",
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			query:   "limit=-1",
+			wantErr: true,
+		},
+		{
+			name:    "limit not a number",
+			query:   "limit=all",
+			wantErr: true,
+		},
+		{
+			name:      "continue token",
+			query:     "continue=" + base64.RawURLEncoding.EncodeToString([]byte("a/x")),
+			wantAfter: "a/x",
+		},
+		{
+			name:    "continue token not base64",
+			query:   "continue=a/x",
+			wantErr: true,
+		},
+		{
+			name:    "continue token with padding",
+			query:   "continue=" + url.QueryEscape(base64.URLEncoding.EncodeToString([]byte("a/xy"))),
+			wantErr: true,
+		},
+		{
+			name:    "continue token of another alphabet",
+			query:   "continue=" + url.QueryEscape(base64.RawStdEncoding.EncodeToString([]byte("a/\xfb\xff"))),
+			wantErr: true,
+		},
+		{
+			name:       "fields",
+			query:      "fields=metadata.name,+status.phase+,,spec",
+			wantFields: [][]string{{"metadata", "name"}, {"status", "phase"}, {"spec"}},
+		},
+		{
+			name:    "invalid label selector",
+			query:   "labelSelector=app%3D%3D%3Dweb",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("invalid query %q: %v", tt.query, err)
+			}
+
+			q, err := parseAPIQuery(values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if q.limit != tt.wantLimit {
+				t.Errorf("got limit %d, want %d", q.limit, tt.wantLimit)
+			}
+			if q.after != tt.wantAfter {
+				t.Errorf("got after %q, want %q", q.after, tt.wantAfter)
+			}
+			if !slices.EqualFunc(q.fields, tt.wantFields, slices.Equal) {
+				t.Errorf("got fields %q, want %q", q.fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func apiTestPods() []any {
+	pod := func(ns string, name string, app string) any {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: map[string]string{"app": app}}}
+	}
+	// Not in the order of the pages
+	return []any{
+		pod("b", "x", "web"),
+		pod("a", "y", "db"),
+		pod("a", "x", "web"),
+		pod("c", "z", "web"),
+		pod("b", "a", "db"),
+	}
+}
+
+func pageIDs(page []any) []string {
+	ids := make([]string, 0, len(page))
+	for _, obj := range page {
+		pd := obj.(*corev1.Pod)
+		ids = append(ids, pd.Namespace+"/"+pd.Name)
+	}
+	return ids
+}
+
+func TestAPIQueryPage(t *testing.T) {
+	token := func(id string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(id))
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		want          []string
+		wantNext      string
+		wantRemaining int
+	}{
+		{
+			name:  "all sorted by namespace and name",
+			query: "",
+			want:  []string{"a/x", "a/y", "b/a", "b/x", "c/z"},
+		},
+		{
+			name:  "label selector",
+			query: "labelSelector=app%3Dweb",
+			want:  []string{"a/x", "b/x", "c/z"},
+		},
+		{
+			name:          "first page",
+			query:         "limit=2",
+			want:          []string{"a/x", "a/y"},
+			wantNext:      token("a/y"),
+			wantRemaining: 3,
+		},
+		{
+			name:          "next page",
+			query:         "limit=2&continue=" + token("a/y"),
+			want:          []string{"b/a", "b/x"},
+			wantNext:      token("b/x"),
+			wantRemaining: 1,
+		},
+		{
+			name:  "last page has no next token",
+			query: "limit=2&continue=" + token("b/x"),
+			want:  []string{"c/z"},
+		},
+		{
+			name:  "limit of exactly the list",
+			query: "limit=5",
+			want:  []string{"a/x", "a/y", "b/a", "b/x", "c/z"},
+		},
+		{
+			name:  "limit larger than the list",
+			query: "limit=100",
+			want:  []string{"a/x", "a/y", "b/a", "b/x", "c/z"},
+		},
+		{
+			name:          "page of the selected objects",
+			query:         "labelSelector=app%3Dweb&limit=1&continue=" + token("a/x"),
+			want:          []string{"b/x"},
+			wantNext:      token("b/x"),
+			wantRemaining: 1,
+		},
+		{
+			// Tokens are not signed, they only skip what sorts before them
+			name:  "tampered token of no object",
+			query: "limit=10&continue=" + token("a/zzz"),
+			want:  []string{"b/a", "b/x", "c/z"},
+		},
+		{
+			name:  "token after the last object",
+			query: "continue=" + token("zzz"),
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := parseTestAPIQuery(t, tt.query)
+
+			page, next, remaining, err := q.page(apiTestPods())
+			if err != nil {
+				t.Fatalf("unable to page: %v", err)
+			}
+			if ids := pageIDs(page); !slices.Equal(ids, tt.want) {
+				t.Errorf("got page %v, want %v", ids, tt.want)
+			}
+			if next != tt.wantNext {
+				t.Errorf("got next %q, want %q", next, tt.wantNext)
+			}
+			if remaining != tt.wantRemaining {
+				t.Errorf("got %d remaining, want %d", remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestAPIQueryPages(t *testing.T) {
+	// Following the tokens returns every object once
+	var ids []string
+	query := "limit=2"
+	for range 10 {
+		page, next, _, err := parseTestAPIQuery(t, query).page(apiTestPods())
+		if err != nil {
+			t.Fatalf("unable to page: %v", err)
+		}
+		ids = append(ids, pageIDs(page)...)
+		if next == "" {
+			break
+		}
+		query = "limit=2&continue=" + next
+	}
+
+	if want := []string{"a/x", "a/y", "b/a", "b/x", "c/z"}; !slices.Equal(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+}
+
+func TestAPIQueryProject(t *testing.T) {
+	pd := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "x", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{
+			NodeName: "n1",
+			Containers: []corev1.Container{
+				{Name: "app", Image: "web:1"},
+				{Name: "proxy", Image: "envoy:1"},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "nested field",
+			query: "fields=metadata.name",
+			want:  `{"metadata":{"name":"x"}}`,
+		},
+		{
+			name:  "fields of the same parent",
+			query: "fields=metadata.name,metadata.namespace,status.phase",
+			want:  `{"metadata":{"name":"x","namespace":"a"},"status":{"phase":"Running"}}`,
+		},
+		{
+			name:  "whole subtree",
+			query: "fields=metadata.labels",
+			want:  `{"metadata":{"labels":{"app":"web"}}}`,
+		},
+		{
+			name:  "field of list elements",
+			query: "fields=spec.containers.image",
+			want:  `{"spec":{"containers":[{"image":"web:1"},{"image":"envoy:1"}]}}`,
+		},
+		{
+			name:  "fields of list elements",
+			query: "fields=spec.containers.name,spec.containers.image",
+			want:  `{"spec":{"containers":[{"image":"web:1","name":"app"},{"image":"envoy:1","name":"proxy"}]}}`,
+		},
+		{
+			name:  "missing field",
+			query: "fields=spec.hostname",
+			want:  `{}`,
+		},
+		{
+			name:  "missing parent",
+			query: "fields=metadata.annotations.team,metadata.name",
+			want:  `{"metadata":{"name":"x"}}`,
+		},
+		{
+			name:  "path below a scalar",
+			query: "fields=spec.nodeName.first",
+			want:  `{}`,
+		},
+		{
+			name:  "missing field of list elements",
+			query: "fields=spec.containers.command,status.phase",
+			want:  `{"status":{"phase":"Running"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := parseTestAPIQuery(t, tt.query).project(pd)
+			if err != nil {
+				t.Fatalf("unable to project: %v", err)
+			}
+			got, err := json.Marshal(obj)
+			if err != nil {
+				t.Fatalf("unable to marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Without fields the object is returned as it is
+	if obj, err := parseTestAPIQuery(t, "").project(pd); err != nil || obj != any(pd) {
+		t.Errorf("got %v (error %v), want the object", obj, err)
+	}
+}
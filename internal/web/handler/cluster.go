@@ -25,9 +25,10 @@ func Cluster(
 				return
 			}
 
+			groups := core.GetProblems(store)
 			nss := core.GetNamespaces(store)
 
-			err = cluster.View(&startTime, cfg, rm, nss).Render(r.Context(), w)
+			err = render(r.Context(), w, "cluster", cluster.View(&startTime, cfg, rm, groups, nss))
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				return
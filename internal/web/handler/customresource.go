@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"polar-bear/internal/config"
+	"polar-bear/internal/core"
+	"polar-bear/internal/runtimemeta"
+	"polar-bear/internal/store"
+	"polar-bear/internal/web/view/customresource"
+)
+
+func CustomResourceDefinitions(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			crts := core.GetCustomResourceTypes(store)
+			nss := core.GetNamespaces(store)
+
+			err = render(
+				r.Context(), w, "customresource-definitions",
+				customresource.DefinitionsView(&startTime, cfg, rm, crts, nss),
+			)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func ClusterCustomResources(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			res, err := url.QueryUnescape(r.PathValue("res"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+
+			crt, ok := core.GetCustomResourceType(store, res)
+			if !ok || crt.Namespaced {
+				http.NotFound(w, r)
+				return
+			}
+
+			ctx, opts, err := listContext(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			crs := core.FilterObjects(core.GetCustomResources(store, crt, ""), opts)
+			nss := core.GetNamespaces(store)
+
+			err = render(
+				ctx, w, "customresource-list",
+				customresource.ListView(&startTime, cfg, rm, "", crt, crs, nss),
+			)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func ClusterCustomResource(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			res, err := url.QueryUnescape(r.PathValue("res"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+
+			name, err := url.QueryUnescape(r.PathValue("name"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+
+			crt, ok := core.GetCustomResourceType(store, res)
+			if !ok || crt.Namespaced {
+				http.NotFound(w, r)
+				return
+			}
+
+			cr := core.GetCustomResource(store, crt, "", name)
+			if serveManifest(w, r, cr) {
+				return
+			}
+
+			nss := core.GetNamespaces(store)
+
+			err = render(
+				r.Context(), w, "customresource-detail",
+				customresource.DetailView(&startTime, cfg, rm, "", name, crt, cr, nss, detailManifest(r, cr)),
+			)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
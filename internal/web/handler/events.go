@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"polar-bear/internal/config"
+	"polar-bear/internal/core"
+	"polar-bear/internal/runtimemeta"
+	"polar-bear/internal/store"
+	"polar-bear/internal/web/view/events"
+)
+
+const (
+	// Number of events shown on the namespace page.
+	recentEventsLimit = 20
+
+	// Number of events shown on the cluster-wide warnings page.
+	warningEventsLimit = 200
+)
+
+func Events(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			evs := core.GetWarningEvents(store, warningEventsLimit)
+			nss := core.GetNamespaces(store)
+
+			err = render(r.Context(), w, "warnings", events.WarningsView(&startTime, cfg, rm, evs, nss))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
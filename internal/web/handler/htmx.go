@@ -17,7 +17,7 @@ func HTMXOpenSidebar(
 		func(w http.ResponseWriter, r *http.Request) {
 			nss := core.GetNamespaces(store)
 
-			err := shared.SidebarState("open", rm, nss, "", "").Render(r.Context(), w)
+			err := render(r.Context(), w, "sidebar", shared.SidebarState("open", rm, nss, "", ""))
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				return
@@ -34,7 +34,7 @@ func HTMXCloseSidebar(
 		func(w http.ResponseWriter, r *http.Request) {
 			nss := core.GetNamespaces(store)
 
-			err := shared.SidebarState("closed", rm, nss, "", "").Render(r.Context(), w)
+			err := render(r.Context(), w, "sidebar", shared.SidebarState("closed", rm, nss, "", ""))
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				return
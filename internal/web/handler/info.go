@@ -28,7 +28,7 @@ func Info(
 			nss := core.GetNamespaces(store)
 			stats := store.Stats()
 
-			err = info.View(&startTime, cfg, rm, nss, stats).Render(r.Context(), w)
+			err = render(r.Context(), w, "info", info.View(&startTime, cfg, rm, nss, stats))
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				return
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/web/view/shared"
+)
+
+// listContext returns the filters of a list page and the context its view is
+// rendered with, which links live updates to the filtered list.
+func listContext(r *http.Request) (context.Context, core.ListOptions, error) {
+	opts, err := core.ParseListOptions(r.URL.Query())
+	if err != nil {
+		return nil, opts, err
+	}
+	return shared.WithListOptions(r.Context(), opts), opts, nil
+}
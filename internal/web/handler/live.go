@@ -0,0 +1,919 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/event"
+	"polar-bear/internal/store"
+	"polar-bear/internal/web/view/cluster"
+	"polar-bear/internal/web/view/clusterrole"
+	"polar-bear/internal/web/view/clusterrolebinding"
+	"polar-bear/internal/web/view/configmap"
+	"polar-bear/internal/web/view/cronjob"
+	"polar-bear/internal/web/view/csidriver"
+	"polar-bear/internal/web/view/customresource"
+	"polar-bear/internal/web/view/daemonset"
+	"polar-bear/internal/web/view/deployment"
+	"polar-bear/internal/web/view/endpointslice"
+	"polar-bear/internal/web/view/events"
+	"polar-bear/internal/web/view/ingress"
+	"polar-bear/internal/web/view/job"
+	"polar-bear/internal/web/view/namespace"
+	"polar-bear/internal/web/view/networkpolicy"
+	"polar-bear/internal/web/view/node"
+	"polar-bear/internal/web/view/persistentvolume"
+	"polar-bear/internal/web/view/persistentvolumeclaim"
+	"polar-bear/internal/web/view/pod"
+	"polar-bear/internal/web/view/replicaset"
+	"polar-bear/internal/web/view/role"
+	"polar-bear/internal/web/view/rolebinding"
+	"polar-bear/internal/web/view/secret"
+	"polar-bear/internal/web/view/service"
+	"polar-bear/internal/web/view/serviceaccount"
+	"polar-bear/internal/web/view/statefulset"
+	"polar-bear/internal/web/view/storageclass"
+)
+
+const (
+	// Swap method of fragments pushed over the websocket.
+	liveSwap = "outerHTML"
+
+	// Time to collect changes before rendering them in one update.
+	liveDebounce = 100 * time.Millisecond
+)
+
+// subscription describes the page a websocket client shows. Kind is a store
+// kind or the name of a custom resource type, an empty name means its list
+// filtered and sorted by List.
+type subscription struct {
+	Kind      string
+	Namespace string
+	Name      string
+	List      core.ListOptions
+}
+
+func parseSubscription(r *http.Request) (subscription, error) {
+	q := r.URL.Query()
+	sub := subscription{
+		Kind:      q.Get("kind"),
+		Namespace: q.Get("ns"),
+		Name:      q.Get("name"),
+	}
+	if sub.Kind == "" {
+		return sub, fmt.Errorf("missing kind")
+	}
+	list, err := core.ParseListOptions(q)
+	if err != nil {
+		return sub, err
+	}
+	sub.List = list
+	return sub, nil
+}
+
+// liveViewName returns the metrics label of the view of a subscription. Custom
+// resources share one label, their kinds are unbounded.
+func liveViewName(sub subscription) string {
+	kind := sub.Kind
+	_, isList := liveLists[kind]
+	_, isDetail := liveDetails[kind]
+	if !isList && !isDetail {
+		kind = "customresource"
+	}
+	if sub.Name != "" {
+		return kind + "-detail"
+	}
+	return kind + "-list"
+}
+
+// liveView re-renders the part of a page that changes with the resources it shows.
+type liveView struct {
+	// relevant reports whether a changed store key affects the page
+	relevant func(sub subscription, key string) bool
+	// related returns the keys of other resources a detail page shows, found
+	// through the store indexes. It is optional, a key is relevant as well if
+	// it was related when the page was last rendered or is related now.
+	related func(store store.Store, sub subscription) []string
+	// render returns the fragment that replaces the page content
+	render func(store store.Store, sub subscription) templ.Component
+	// rows is set for lists that can update single rows instead of re-rendering
+	rows *liveRows
+}
+
+// liveRows renders single rows of a list, identified by their store keys.
+type liveRows struct {
+	// list renders the whole list and returns the keys of the rows it shows
+	list func(store store.Store, sub subscription) (templ.Component, []string)
+	// row renders the changed row of a key as an out-of-band swap, ok is
+	// false if the resource doesn't exist
+	row func(store store.Store, sub subscription, key string) (c templ.Component, ok bool)
+	// remove renders the removal of the row of a deleted resource
+	remove func(key string) templ.Component
+}
+
+// clusterViews are the views subscribed to without a namespace.
+var clusterViews = map[string]bool{
+	"cluster":            true,
+	"node":               true,
+	"namespace":          true,
+	"event":              true,
+	"persistentvolume":   true,
+	"storageclass":       true,
+	"csidriver":          true,
+	"clusterrole":        true,
+	"clusterrolebinding": true,
+}
+
+var liveLists = map[string]liveView{
+	"pod": {
+		relevant: relevantList("pod"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			c, _ := podListRows(store, sub)
+			return c
+		},
+		rows: &liveRows{
+			list: podListRows,
+			row: func(store store.Store, sub subscription, key string) (templ.Component, bool) {
+				pd := core.GetPod(store, keyNamespace(key), path.Base(key))
+				if pd == nil || !sub.List.Matches(pd) {
+					return nil, false
+				}
+				return pod.PodItem(pd, sub.Namespace == core.AllNamespaces, liveSwap), true
+			},
+			remove: func(key string) templ.Component {
+				return pod.RemovedPodItem(keyNamespace(key), path.Base(key))
+			},
+		},
+	},
+	"deployment": {
+		relevant: relevantList("deployment"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return deployment.DeploymentList(sub.Namespace, core.FilterObjects(core.GetDeployments(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"replicaset": {
+		relevant: relevantList("replicaset"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return replicaset.ReplicaSetList(sub.Namespace, core.FilterObjects(core.GetReplicaSets(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"statefulset": {
+		relevant: relevantList("statefulset"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return statefulset.StatefulSetList(sub.Namespace, core.FilterObjects(core.GetStatefulSets(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"daemonset": {
+		relevant: relevantList("daemonset"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return daemonset.DaemonSetList(sub.Namespace, core.FilterObjects(core.GetDaemonSets(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"job": {
+		relevant: relevantList("job"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return job.JobList(sub.Namespace, core.FilterObjects(core.GetJobs(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"cronjob": {
+		relevant: relevantList("cronjob"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return cronjob.CronJobList(sub.Namespace, core.FilterObjects(core.GetCronJobs(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"service": {
+		relevant: relevantList("service"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return service.ServiceList(sub.Namespace, core.FilterObjects(core.GetServices(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"endpointslice": {
+		relevant: relevantList("endpointslice"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return endpointslice.EndpointSliceList(sub.Namespace, core.FilterObjects(core.GetEndpointSlices(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"ingress": {
+		relevant: relevantList("ingress"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return ingress.IngressList(sub.Namespace, core.FilterObjects(core.GetIngresses(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"networkpolicy": {
+		relevant: relevantList("networkpolicy"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return networkpolicy.NetworkPolicyList(sub.Namespace, core.FilterObjects(core.GetNetworkPolicies(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"persistentvolumeclaim": {
+		relevant: relevantList("persistentvolumeclaim"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return persistentvolumeclaim.PersistentVolumeClaimList(sub.Namespace, core.FilterObjects(core.GetPersistentVolumeClaims(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"configmap": {
+		relevant: relevantList("configmap"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return configmap.ConfigMapList(sub.Namespace, core.FilterObjects(core.GetConfigMaps(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"secret": {
+		relevant: relevantList("secret"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return secret.SecretList(sub.Namespace, core.FilterObjects(core.GetSecrets(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"serviceaccount": {
+		relevant: relevantList("serviceaccount"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return serviceaccount.ServiceAccountList(sub.Namespace, core.FilterObjects(core.GetServiceAccounts(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"role": {
+		relevant: relevantList("role"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return role.RoleList(sub.Namespace, core.FilterObjects(core.GetRoles(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"rolebinding": {
+		relevant: relevantList("rolebinding"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			return rolebinding.RoleBindingList(sub.Namespace, core.FilterObjects(core.GetRoleBindings(store, sub.Namespace), sub.List), liveSwap)
+		},
+	},
+	"persistentvolume": {
+		relevant: func(_ subscription, key string) bool {
+			return strings.HasPrefix(key, keyPrefix("persistentvolume", ""))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			return persistentvolume.PersistentVolumeList(core.FilterObjects(core.GetPersistentVolumes(store), sub.List), liveSwap)
+		},
+	},
+	"storageclass": {
+		relevant: func(_ subscription, key string) bool {
+			return strings.HasPrefix(key, keyPrefix("storageclass", ""))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			return storageclass.StorageClassList(core.FilterObjects(core.GetStorageClasses(store), sub.List), liveSwap)
+		},
+	},
+	"csidriver": {
+		relevant: func(_ subscription, key string) bool {
+			return strings.HasPrefix(key, keyPrefix("csidriver", ""))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			return csidriver.CSIDriverList(core.FilterObjects(core.GetCSIDrivers(store), sub.List), liveSwap)
+		},
+	},
+	"clusterrole": {
+		relevant: func(_ subscription, key string) bool {
+			return strings.HasPrefix(key, keyPrefix("clusterrole", ""))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			return clusterrole.ClusterRoleList(core.FilterObjects(core.GetClusterRoles(store), sub.List), liveSwap)
+		},
+	},
+	"clusterrolebinding": {
+		relevant: func(_ subscription, key string) bool {
+			return strings.HasPrefix(key, keyPrefix("clusterrolebinding", ""))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			return clusterrolebinding.ClusterRoleBindingList(core.FilterObjects(core.GetClusterRoleBindings(store), sub.List), liveSwap)
+		},
+	},
+	"node": {
+		relevant: func(_ subscription, key string) bool {
+			return strings.HasPrefix(key, keyPrefix("node", ""))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			return node.NodeList(core.FilterObjects(core.GetNodes(store), sub.List), liveSwap)
+		},
+	},
+	"cluster": {
+		relevant: func(_ subscription, key string) bool {
+			return isKeyOfKind(key, "pod") ||
+				isKeyOfKind(key, "deployment") ||
+				isKeyOfKind(key, "statefulset") ||
+				isKeyOfKind(key, "job") ||
+				strings.HasPrefix(key, keyPrefix("node", ""))
+		},
+		render: func(store store.Store, _ subscription) templ.Component {
+			return cluster.Problems(core.GetProblems(store), liveSwap)
+		},
+	},
+	"event": {
+		relevant: func(_ subscription, key string) bool {
+			return isKeyOfKind(key, "event")
+		},
+		render: func(store store.Store, _ subscription) templ.Component {
+			return events.Warnings(core.GetWarningEvents(store, warningEventsLimit), liveSwap)
+		},
+	},
+}
+
+func podListRows(store store.Store, sub subscription) (templ.Component, []string) {
+	pds := core.FilterObjects(core.GetPods(store, sub.Namespace), sub.List)
+	keys := make([]string, 0, len(pds))
+	for _, pd := range pds {
+		keys = append(keys, resourceKey("pod", pd.Namespace, pd.Name))
+	}
+	return pod.PodList(sub.Namespace, pds, liveSwap), keys
+}
+
+var liveDetails = map[string]liveView{
+	"pod": {
+		relevant: func(sub subscription, key string) bool {
+			return key == resourceKey("pod", sub.Namespace, sub.Name) ||
+				key == resourceKey("podusage", sub.Namespace, sub.Name)
+		},
+		related: func(store store.Store, sub subscription) []string {
+			keys := core.GetEventKeysRegarding(store, "Pod", sub.Namespace, sub.Name)
+			if pd := core.GetPod(store, sub.Namespace, sub.Name); pd != nil {
+				// Claims that don't exist yet are shown as missing
+				for _, v := range pd.Spec.Volumes {
+					if claim := core.ClaimName(pd, v); claim != "" {
+						keys = append(keys, resourceKey("persistentvolumeclaim", sub.Namespace, claim))
+					}
+				}
+			}
+			return keys
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			pd := core.GetPod(store, sub.Namespace, sub.Name)
+			u := core.GetPodUsage(store, sub.Namespace, sub.Name)
+			claims := podClaims(store, pd)
+			evs := core.GetEventsRegarding(store, "Pod", sub.Namespace, sub.Name)
+			return pod.Detail(sub.Namespace, sub.Name, pd, u, claims, evs, liveSwap)
+		},
+	},
+	"deployment": {
+		relevant: relevantKinds("deployment", "replicaset", "pod", "event"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			deploy := core.GetDeployment(store, sub.Namespace, sub.Name)
+			rss := ownedBy(store, deploy, core.GetReplicaSetsOwnedBy)
+			pds := deploymentPods(store, deploy)
+			evs := core.GetEventsRegarding(store, "Deployment", sub.Namespace, sub.Name)
+			return deployment.Detail(sub.Namespace, sub.Name, deploy, rss, pds, evs, liveSwap)
+		},
+	},
+	"replicaset": {
+		relevant: relevantKinds("replicaset", "pod", "event"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			rs := core.GetReplicaSet(store, sub.Namespace, sub.Name)
+			pds := ownedBy(store, rs, core.GetPodsOwnedBy)
+			evs := core.GetEventsRegarding(store, "ReplicaSet", sub.Namespace, sub.Name)
+			return replicaset.Detail(sub.Namespace, sub.Name, rs, pds, evs, liveSwap)
+		},
+	},
+	"statefulset": {
+		relevant: relevantKinds("statefulset", "controllerrevision", "pod", "event"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			st := core.GetStatefulSet(store, sub.Namespace, sub.Name)
+			crs := ownedBy(store, st, core.GetControllerRevisionsOwnedBy)
+			pds := ownedBy(store, st, core.GetPodsOwnedBy)
+			evs := core.GetEventsRegarding(store, "StatefulSet", sub.Namespace, sub.Name)
+			return statefulset.Detail(sub.Namespace, sub.Name, st, crs, pds, evs, liveSwap)
+		},
+	},
+	"daemonset": {
+		relevant: relevantKinds("daemonset", "controllerrevision", "pod", "event"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			ds := core.GetDaemonSet(store, sub.Namespace, sub.Name)
+			crs := ownedBy(store, ds, core.GetControllerRevisionsOwnedBy)
+			pds := ownedBy(store, ds, core.GetPodsOwnedBy)
+			evs := core.GetEventsRegarding(store, "DaemonSet", sub.Namespace, sub.Name)
+			return daemonset.Detail(sub.Namespace, sub.Name, ds, crs, pds, evs, liveSwap)
+		},
+	},
+	"job": {
+		relevant: relevantKinds("job", "pod", "event"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			jb := core.GetJob(store, sub.Namespace, sub.Name)
+			pds := ownedBy(store, jb, core.GetPodsOwnedBy)
+			evs := core.GetEventsRegarding(store, "Job", sub.Namespace, sub.Name)
+			return job.Detail(sub.Namespace, sub.Name, jb, pds, evs, liveSwap)
+		},
+	},
+	"cronjob": {
+		relevant: relevantKinds("cronjob", "job", "pod", "event"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			cj := core.GetCronJob(store, sub.Namespace, sub.Name)
+			jobs := ownedBy(store, cj, core.GetJobsOwnedBy)
+			pds := cronJobPods(store, cj)
+			evs := core.GetEventsRegarding(store, "CronJob", sub.Namespace, sub.Name)
+			return cronjob.Detail(sub.Namespace, sub.Name, cj, jobs, pds, evs, liveSwap)
+		},
+	},
+	"service": {
+		relevant: relevantKinds("service", "endpointslice", "pod", "event"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			svc := core.GetService(store, sub.Namespace, sub.Name)
+			slices := core.GetEndpointSlicesOfService(store, sub.Namespace, sub.Name)
+			pds := servicePods(store, svc)
+			evs := core.GetEventsRegarding(store, "Service", sub.Namespace, sub.Name)
+			return service.Detail(sub.Namespace, sub.Name, svc, slices, pds, evs, liveSwap)
+		},
+	},
+	"endpointslice": {
+		relevant: func(sub subscription, key string) bool {
+			return key == resourceKey("endpointslice", sub.Namespace, sub.Name)
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			slice := core.GetEndpointSlice(store, sub.Namespace, sub.Name)
+			return endpointslice.Detail(sub.Namespace, sub.Name, slice, liveSwap)
+		},
+	},
+	"ingress": {
+		relevant: relevantKinds("ingress", "event"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			ing := core.GetIngress(store, sub.Namespace, sub.Name)
+			evs := core.GetEventsRegarding(store, "Ingress", sub.Namespace, sub.Name)
+			return ingress.Detail(sub.Namespace, sub.Name, ing, evs, liveSwap)
+		},
+	},
+	"networkpolicy": {
+		relevant: relevantKinds("networkpolicy", "pod"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			np := core.GetNetworkPolicy(store, sub.Namespace, sub.Name)
+			pds := networkPolicyPods(store, np)
+			return networkpolicy.Detail(sub.Namespace, sub.Name, np, pds, liveSwap)
+		},
+	},
+	"persistentvolumeclaim": {
+		relevant: relevantKinds("persistentvolumeclaim", "pod", "event"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			pvc := core.GetPersistentVolumeClaim(store, sub.Namespace, sub.Name)
+			pds := core.GetPodsUsingClaim(store, sub.Namespace, sub.Name)
+			evs := core.GetEventsRegarding(store, "PersistentVolumeClaim", sub.Namespace, sub.Name)
+			return persistentvolumeclaim.Detail(sub.Namespace, sub.Name, pvc, pds, evs, liveSwap)
+		},
+	},
+	"configmap": {
+		relevant: relevantKinds("configmap", "pod", "deployment", "replicaset", "statefulset", "daemonset", "job", "cronjob"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			cm := core.GetConfigMap(store, sub.Namespace, sub.Name)
+			wls := core.GetWorkloadsUsingConfigMap(store, sub.Namespace, sub.Name)
+			pds := core.GetPodsUsingConfigMap(store, sub.Namespace, sub.Name)
+			return configmap.Detail(sub.Namespace, sub.Name, cm, wls, pds, liveSwap)
+		},
+	},
+	"secret": {
+		relevant: relevantKinds("secret", "pod"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			s := core.GetSecret(store, sub.Namespace, sub.Name)
+			pds := core.GetPodsUsingSecret(store, sub.Namespace, sub.Name)
+			return secret.Detail(sub.Namespace, sub.Name, s, pds, liveSwap)
+		},
+	},
+	"serviceaccount": {
+		relevant: func(sub subscription, key string) bool {
+			return relevantKinds("serviceaccount", "pod", "role", "rolebinding")(sub, key) ||
+				isClusterRBACKey(key)
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			sa := core.GetServiceAccount(store, sub.Namespace, sub.Name)
+			grants := serviceAccountGrants(store, sa)
+			pds := core.GetPodsOfServiceAccount(store, sub.Namespace, sub.Name)
+			return serviceaccount.Detail(sub.Namespace, sub.Name, sa, grants, pds, liveSwap)
+		},
+	},
+	"role": {
+		relevant: relevantKinds("role", "rolebinding"),
+		render: func(store store.Store, sub subscription) templ.Component {
+			rl := core.GetRole(store, sub.Namespace, sub.Name)
+			rbs := core.GetRoleBindingsOfRole(store, sub.Namespace, "Role", sub.Name)
+			return role.Detail(sub.Namespace, sub.Name, rl, rbs, liveSwap)
+		},
+	},
+	"rolebinding": {
+		relevant: func(sub subscription, key string) bool {
+			return relevantKinds("rolebinding", "role")(sub, key) ||
+				strings.HasPrefix(key, keyPrefix("clusterrole", ""))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			rb := core.GetRoleBinding(store, sub.Namespace, sub.Name)
+			rules := roleBindingRules(store, rb)
+			return rolebinding.Detail(sub.Namespace, sub.Name, rb, rules, liveSwap)
+		},
+	},
+	"persistentvolume": {
+		relevant: func(sub subscription, key string) bool {
+			return key == resourceKey("persistentvolume", "", sub.Name) ||
+				strings.HasPrefix(key, keyPrefix("volumeattachment", "")) ||
+				strings.HasPrefix(key, keyPrefix("event", "default"))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			pv := core.GetPersistentVolume(store, sub.Name)
+			vas := core.GetVolumeAttachmentsOfVolume(store, sub.Name)
+			evs := core.GetEventsRegarding(store, "PersistentVolume", "", sub.Name)
+			return persistentvolume.Detail(sub.Name, pv, vas, evs, liveSwap)
+		},
+	},
+	"storageclass": {
+		relevant: func(sub subscription, key string) bool {
+			return key == resourceKey("storageclass", "", sub.Name) ||
+				strings.HasPrefix(key, keyPrefix("persistentvolume", "")) ||
+				strings.HasPrefix(key, keyPrefix("csidriver", "")) ||
+				isKeyOfKind(key, "csistoragecapacity")
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			sc := core.GetStorageClass(store, sub.Name)
+			driver := storageClassDriver(store, sc)
+			pvs := core.GetPersistentVolumesOfClass(store, sub.Name)
+			cscs := core.GetCSIStorageCapacitiesOfClass(store, sub.Name)
+			return storageclass.Detail(sub.Name, sc, driver, pvs, cscs, liveSwap)
+		},
+	},
+	"csidriver": {
+		relevant: func(sub subscription, key string) bool {
+			return key == resourceKey("csidriver", "", sub.Name) ||
+				strings.HasPrefix(key, keyPrefix("csinode", "")) ||
+				strings.HasPrefix(key, keyPrefix("storageclass", "")) ||
+				strings.HasPrefix(key, keyPrefix("volumeattachment", ""))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			driver := core.GetCSIDriver(store, sub.Name)
+			cns := core.GetCSINodesWithDriver(store, sub.Name)
+			scs := core.GetStorageClassesOfProvisioner(store, sub.Name)
+			vas := core.GetVolumeAttachmentsOfAttacher(store, sub.Name)
+			return csidriver.Detail(sub.Name, driver, cns, scs, vas, liveSwap)
+		},
+	},
+	"clusterrole": {
+		relevant: func(_ subscription, key string) bool {
+			return isClusterRBACKey(key) || isKeyOfKind(key, "rolebinding")
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			cr := core.GetClusterRole(store, sub.Name)
+			rules, aggregated := clusterRoleRules(store, cr)
+			crbs := core.GetClusterRoleBindingsOfRole(store, sub.Name)
+			rbs := core.GetRoleBindingsOfRole(store, "", "ClusterRole", sub.Name)
+			return clusterrole.Detail(sub.Name, cr, rules, aggregated, crbs, rbs, liveSwap)
+		},
+	},
+	"clusterrolebinding": {
+		relevant: func(sub subscription, key string) bool {
+			return key == resourceKey("clusterrolebinding", "", sub.Name) ||
+				strings.HasPrefix(key, keyPrefix("clusterrole", ""))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			crb := core.GetClusterRoleBinding(store, sub.Name)
+			rules := clusterRoleBindingRules(store, crb)
+			return clusterrolebinding.Detail(sub.Name, crb, rules, liveSwap)
+		},
+	},
+	"node": {
+		relevant: func(sub subscription, key string) bool {
+			return key == resourceKey("node", "", sub.Name) ||
+				key == resourceKey("nodeusage", "", sub.Name)
+		},
+		related: func(store store.Store, sub subscription) []string {
+			return slices.Concat(
+				core.GetPodKeysOnNode(store, sub.Name),
+				core.GetEventKeysRegarding(store, "Node", "", sub.Name),
+			)
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			no := core.GetNode(store, sub.Name)
+			c := nodeCapacity(store, no)
+			u := core.GetNodeUsage(store, sub.Name)
+			pds := core.GetPodsOnNode(store, sub.Name)
+			evs := core.GetEventsRegarding(store, "Node", "", sub.Name)
+			return node.Detail(sub.Name, no, c, u, pds, evs, liveSwap)
+		},
+	},
+	"namespace": {
+		relevant: func(sub subscription, key string) bool {
+			if isKeyOfKind(key, "podusage") {
+				// Usage is polled, it isn't shown on namespace pages
+				return false
+			}
+			if sub.Name == core.AllNamespaces {
+				return strings.HasPrefix(key, keyPrefix("namespace", "")) || strings.HasPrefix(key, "ns/")
+			}
+			return key == resourceKey("namespace", "", sub.Name) ||
+				strings.HasPrefix(key, "ns/"+sub.Name+"/")
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			return namespace.Detail(namespaceData(store, sub.Name), liveSwap)
+		},
+	},
+}
+
+// relevantList returns a liveView.relevant for lists of a namespaced kind in
+// the namespace of the subscription or in all namespaces.
+func relevantList(kind string) func(sub subscription, key string) bool {
+	return func(sub subscription, key string) bool {
+		if sub.Namespace == core.AllNamespaces {
+			return isKeyOfKind(key, kind)
+		}
+		return strings.HasPrefix(key, keyPrefix(kind, sub.Namespace))
+	}
+}
+
+// relevantKinds returns a liveView.relevant for detail pages that show
+// resources of the given kinds from the namespace of the subscription.
+func relevantKinds(kinds ...string) func(sub subscription, key string) bool {
+	return func(sub subscription, key string) bool {
+		for _, kind := range kinds {
+			if strings.HasPrefix(key, keyPrefix(kind, sub.Namespace)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// liveTopics returns the key prefixes a subscription needs updates for. Views
+// of a namespace only depend on its resources, all others listen to everything
+// and filter with liveView.relevant.
+func liveTopics(sub subscription) []string {
+	if sub.Namespace == core.AllNamespaces || (sub.Kind == "namespace" && sub.Name == core.AllNamespaces) {
+		return nil
+	}
+	if sub.Kind == "namespace" && sub.Name != "" {
+		return []string{resourceKey("namespace", "", sub.Name), "ns/" + sub.Name + "/"}
+	}
+	if sub.Namespace != "" {
+		topics := []string{"ns/" + sub.Namespace + "/"}
+		if sub.Name != "" && (sub.Kind == "serviceaccount" || sub.Kind == "rolebinding") {
+			// Permissions also come from cluster roles and their bindings
+			topics = append(topics, keyPrefix("clusterrole", ""), keyPrefix("clusterrolebinding", ""))
+		}
+		return topics
+	}
+	return nil
+}
+
+// isClusterRBACKey reports whether key belongs to a cluster role or cluster
+// role binding.
+func isClusterRBACKey(key string) bool {
+	return strings.HasPrefix(key, keyPrefix("clusterrole", "")) ||
+		strings.HasPrefix(key, keyPrefix("clusterrolebinding", ""))
+}
+
+// findLiveView returns the view a subscription refers to.
+func findLiveView(store store.Store, sub subscription) (liveView, bool) {
+	if sub.Name != "" && sub.Namespace == core.AllNamespaces {
+		// Objects are shown in their own namespace
+		return liveView{}, false
+	}
+	views := liveLists
+	if sub.Name != "" {
+		views = liveDetails
+	}
+	if view, ok := views[sub.Kind]; ok {
+		return view, clusterViews[sub.Kind] == (sub.Namespace == "")
+	}
+
+	crt, ok := core.GetCustomResourceType(store, sub.Kind)
+	if !ok || crt.Namespaced != (sub.Namespace != "") {
+		return liveView{}, false
+	}
+	return customResourceLiveView(crt, sub.Name != ""), true
+}
+
+func customResourceLiveView(crt core.CustomResourceType, detail bool) liveView {
+	if detail {
+		return liveView{
+			relevant: func(sub subscription, key string) bool {
+				dbKey, err := core.CustomResourceKey(crt, sub.Namespace, sub.Name)
+				return err == nil && key == string(dbKey)
+			},
+			render: func(store store.Store, sub subscription) templ.Component {
+				cr := core.GetCustomResource(store, crt, sub.Namespace, sub.Name)
+				return customresource.Detail(sub.Namespace, sub.Name, crt, cr, liveSwap)
+			},
+		}
+	}
+
+	return liveView{
+		relevant: func(sub subscription, key string) bool {
+			ns := sub.Namespace
+			if ns == core.AllNamespaces {
+				ns, key = "_", inAnyNamespace(key)
+			}
+			dbKey, err := core.CustomResourceKey(crt, ns, "")
+			return err == nil && strings.HasPrefix(key, string(dbKey))
+		},
+		render: func(store store.Store, sub subscription) templ.Component {
+			crs := core.FilterObjects(core.GetCustomResources(store, crt, sub.Namespace), sub.List)
+			return customresource.CustomResourceList(sub.Namespace, crt, crs, liveSwap)
+		},
+	}
+}
+
+func resourceKey(kind string, ns string, name string) string {
+	dbKey, _ := core.ResourceKey(kind, ns, name)
+	return string(dbKey)
+}
+
+// keyPrefix returns the common prefix of all keys of a kind in a namespace.
+func keyPrefix(kind string, ns string) string {
+	return resourceKey(kind, ns, "")
+}
+
+// isKeyOfKind reports whether key belongs to a namespaced kind in any namespace.
+func isKeyOfKind(key string, kind string) bool {
+	return strings.HasPrefix(inAnyNamespace(key), keyPrefix(kind, "_"))
+}
+
+// inAnyNamespace replaces the namespace of a namespaced key with "_", so it
+// can be compared with key prefixes of that namespace. Other keys are
+// returned as they are.
+func inAnyNamespace(key string) string {
+	// Keys are ns/{ns}/{kind}/{name}
+	rest, ok := strings.CutPrefix(key, "ns/")
+	if !ok {
+		return key
+	}
+	_, rest, ok = strings.Cut(rest, "/")
+	if !ok {
+		return key
+	}
+	return "ns/_/" + rest
+}
+
+// keyNamespace returns the namespace of a namespaced key.
+func keyNamespace(key string) string {
+	rest, _ := strings.CutPrefix(key, "ns/")
+	ns, _, _ := strings.Cut(rest, "/")
+	return ns
+}
+
+// liveState renders the updates of one connection. For views with rows it
+// remembers the rows on the page, so changed rows can be sent on their own.
+type liveState struct {
+	sub     subscription
+	view    liveView
+	rows    map[string]struct{} // nil until the whole view was rendered once
+	related map[string]struct{} // keys related to the page when it was last rendered
+}
+
+func newLiveState(store store.Store, sub subscription, view liveView) *liveState {
+	ls := &liveState{sub: sub, view: view}
+	ls.related = ls.relatedKeys(store)
+	return ls
+}
+
+// relatedKeys returns the keys the view is related to now, nil if it has no
+// related keys.
+func (ls *liveState) relatedKeys(store store.Store) map[string]struct{} {
+	if ls.view.related == nil {
+		return nil
+	}
+	keys := ls.view.related(store, ls.sub)
+	related := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		related[key] = struct{}{}
+	}
+	return related
+}
+
+// full re-renders the whole view.
+func (ls *liveState) full(store store.Store) templ.Component {
+	ls.related = ls.relatedKeys(store)
+	if ls.view.rows == nil {
+		return ls.view.render(store, ls.sub)
+	}
+
+	c, keys := ls.view.rows.list(store, ls.sub)
+	ls.rows = make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		ls.rows[key] = struct{}{}
+	}
+	return c
+}
+
+// changes renders the updates for a batch of changed keys, nil if nothing
+// on the page changed. New rows re-render the whole list to keep it sorted.
+func (ls *liveState) changes(store store.Store, keys []string) templ.Component {
+	slices.Sort(keys)
+	related := ls.relatedKeys(store)
+	relevant := slices.DeleteFunc(slices.Compact(keys), func(key string) bool {
+		_, was := ls.related[key]
+		_, is := related[key]
+		return !was && !is && !ls.view.relevant(ls.sub, key)
+	})
+	ls.related = related
+	if len(relevant) == 0 {
+		return nil
+	}
+	if ls.view.rows == nil || ls.rows == nil || ls.sub.List.Sort != "" {
+		// Changed rows may move in a sorted list
+		return ls.full(store)
+	}
+
+	var parts []templ.Component
+	for _, key := range relevant {
+		c, ok := ls.view.rows.row(store, ls.sub, key)
+		_, shown := ls.rows[key]
+		switch {
+		case ok && shown:
+			parts = append(parts, c)
+		case ok && !shown:
+			return ls.full(store)
+		case shown:
+			parts = append(parts, ls.view.rows.remove(key))
+			delete(ls.rows, key)
+		}
+	}
+
+	if len(ls.rows) == 0 {
+		// Show the message of an empty list
+		return ls.full(store)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return templ.Join(parts...)
+}
+
+// liveStream renders the updates of a subscription and hands them to a
+// transport, the websocket or the event stream.
+type liveStream struct {
+	logger *slog.Logger
+	// send writes a rendered update to the client
+	send func(msg []byte) error
+	// keepAlive is called every pingPeriod to detect dead connections
+	keepAlive func() error
+}
+
+// run sends updates until ctx is done or the client is gone. Updates are
+// rendered with ctx, so they link to pages like the page they update.
+func (ls liveStream) run(
+	ctx context.Context,
+	updates *event.Subscription,
+	sub subscription,
+	view liveView,
+	store store.Store,
+) {
+	pingTicker := time.NewTicker(pingPeriod)
+	defer pingTicker.Stop()
+
+	var buf bytes.Buffer
+	state := newLiveState(store, sub, view)
+	var (
+		batch  []string
+		lagged bool
+		flush  <-chan time.Time // nil while no batch is pending
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-updates.Ready():
+			keys, dropped := updates.Drain()
+			if dropped {
+				ls.logger.Warn("subscription lagged, updates were dropped", "kind", sub.Kind, "ns", sub.Namespace)
+			}
+			batch = append(batch, keys...)
+			lagged = lagged || dropped
+			if flush == nil {
+				flush = time.After(liveDebounce)
+			}
+		case <-flush:
+			ls.logger.Debug("got updates", "kind", sub.Kind, "keys", len(batch), "lagged", lagged)
+
+			var c templ.Component
+			if lagged {
+				c = state.full(store)
+			} else {
+				c = state.changes(store, batch)
+			}
+			batch, lagged, flush = nil, false, nil
+			if c == nil {
+				continue
+			}
+
+			buf.Reset()
+			err := render(ctx, &buf, "live-"+liveViewName(sub), c)
+			if err != nil {
+				ls.logger.Error("unable to render template", "err", err)
+				return
+			}
+
+			if err := ls.send(buf.Bytes()); err != nil {
+				ls.logger.Error("unable to send update", "err", err)
+				return
+			}
+		case <-pingTicker.C:
+			ls.logger.Debug("send ping")
+			if err := ls.keepAlive(); err != nil {
+				ls.logger.Error("unable to send ping", "err", err)
+				return
+			}
+		}
+	}
+}
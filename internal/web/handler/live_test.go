@@ -0,0 +1,508 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/store"
+)
+
+func newTestStore(t *testing.T) store.Store {
+	t.Helper()
+
+	db, err := store.NewOtterStore(store.OtterOptions{Eviction: store.EvictionNone})
+	if err != nil {
+		t.Fatalf("unable to create store: %v", err)
+	}
+	return db
+}
+
+// setResource writes a resource as the informers do, with its index entries.
+func setResource(t *testing.T, db store.Store, kind string, obj metav1.Object, entries ...store.IndexEntry) {
+	t.Helper()
+
+	key := resourceKey(kind, obj.GetNamespace(), obj.GetName())
+	value, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("unable to marshal %s: %v", key, err)
+	}
+	if err := db.Set([]byte(key), value); err != nil {
+		t.Fatalf("unable to set %s: %v", key, err)
+	}
+	if err := db.SetIndex([]byte(key), entries); err != nil {
+		t.Fatalf("unable to index %s: %v", key, err)
+	}
+}
+
+func testPod(ns string, name string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: "n1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func renderString(t *testing.T, c templ.Component) string {
+	t.Helper()
+
+	var sb strings.Builder
+	if err := c.Render(context.Background(), &sb); err != nil {
+		t.Fatalf("unable to render: %v", err)
+	}
+	return sb.String()
+}
+
+func TestLiveStateChanges(t *testing.T) {
+	web := map[string]string{"app": "web"}
+
+	tests := []struct {
+		name     string
+		sub      subscription
+		query    string        // list options of the subscription
+		pods     []*corev1.Pod // shown when the page was rendered
+		update   func(t *testing.T, db store.Store)
+		keys     []string
+		want     []string // fragments the update contains
+		wantNot  []string
+		wantNone bool
+	}{
+		{
+			name:     "no relevant keys",
+			sub:      subscription{Kind: "pod", Namespace: "a"},
+			pods:     []*corev1.Pod{testPod("a", "x", nil)},
+			keys:     []string{resourceKey("deployment", "a", "x"), resourceKey("pod", "b", "x")},
+			wantNone: true,
+		},
+		{
+			name: "changed row",
+			sub:  subscription{Kind: "pod", Namespace: "a"},
+			pods: []*corev1.Pod{testPod("a", "x", nil), testPod("a", "y", nil)},
+			update: func(t *testing.T, db store.Store) {
+				pd := testPod("a", "x", nil)
+				pd.Status.Phase = corev1.PodFailed
+				setResource(t, db, "pod", pd)
+			},
+			keys:    []string{resourceKey("pod", "a", "x"), resourceKey("pod", "a", "x")},
+			want:    []string{`id="a/x" hx-swap-oob="outerHTML"`, "Failed"},
+			wantNot: []string{`id="pods-container"`, `id="a/y"`},
+		},
+		{
+			name: "removed row",
+			sub:  subscription{Kind: "pod", Namespace: "a"},
+			pods: []*corev1.Pod{testPod("a", "x", nil), testPod("a", "y", nil)},
+			update: func(t *testing.T, db store.Store) {
+				if err := db.Delete([]byte(resourceKey("pod", "a", "y"))); err != nil {
+					t.Fatalf("unable to delete: %v", err)
+				}
+			},
+			keys:    []string{resourceKey("pod", "a", "y")},
+			want:    []string{`id="a/y" hx-swap-oob="delete"`},
+			wantNot: []string{`id="pods-container"`, `id="a/x"`},
+		},
+		{
+			name: "changed and removed rows",
+			sub:  subscription{Kind: "pod", Namespace: "a"},
+			pods: []*corev1.Pod{testPod("a", "x", nil), testPod("a", "y", nil), testPod("a", "z", nil)},
+			update: func(t *testing.T, db store.Store) {
+				setResource(t, db, "pod", testPod("a", "x", web))
+				if err := db.Delete([]byte(resourceKey("pod", "a", "z"))); err != nil {
+					t.Fatalf("unable to delete: %v", err)
+				}
+			},
+			keys:    []string{resourceKey("pod", "a", "z"), resourceKey("pod", "a", "x")},
+			want:    []string{`id="a/x" hx-swap-oob="outerHTML"`, `id="a/z" hx-swap-oob="delete"`},
+			wantNot: []string{`id="pods-container"`, `id="a/y"`},
+		},
+		{
+			name: "added row re-renders the list",
+			sub:  subscription{Kind: "pod", Namespace: "a"},
+			pods: []*corev1.Pod{testPod("a", "x", nil)},
+			update: func(t *testing.T, db store.Store) {
+				setResource(t, db, "pod", testPod("a", "w", nil))
+			},
+			keys: []string{resourceKey("pod", "a", "w")},
+			want: []string{`id="pods-container"`, `id="a/w"`, `id="a/x"`},
+		},
+		{
+			name: "removing the last row re-renders the list",
+			sub:  subscription{Kind: "pod", Namespace: "a"},
+			pods: []*corev1.Pod{testPod("a", "x", nil)},
+			update: func(t *testing.T, db store.Store) {
+				if err := db.Delete([]byte(resourceKey("pod", "a", "x"))); err != nil {
+					t.Fatalf("unable to delete: %v", err)
+				}
+			},
+			keys: []string{resourceKey("pod", "a", "x")},
+			want: []string{`id="pods-container"`, "No Pods found"},
+		},
+		{
+			name: "deleted pod not shown",
+			sub:  subscription{Kind: "pod", Namespace: "a"},
+			pods: []*corev1.Pod{testPod("a", "x", nil)},
+			keys: []string{resourceKey("pod", "a", "gone")},
+			// Nothing on the page changed
+			wantNone: true,
+		},
+		{
+			name:  "row no longer matching the selector is removed",
+			sub:   subscription{Kind: "pod", Namespace: "a"},
+			query: "selector=app%3Dweb",
+			pods:  []*corev1.Pod{testPod("a", "x", web), testPod("a", "y", web)},
+			update: func(t *testing.T, db store.Store) {
+				setResource(t, db, "pod", testPod("a", "x", nil))
+			},
+			keys:    []string{resourceKey("pod", "a", "x")},
+			want:    []string{`id="a/x" hx-swap-oob="delete"`},
+			wantNot: []string{`id="pods-container"`},
+		},
+		{
+			name:  "changed row of a sorted list re-renders the list",
+			sub:   subscription{Kind: "pod", Namespace: "a"},
+			query: "sort=name",
+			pods:  []*corev1.Pod{testPod("a", "x", nil), testPod("a", "y", nil)},
+			update: func(t *testing.T, db store.Store) {
+				setResource(t, db, "pod", testPod("a", "x", web))
+			},
+			keys: []string{resourceKey("pod", "a", "x")},
+			want: []string{`id="pods-container"`, `id="a/x"`, `id="a/y"`},
+		},
+		{
+			name: "changed row of all namespaces",
+			sub:  subscription{Kind: "pod", Namespace: core.AllNamespaces},
+			pods: []*corev1.Pod{testPod("a", "x", nil), testPod("b", "x", nil)},
+			update: func(t *testing.T, db store.Store) {
+				setResource(t, db, "pod", testPod("b", "x", web))
+			},
+			keys:    []string{resourceKey("pod", "b", "x")},
+			want:    []string{`id="b/x" hx-swap-oob="outerHTML"`},
+			wantNot: []string{`id="pods-container"`, `id="a/x"`},
+		},
+		{
+			name: "views without rows are re-rendered",
+			sub:  subscription{Kind: "node"},
+			update: func(t *testing.T, db store.Store) {
+				setResource(t, db, "node", &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}})
+			},
+			keys: []string{resourceKey("node", "", "n1")},
+			want: []string{"n1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("invalid query %q: %v", tt.query, err)
+			}
+			if tt.sub.List, err = core.ParseListOptions(query); err != nil {
+				t.Fatalf("unable to parse options: %v", err)
+			}
+
+			db := newTestStore(t)
+			for _, pd := range tt.pods {
+				setResource(t, db, "pod", pd)
+			}
+
+			view, ok := findLiveView(db, tt.sub)
+			if !ok {
+				t.Fatalf("no live view for %+v", tt.sub)
+			}
+			ls := newLiveState(db, tt.sub, view)
+			ls.full(db)
+
+			if tt.update != nil {
+				tt.update(t, db)
+			}
+			c := ls.changes(db, tt.keys)
+			if tt.wantNone {
+				if c != nil {
+					t.Errorf("got update %s, want none", renderString(t, c))
+				}
+				return
+			}
+			if c == nil {
+				t.Fatal("got no update")
+			}
+
+			html := renderString(t, c)
+			for _, want := range tt.want {
+				if !strings.Contains(html, want) {
+					t.Errorf("update doesn't contain %q: %s", want, html)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(html, wantNot) {
+					t.Errorf("update contains %q: %s", wantNot, html)
+				}
+			}
+		})
+	}
+}
+
+func TestLiveStateChangesBeforeRendering(t *testing.T) {
+	db := newTestStore(t)
+	setResource(t, db, "pod", testPod("a", "x", nil))
+
+	sub := subscription{Kind: "pod", Namespace: "a"}
+	ls := newLiveState(db, sub, liveLists["pod"])
+
+	// Rows are unknown until the list was rendered once
+	c := ls.changes(db, []string{resourceKey("pod", "a", "x")})
+	if c == nil {
+		t.Fatal("got no update")
+	}
+	if html := renderString(t, c); !strings.Contains(html, `id="pods-container"`) {
+		t.Errorf("got %s, want the whole list", html)
+	}
+	if _, ok := ls.rows[resourceKey("pod", "a", "x")]; !ok {
+		t.Errorf("rows %v don't contain the rendered pod", ls.rows)
+	}
+}
+
+func TestLiveViewsRelevant(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  subscription
+		key  string
+		want bool
+	}{
+		// Lists
+		{"pod list", subscription{Kind: "pod", Namespace: "a"}, resourceKey("pod", "a", "x"), true},
+		{"pod list other namespace", subscription{Kind: "pod", Namespace: "a"}, resourceKey("pod", "b", "x"), false},
+		{"pod list namespace prefix", subscription{Kind: "pod", Namespace: "a"}, resourceKey("pod", "ab", "x"), false},
+		{"pod list other kind", subscription{Kind: "pod", Namespace: "a"}, resourceKey("deployment", "a", "x"), false},
+		{"pod list usage", subscription{Kind: "pod", Namespace: "a"}, resourceKey("podusage", "a", "x"), false},
+		{"pod list all namespaces", subscription{Kind: "pod", Namespace: core.AllNamespaces}, resourceKey("pod", "b", "x"), true},
+		{"pod list all namespaces other kind", subscription{Kind: "pod", Namespace: core.AllNamespaces}, resourceKey("service", "b", "x"), false},
+		{"node list", subscription{Kind: "node"}, resourceKey("node", "", "n1"), true},
+		{"node list usage", subscription{Kind: "node"}, resourceKey("nodeusage", "", "n1"), false},
+		{"persistent volume list", subscription{Kind: "persistentvolume"}, resourceKey("persistentvolume", "", "pv1"), true},
+		{"persistent volume list claim", subscription{Kind: "persistentvolume"}, resourceKey("persistentvolumeclaim", "a", "pvc1"), false},
+		{"cluster problems pod", subscription{Kind: "cluster"}, resourceKey("pod", "a", "x"), true},
+		{"cluster problems node", subscription{Kind: "cluster"}, resourceKey("node", "", "n1"), true},
+		{"cluster problems config map", subscription{Kind: "cluster"}, resourceKey("configmap", "a", "x"), false},
+		{"warnings event", subscription{Kind: "event"}, resourceKey("event", "a", "e1"), true},
+		{"warnings pod", subscription{Kind: "event"}, resourceKey("pod", "a", "x"), false},
+
+		// Details
+		{"pod", subscription{Kind: "pod", Namespace: "a", Name: "x"}, resourceKey("pod", "a", "x"), true},
+		{"pod usage", subscription{Kind: "pod", Namespace: "a", Name: "x"}, resourceKey("podusage", "a", "x"), true},
+		{"pod other pod", subscription{Kind: "pod", Namespace: "a", Name: "x"}, resourceKey("pod", "a", "y"), false},
+		{"deployment", subscription{Kind: "deployment", Namespace: "a", Name: "d"}, resourceKey("deployment", "a", "d"), true},
+		{"deployment pod", subscription{Kind: "deployment", Namespace: "a", Name: "d"}, resourceKey("pod", "a", "x"), true},
+		{"deployment pod other namespace", subscription{Kind: "deployment", Namespace: "a", Name: "d"}, resourceKey("pod", "b", "x"), false},
+		{"deployment service", subscription{Kind: "deployment", Namespace: "a", Name: "d"}, resourceKey("service", "a", "x"), false},
+		{"endpoint slice other slice", subscription{Kind: "endpointslice", Namespace: "a", Name: "s"}, resourceKey("endpointslice", "a", "t"), false},
+		{"node", subscription{Kind: "node", Name: "n1"}, resourceKey("node", "", "n1"), true},
+		{"node usage", subscription{Kind: "node", Name: "n1"}, resourceKey("nodeusage", "", "n1"), true},
+		{"node other node", subscription{Kind: "node", Name: "n1"}, resourceKey("node", "", "n2"), false},
+		{"namespace", subscription{Kind: "namespace", Name: "a"}, resourceKey("namespace", "", "a"), true},
+		{"namespace resource", subscription{Kind: "namespace", Name: "a"}, resourceKey("configmap", "a", "x"), true},
+		{"namespace other namespace", subscription{Kind: "namespace", Name: "a"}, resourceKey("configmap", "b", "x"), false},
+		{"namespace usage", subscription{Kind: "namespace", Name: "a"}, resourceKey("podusage", "a", "x"), false},
+		{"all namespaces", subscription{Kind: "namespace", Name: core.AllNamespaces}, resourceKey("configmap", "b", "x"), true},
+		{"service account cluster role", subscription{Kind: "serviceaccount", Namespace: "a", Name: "sa"}, resourceKey("clusterrole", "", "r"), true},
+		{"role binding cluster role binding", subscription{Kind: "rolebinding", Namespace: "a", Name: "rb"}, resourceKey("clusterrolebinding", "", "r"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view, ok := findLiveView(nil, tt.sub)
+			if !ok {
+				t.Fatalf("no live view for %+v", tt.sub)
+			}
+			if got := view.relevant(tt.sub, tt.key); got != tt.want {
+				t.Errorf("relevant(%s) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiveStateRelated(t *testing.T) {
+	onNode := func(node string) store.IndexEntry {
+		return store.IndexEntry{Index: store.IndexNode, Value: node}
+	}
+	regarding := func(kind string, ns string, name string) store.IndexEntry {
+		return store.IndexEntry{Index: store.IndexRegarding, Value: store.RegardingIndexValue(kind, ns, name)}
+	}
+	testEvent := func(ns string, name string) *eventsv1.Event {
+		return &eventsv1.Event{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}}
+	}
+	withClaim := func(pd *corev1.Pod, claim string) *corev1.Pod {
+		pd.Spec.Volumes = append(pd.Spec.Volumes, corev1.Volume{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+			},
+		})
+		return pd
+	}
+
+	// Shown when the page was rendered
+	seed := func(t *testing.T, db store.Store) {
+		setResource(t, db, "node", &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}})
+		setResource(t, db, "pod", withClaim(testPod("a", "x", nil), "data-x"), onNode("n1"))
+		setResource(t, db, "pod", testPod("a", "y", nil), onNode("n2"))
+		setResource(t, db, "persistentvolumeclaim", &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "data-x"}})
+		setResource(t, db, "persistentvolumeclaim", &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "data-y"}})
+		setResource(t, db, "event", testEvent("a", "x.1"), regarding("Pod", "a", "x"))
+		setResource(t, db, "event", testEvent("a", "y.1"), regarding("Pod", "a", "y"))
+		setResource(t, db, "event", testEvent("default", "n1.1"), regarding("Node", "", "n1"))
+		setResource(t, db, "event", testEvent("default", "n2.1"), regarding("Node", "", "n2"))
+	}
+
+	podDetail := subscription{Kind: "pod", Namespace: "a", Name: "x"}
+	nodeDetail := subscription{Kind: "node", Name: "n1"}
+
+	tests := []struct {
+		name       string
+		sub        subscription
+		update     func(t *testing.T, db store.Store)
+		key        string
+		wantUpdate bool
+	}{
+		{
+			name:       "pod itself",
+			sub:        podDetail,
+			key:        resourceKey("pod", "a", "x"),
+			wantUpdate: true,
+		},
+		{
+			name: "other pod",
+			sub:  podDetail,
+			key:  resourceKey("pod", "a", "y"),
+		},
+		{
+			name:       "event of the pod",
+			sub:        podDetail,
+			key:        resourceKey("event", "a", "x.1"),
+			wantUpdate: true,
+		},
+		{
+			name: "event of another pod",
+			sub:  podDetail,
+			key:  resourceKey("event", "a", "y.1"),
+		},
+		{
+			name: "new event of the pod",
+			sub:  podDetail,
+			update: func(t *testing.T, db store.Store) {
+				setResource(t, db, "event", testEvent("a", "x.2"), regarding("Pod", "a", "x"))
+			},
+			key:        resourceKey("event", "a", "x.2"),
+			wantUpdate: true,
+		},
+		{
+			name: "pruned event of the pod",
+			sub:  podDetail,
+			update: func(t *testing.T, db store.Store) {
+				if err := db.Delete([]byte(resourceKey("event", "a", "x.1"))); err != nil {
+					t.Fatalf("unable to delete: %v", err)
+				}
+			},
+			key:        resourceKey("event", "a", "x.1"),
+			wantUpdate: true,
+		},
+		{
+			name:       "claim of the pod",
+			sub:        podDetail,
+			key:        resourceKey("persistentvolumeclaim", "a", "data-x"),
+			wantUpdate: true,
+		},
+		{
+			name: "claim of another pod",
+			sub:  podDetail,
+			key:  resourceKey("persistentvolumeclaim", "a", "data-y"),
+		},
+		{
+			name:       "node itself",
+			sub:        nodeDetail,
+			key:        resourceKey("node", "", "n1"),
+			wantUpdate: true,
+		},
+		{
+			name:       "pod on the node",
+			sub:        nodeDetail,
+			key:        resourceKey("pod", "a", "x"),
+			wantUpdate: true,
+		},
+		{
+			name: "pod on another node",
+			sub:  nodeDetail,
+			key:  resourceKey("pod", "a", "y"),
+		},
+		{
+			name: "pod scheduled to the node",
+			sub:  nodeDetail,
+			update: func(t *testing.T, db store.Store) {
+				setResource(t, db, "pod", testPod("a", "y", nil), onNode("n1"))
+			},
+			key:        resourceKey("pod", "a", "y"),
+			wantUpdate: true,
+		},
+		{
+			name: "pod deleted from the node",
+			sub:  nodeDetail,
+			update: func(t *testing.T, db store.Store) {
+				if err := db.Delete([]byte(resourceKey("pod", "a", "x"))); err != nil {
+					t.Fatalf("unable to delete: %v", err)
+				}
+			},
+			key:        resourceKey("pod", "a", "x"),
+			wantUpdate: true,
+		},
+		{
+			name:       "event of the node",
+			sub:        nodeDetail,
+			key:        resourceKey("event", "default", "n1.1"),
+			wantUpdate: true,
+		},
+		{
+			name: "event of another node",
+			sub:  nodeDetail,
+			key:  resourceKey("event", "default", "n2.1"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestStore(t)
+			seed(t, db)
+
+			view, ok := findLiveView(db, tt.sub)
+			if !ok {
+				t.Fatalf("no live view for %+v", tt.sub)
+			}
+			ls := newLiveState(db, tt.sub, view)
+			ls.full(db)
+
+			if tt.update != nil {
+				tt.update(t, db)
+			}
+			if c := ls.changes(db, []string{tt.key}); (c != nil) != tt.wantUpdate {
+				t.Errorf("got update %v, want update %v", c != nil, tt.wantUpdate)
+			}
+		})
+	}
+
+	// A pod that left the node is no longer related once the page was updated
+	db := newTestStore(t)
+	seed(t, db)
+	ls := newLiveState(db, nodeDetail, liveDetails["node"])
+	setResource(t, db, "pod", testPod("a", "x", nil), onNode("n2"))
+	if c := ls.changes(db, []string{resourceKey("pod", "a", "x")}); c == nil {
+		t.Error("got no update for the pod leaving the node")
+	}
+	if c := ls.changes(db, []string{resourceKey("pod", "a", "x")}); c != nil {
+		t.Error("got an update for the pod on another node")
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"polar-bear/internal/core"
+)
+
+// manifestObject is a stored object as returned by the core getters, which
+// return nil if it doesn't exist.
+type manifestObject interface {
+	comparable
+	runtime.Object
+}
+
+// manifestFormat returns the format the object of a detail page is requested
+// in instead of the page: the format parameter, or yaml or json if the Accept
+// header prefers them over HTML. It returns "" for the page.
+func manifestFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	for accept := range strings.SplitSeq(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/yaml", "application/x-yaml", "text/yaml":
+			return "yaml"
+		case "application/json":
+			return "json"
+		case "text/html", "*/*":
+			return ""
+		}
+	}
+	return ""
+}
+
+// serveManifest writes obj as YAML or JSON if the request asks for it and
+// reports whether it did, the detail page is rendered otherwise.
+func serveManifest[T manifestObject](w http.ResponseWriter, r *http.Request, obj T) bool {
+	format := manifestFormat(r)
+	if format == "" {
+		return false
+	}
+	if format != "yaml" && format != "json" {
+		http.Error(w, fmt.Sprintf("unsupported format %q, use yaml or json", format), http.StatusNotAcceptable)
+		return true
+	}
+
+	var zero T
+	if obj == zero {
+		http.NotFound(w, r)
+		return true
+	}
+
+	out, err := encodeManifest(obj, format)
+	if err != nil {
+		slog.Error("unable to encode manifest", "component", "handler-manifest", "format", format, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return true
+	}
+
+	if format == "yaml" {
+		w.Header().Set("Content-Type", "application/yaml")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	_, _ = w.Write(out)
+	return true
+}
+
+// detailManifest returns the YAML of obj for the YAML tab of a detail page,
+// or nil for the details tab.
+func detailManifest[T manifestObject](r *http.Request, obj T) []byte {
+	var zero T
+	if r.URL.Query().Get("tab") != "yaml" || obj == zero {
+		return nil
+	}
+
+	out, err := encodeManifest(obj, "yaml")
+	if err != nil {
+		slog.Error("unable to encode manifest", "component", "handler-manifest", "format", "yaml", "error", err)
+		return nil
+	}
+	return out
+}
+
+func encodeManifest(obj runtime.Object, format string) ([]byte, error) {
+	manifest, err := core.Manifest(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "yaml" {
+		return yaml.Marshal(manifest)
+	}
+	out, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -32,21 +32,22 @@ func Namespace(
 				return
 			}
 
-			data := &namespace.Data{
-				Start:            &startTime,
-				Config:           cfg,
-				Meta:             rm,
-				Namespace:        core.GetNamespace(store, ns),
-				Namespaces:       core.GetNamespaces(store),
-				PodCount:         core.CountPods(store, ns),
-				ReplicaSetCount:  core.CountReplicaSets(store, ns),
-				StatefulSetCount: core.CountStatefulSets(store, ns),
-				DeploymentCount:  core.CountDeployments(store, ns),
-				ServiceCount:     core.CountServices(store, ns),
-				IngressCount:     core.CountIngresses(store, ns),
+			data := namespaceData(store, ns)
+			if data.Namespace == nil && ns != core.AllNamespaces {
+				http.NotFound(w, r)
+				return
+			}
+			if data.Namespace != nil {
+				if serveManifest(w, r, data.Namespace) {
+					return
+				}
+				data.Manifest = detailManifest(r, data.Namespace)
 			}
+			data.Start = &startTime
+			data.Config = cfg
+			data.Meta = rm
 
-			err = namespace.DetailView(data).Render(r.Context(), w)
+			err = render(r.Context(), w, "namespace-detail", namespace.DetailView(data))
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				return
@@ -54,3 +55,49 @@ func Namespace(
 		},
 	)
 }
+
+// namespaceData collects the resources shown on the namespace page, ns may be
+// core.AllNamespaces.
+func namespaceData(store store.Store, ns string) *namespace.Data {
+	data := &namespace.Data{
+		Name:                       ns,
+		Namespaces:                 core.GetNamespaces(store),
+		PodCount:                   core.CountPods(store, ns),
+		ReplicaSetCount:            core.CountReplicaSets(store, ns),
+		StatefulSetCount:           core.CountStatefulSets(store, ns),
+		DaemonSetCount:             core.CountDaemonSets(store, ns),
+		DeploymentCount:            core.CountDeployments(store, ns),
+		JobCount:                   core.CountJobs(store, ns),
+		CronJobCount:               core.CountCronJobs(store, ns),
+		ServiceCount:               core.CountServices(store, ns),
+		EndpointSliceCount:         core.CountEndpointSlices(store, ns),
+		IngressCount:               core.CountIngresses(store, ns),
+		NetworkPolicyCount:         core.CountNetworkPolicies(store, ns),
+		PersistentVolumeClaimCount: core.CountPersistentVolumeClaims(store, ns),
+		ConfigMapCount:             core.CountConfigMaps(store, ns),
+		SecretCount:                core.CountSecrets(store, ns),
+		ServiceAccountCount:        core.CountServiceAccounts(store, ns),
+		RoleCount:                  core.CountRoles(store, ns),
+		RoleBindingCount:           core.CountRoleBindings(store, ns),
+		Events:                     core.GetRecentEvents(store, ns, recentEventsLimit),
+		Capacity:                   core.NamespaceCapacity(store, ns),
+	}
+
+	if ns != core.AllNamespaces {
+		data.Namespace = core.GetNamespace(store, ns)
+	}
+
+	for _, crt := range core.GetCustomResourceTypes(store) {
+		if !crt.Namespaced {
+			continue
+		}
+		if count := core.CountCustomResources(store, crt, ns); count > 0 {
+			data.CustomResources = append(data.CustomResources, namespace.CustomResourceCount{
+				Type:  crt,
+				Count: count,
+			})
+		}
+	}
+
+	return data
+}
@@ -5,6 +5,8 @@ import (
 	"net/url"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+
 	"polar-bear/internal/config"
 	"polar-bear/internal/core"
 	"polar-bear/internal/runtimemeta"
@@ -33,9 +35,17 @@ func Node(
 			}
 
 			res := core.GetNode(store, no)
+			if serveManifest(w, r, res) {
+				return
+			}
+
+			c := nodeCapacity(store, res)
+			u := core.GetNodeUsage(store, no)
+			pds := core.GetPodsOnNode(store, no)
+			evs := core.GetEventsRegarding(store, "Node", "", no)
 			nss := core.GetNamespaces(store)
 
-			err = node.DetailView(&startTime, cfg, rm, no, res, nss).Render(r.Context(), w)
+			err = render(r.Context(), w, "node-detail", node.DetailView(&startTime, cfg, rm, no, res, c, u, pds, evs, nss, detailManifest(r, res)))
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				return
@@ -43,3 +53,12 @@ func Node(
 		},
 	)
 }
+
+// nodeCapacity returns the allocation of a node by its pods, the zero value if
+// it doesn't exist.
+func nodeCapacity(store store.Store, no *corev1.Node) core.Capacity {
+	if no == nil {
+		return core.Capacity{}
+	}
+	return core.NodeCapacity(store, no)
+}
@@ -25,10 +25,16 @@ func Nodes(
 				return
 			}
 
-			nos := core.GetNodes(store)
+			ctx, opts, err := listContext(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			nos := core.FilterObjects(core.GetNodes(store), opts)
 			nss := core.GetNamespaces(store)
 
-			err = node.ListView(&startTime, cfg, rm, nos, nss).Render(r.Context(), w)
+			err = render(ctx, w, "node-list", node.ListView(&startTime, cfg, rm, nos, nss))
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				return
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"polar-bear/internal/config"
+	"polar-bear/internal/core"
+	"polar-bear/internal/runtimemeta"
+	"polar-bear/internal/store"
+	"polar-bear/internal/web/view/clusterrole"
+	"polar-bear/internal/web/view/clusterrolebinding"
+	"polar-bear/internal/web/view/rbac"
+)
+
+func ClusterRoles(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			ctx, opts, err := listContext(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			crs := core.FilterObjects(core.GetClusterRoles(store), opts)
+			nss := core.GetNamespaces(store)
+
+			err = render(ctx, w, "clusterrole-list", clusterrole.ListView(&startTime, cfg, rm, crs, nss))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func ClusterRole(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			name, err := url.QueryUnescape(r.PathValue("clusterrole"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+
+			cr := core.GetClusterRole(store, name)
+			if serveManifest(w, r, cr) {
+				return
+			}
+
+			rules, aggregated := clusterRoleRules(store, cr)
+			crbs := core.GetClusterRoleBindingsOfRole(store, name)
+			rbs := core.GetRoleBindingsOfRole(store, "", "ClusterRole", name)
+			nss := core.GetNamespaces(store)
+
+			err = render(
+				r.Context(), w, "clusterrole-detail",
+				clusterrole.DetailView(&startTime, cfg, rm, name, cr, rules, aggregated, crbs, rbs, nss, detailManifest(r, cr)),
+			)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func ClusterRoleBindings(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			ctx, opts, err := listContext(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			crbs := core.FilterObjects(core.GetClusterRoleBindings(store), opts)
+			nss := core.GetNamespaces(store)
+
+			err = render(ctx, w, "clusterrolebinding-list", clusterrolebinding.ListView(&startTime, cfg, rm, crbs, nss))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func ClusterRoleBinding(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			name, err := url.QueryUnescape(r.PathValue("clusterrolebinding"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+
+			crb := core.GetClusterRoleBinding(store, name)
+			if serveManifest(w, r, crb) {
+				return
+			}
+
+			rules := clusterRoleBindingRules(store, crb)
+			nss := core.GetNamespaces(store)
+
+			err = render(
+				r.Context(), w, "clusterrolebinding-detail",
+				clusterrolebinding.DetailView(&startTime, cfg, rm, name, crb, rules, nss, detailManifest(r, crb)),
+			)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+// RBAC answers what a subject can do or who can perform a request, in a
+// namespace or cluster wide, from the RBAC objects in the store.
+func RBAC(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			query := r.URL.Query()
+			q := rbac.Query{
+				Namespace:   query.Get("ns"),
+				SubjectKind: query.Get("kind"),
+				Subject:     query.Get("subject"),
+				Verb:        query.Get("verb"),
+				Group:       query.Get("group"),
+				Resource:    query.Get("resource"),
+				Name:        query.Get("name"),
+			}
+			if q.SubjectKind == "" {
+				q.SubjectKind = rbacv1.UserKind
+			}
+
+			var grants []core.Grant
+			if q.Subject != "" {
+				grants = core.GrantsOf(store, core.ParseSubject(q.SubjectKind, q.Subject), q.Namespace)
+			}
+			var accesses []core.Access
+			if q.Resource != "" {
+				accesses = core.SubjectsAllowed(store, q.Verb, q.Group, q.Resource, q.Name, q.Namespace)
+			}
+			nss := core.GetNamespaces(store)
+
+			err = render(r.Context(), w, "rbac", rbac.EvaluatorView(&startTime, cfg, rm, q, grants, accesses, nss))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+// clusterRoleRules returns the rules of a cluster role including aggregated
+// ones and the cluster roles it aggregates, nil if it doesn't exist.
+func clusterRoleRules(store store.Store, cr *rbacv1.ClusterRole) ([]rbacv1.PolicyRule, []*rbacv1.ClusterRole) {
+	if cr == nil {
+		return nil, nil
+	}
+	return core.ClusterRoleRules(store, cr), core.GetAggregatedClusterRoles(store, cr)
+}
+
+// clusterRoleBindingRules returns the rules granted by a cluster role binding.
+func clusterRoleBindingRules(store store.Store, crb *rbacv1.ClusterRoleBinding) []rbacv1.PolicyRule {
+	if crb == nil {
+		return nil
+	}
+	return core.RoleRules(store, "", crb.RoleRef)
+}
+
+// roleBindingRules returns the rules granted by a role binding.
+func roleBindingRules(store store.Store, rb *rbacv1.RoleBinding) []rbacv1.PolicyRule {
+	if rb == nil {
+		return nil
+	}
+	return core.RoleRules(store, rb.Namespace, rb.RoleRef)
+}
+
+// serviceAccountGrants returns the rules granted to a service account in its
+// namespace.
+func serviceAccountGrants(store store.Store, sa *corev1.ServiceAccount) []core.Grant {
+	if sa == nil {
+		return nil
+	}
+	subject := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: sa.Namespace, Name: sa.Name}
+	return core.GrantsOf(store, subject, sa.Namespace)
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/a-h/templ"
+
+	"polar-bear/internal/metrics"
+)
+
+// render writes a view and records how long rendering took.
+func render(ctx context.Context, w io.Writer, view string, c templ.Component) error {
+	defer func(start time.Time) {
+		metrics.RenderDuration.WithLabelValues(view).Observe(time.Since(start).Seconds())
+	}(time.Now())
+
+	return c.Render(ctx, w)
+}
@@ -5,12 +5,35 @@ import (
 	"net/url"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
 	"polar-bear/internal/config"
 	"polar-bear/internal/core"
 	"polar-bear/internal/runtimemeta"
 	"polar-bear/internal/store"
+	"polar-bear/internal/web/view/configmap"
+	"polar-bear/internal/web/view/cronjob"
+	"polar-bear/internal/web/view/customresource"
+	"polar-bear/internal/web/view/daemonset"
 	"polar-bear/internal/web/view/deployment"
+	"polar-bear/internal/web/view/endpointslice"
+	"polar-bear/internal/web/view/ingress"
+	"polar-bear/internal/web/view/job"
+	"polar-bear/internal/web/view/networkpolicy"
+	"polar-bear/internal/web/view/persistentvolumeclaim"
 	"polar-bear/internal/web/view/pod"
+	"polar-bear/internal/web/view/replicaset"
+	"polar-bear/internal/web/view/role"
+	"polar-bear/internal/web/view/rolebinding"
+	"polar-bear/internal/web/view/secret"
+	"polar-bear/internal/web/view/service"
+	"polar-bear/internal/web/view/serviceaccount"
+	"polar-bear/internal/web/view/statefulset"
 )
 
 func Resource(
@@ -50,10 +73,204 @@ func Resource(
 			switch res {
 			case "pd":
 				pd := core.GetPod(store, ns, name)
-				err = pod.DetailView(&startTime, cfg, rm, ns, name, pd, nss).Render(r.Context(), w)
+				if serveManifest(w, r, pd) {
+					return
+				}
+				u := core.GetPodUsage(store, ns, name)
+				claims := podClaims(store, pd)
+				evs := core.GetEventsRegarding(store, "Pod", ns, name)
+				err = render(
+					r.Context(), w, "pod-detail",
+					pod.DetailView(&startTime, cfg, rm, ns, name, pd, u, claims, evs, nss, detailManifest(r, pd)),
+				)
 			case "deploy":
 				deploy := core.GetDeployment(store, ns, name)
-				err = deployment.DetailView(&startTime, cfg, rm, ns, name, deploy, nss).Render(r.Context(), w)
+				if serveManifest(w, r, deploy) {
+					return
+				}
+				rss := ownedBy(store, deploy, core.GetReplicaSetsOwnedBy)
+				pds := deploymentPods(store, deploy)
+				evs := core.GetEventsRegarding(store, "Deployment", ns, name)
+				err = render(
+					r.Context(), w, "deployment-detail",
+					deployment.DetailView(&startTime, cfg, rm, ns, name, deploy, rss, pds, evs, nss, detailManifest(r, deploy)),
+				)
+			case "rs":
+				rs := core.GetReplicaSet(store, ns, name)
+				if serveManifest(w, r, rs) {
+					return
+				}
+				pds := ownedBy(store, rs, core.GetPodsOwnedBy)
+				evs := core.GetEventsRegarding(store, "ReplicaSet", ns, name)
+				err = render(
+					r.Context(), w, "replicaset-detail",
+					replicaset.DetailView(&startTime, cfg, rm, ns, name, rs, pds, evs, nss, detailManifest(r, rs)),
+				)
+			case "sts":
+				st := core.GetStatefulSet(store, ns, name)
+				if serveManifest(w, r, st) {
+					return
+				}
+				crs := ownedBy(store, st, core.GetControllerRevisionsOwnedBy)
+				pds := ownedBy(store, st, core.GetPodsOwnedBy)
+				evs := core.GetEventsRegarding(store, "StatefulSet", ns, name)
+				err = render(
+					r.Context(), w, "statefulset-detail",
+					statefulset.DetailView(&startTime, cfg, rm, ns, name, st, crs, pds, evs, nss, detailManifest(r, st)),
+				)
+			case "ds":
+				ds := core.GetDaemonSet(store, ns, name)
+				if serveManifest(w, r, ds) {
+					return
+				}
+				crs := ownedBy(store, ds, core.GetControllerRevisionsOwnedBy)
+				pds := ownedBy(store, ds, core.GetPodsOwnedBy)
+				evs := core.GetEventsRegarding(store, "DaemonSet", ns, name)
+				err = render(
+					r.Context(), w, "daemonset-detail",
+					daemonset.DetailView(&startTime, cfg, rm, ns, name, ds, crs, pds, evs, nss, detailManifest(r, ds)),
+				)
+			case "job":
+				jb := core.GetJob(store, ns, name)
+				if serveManifest(w, r, jb) {
+					return
+				}
+				pds := ownedBy(store, jb, core.GetPodsOwnedBy)
+				evs := core.GetEventsRegarding(store, "Job", ns, name)
+				err = render(
+					r.Context(), w, "job-detail",
+					job.DetailView(&startTime, cfg, rm, ns, name, jb, pds, evs, nss, detailManifest(r, jb)),
+				)
+			case "cronjob":
+				cj := core.GetCronJob(store, ns, name)
+				if serveManifest(w, r, cj) {
+					return
+				}
+				jobs := ownedBy(store, cj, core.GetJobsOwnedBy)
+				pds := cronJobPods(store, cj)
+				evs := core.GetEventsRegarding(store, "CronJob", ns, name)
+				err = render(
+					r.Context(), w, "cronjob-detail",
+					cronjob.DetailView(&startTime, cfg, rm, ns, name, cj, jobs, pds, evs, nss, detailManifest(r, cj)),
+				)
+			case "svc":
+				svc := core.GetService(store, ns, name)
+				if serveManifest(w, r, svc) {
+					return
+				}
+				slices := core.GetEndpointSlicesOfService(store, ns, name)
+				pds := servicePods(store, svc)
+				evs := core.GetEventsRegarding(store, "Service", ns, name)
+				err = render(
+					r.Context(), w, "service-detail",
+					service.DetailView(&startTime, cfg, rm, ns, name, svc, slices, pds, evs, nss, detailManifest(r, svc)),
+				)
+			case "epslice":
+				slice := core.GetEndpointSlice(store, ns, name)
+				if serveManifest(w, r, slice) {
+					return
+				}
+				err = render(
+					r.Context(), w, "endpointslice-detail",
+					endpointslice.DetailView(&startTime, cfg, rm, ns, name, slice, nss, detailManifest(r, slice)),
+				)
+			case "ing":
+				ing := core.GetIngress(store, ns, name)
+				if serveManifest(w, r, ing) {
+					return
+				}
+				evs := core.GetEventsRegarding(store, "Ingress", ns, name)
+				err = render(
+					r.Context(), w, "ingress-detail",
+					ingress.DetailView(&startTime, cfg, rm, ns, name, ing, evs, nss, detailManifest(r, ing)),
+				)
+			case "netpol":
+				np := core.GetNetworkPolicy(store, ns, name)
+				if serveManifest(w, r, np) {
+					return
+				}
+				pds := networkPolicyPods(store, np)
+				err = render(
+					r.Context(), w, "networkpolicy-detail",
+					networkpolicy.DetailView(&startTime, cfg, rm, ns, name, np, pds, nss, detailManifest(r, np)),
+				)
+			case "pvc":
+				pvc := core.GetPersistentVolumeClaim(store, ns, name)
+				if serveManifest(w, r, pvc) {
+					return
+				}
+				pds := core.GetPodsUsingClaim(store, ns, name)
+				evs := core.GetEventsRegarding(store, "PersistentVolumeClaim", ns, name)
+				err = render(
+					r.Context(), w, "persistentvolumeclaim-detail",
+					persistentvolumeclaim.DetailView(&startTime, cfg, rm, ns, name, pvc, pds, evs, nss, detailManifest(r, pvc)),
+				)
+			case "cm":
+				cm := core.GetConfigMap(store, ns, name)
+				if serveManifest(w, r, cm) {
+					return
+				}
+				wls := core.GetWorkloadsUsingConfigMap(store, ns, name)
+				pds := core.GetPodsUsingConfigMap(store, ns, name)
+				err = render(
+					r.Context(), w, "configmap-detail",
+					configmap.DetailView(&startTime, cfg, rm, ns, name, cm, wls, pds, nss, detailManifest(r, cm)),
+				)
+			case "secret":
+				s := core.GetSecret(store, ns, name)
+				if serveManifest(w, r, s) {
+					return
+				}
+				pds := core.GetPodsUsingSecret(store, ns, name)
+				err = render(
+					r.Context(), w, "secret-detail",
+					secret.DetailView(&startTime, cfg, rm, ns, name, s, pds, nss, detailManifest(r, s)),
+				)
+			case "sa":
+				sa := core.GetServiceAccount(store, ns, name)
+				if serveManifest(w, r, sa) {
+					return
+				}
+				grants := serviceAccountGrants(store, sa)
+				pds := core.GetPodsOfServiceAccount(store, ns, name)
+				err = render(
+					r.Context(), w, "serviceaccount-detail",
+					serviceaccount.DetailView(&startTime, cfg, rm, ns, name, sa, grants, pds, nss, detailManifest(r, sa)),
+				)
+			case "role":
+				rl := core.GetRole(store, ns, name)
+				if serveManifest(w, r, rl) {
+					return
+				}
+				rbs := core.GetRoleBindingsOfRole(store, ns, "Role", name)
+				err = render(
+					r.Context(), w, "role-detail",
+					role.DetailView(&startTime, cfg, rm, ns, name, rl, rbs, nss, detailManifest(r, rl)),
+				)
+			case "rolebinding":
+				rb := core.GetRoleBinding(store, ns, name)
+				if serveManifest(w, r, rb) {
+					return
+				}
+				rules := roleBindingRules(store, rb)
+				err = render(
+					r.Context(), w, "rolebinding-detail",
+					rolebinding.DetailView(&startTime, cfg, rm, ns, name, rb, rules, nss, detailManifest(r, rb)),
+				)
+			default:
+				crt, ok := core.GetCustomResourceType(store, res)
+				if !ok || !crt.Namespaced {
+					http.NotFound(w, r)
+					return
+				}
+				cr := core.GetCustomResource(store, crt, ns, name)
+				if serveManifest(w, r, cr) {
+					return
+				}
+				err = render(
+					r.Context(), w, "customresource-detail",
+					customresource.DetailView(&startTime, cfg, rm, ns, name, crt, cr, nss, detailManifest(r, cr)),
+				)
 			}
 
 			if err != nil {
@@ -63,3 +280,57 @@ func Resource(
 		},
 	)
 }
+
+// deploymentPods returns the pods of a deployment, which may not exist.
+func deploymentPods(store store.Store, deploy *appsv1.Deployment) []*corev1.Pod {
+	if deploy == nil {
+		return nil
+	}
+	return core.GetDeploymentPods(store, deploy)
+}
+
+// cronJobPods returns the pods of the jobs of a cron job, which may not exist.
+func cronJobPods(store store.Store, cj *batchv1.CronJob) []*corev1.Pod {
+	if cj == nil {
+		return nil
+	}
+	return core.GetCronJobPods(store, cj)
+}
+
+// servicePods returns the pods selected by a service, which may not exist.
+func servicePods(store store.Store, svc *corev1.Service) []*corev1.Pod {
+	if svc == nil || len(svc.Spec.Selector) == 0 {
+		return nil
+	}
+	return core.GetPodsSelectedBy(store, svc.Namespace, svc.Spec.Selector)
+}
+
+// networkPolicyPods returns the pods a network policy applies to, which may
+// not exist.
+func networkPolicyPods(store store.Store, np *networkingv1.NetworkPolicy) []*corev1.Pod {
+	if np == nil {
+		return nil
+	}
+	return core.GetPodsMatching(store, np.Namespace, &np.Spec.PodSelector)
+}
+
+// ownerObject is a stored object that owns others, as returned by the core
+// getters, which return nil if it doesn't exist.
+type ownerObject interface {
+	comparable
+	metav1.Object
+}
+
+// ownedBy returns the objects lookup finds for the owner obj, which may not
+// exist.
+func ownedBy[T ownerObject, R any](
+	store store.Store,
+	obj T,
+	lookup func(store store.Store, ns string, uid types.UID) []R,
+) []R {
+	var zero T
+	if obj == zero {
+		return nil
+	}
+	return lookup(store, obj.GetNamespace(), obj.GetUID())
+}
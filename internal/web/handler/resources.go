@@ -9,9 +9,23 @@ import (
 	"polar-bear/internal/core"
 	"polar-bear/internal/runtimemeta"
 	"polar-bear/internal/store"
+	"polar-bear/internal/web/view/configmap"
+	"polar-bear/internal/web/view/cronjob"
+	"polar-bear/internal/web/view/customresource"
+	"polar-bear/internal/web/view/daemonset"
 	"polar-bear/internal/web/view/deployment"
+	"polar-bear/internal/web/view/endpointslice"
+	"polar-bear/internal/web/view/ingress"
+	"polar-bear/internal/web/view/job"
+	"polar-bear/internal/web/view/networkpolicy"
+	"polar-bear/internal/web/view/persistentvolumeclaim"
 	"polar-bear/internal/web/view/pod"
 	"polar-bear/internal/web/view/replicaset"
+	"polar-bear/internal/web/view/role"
+	"polar-bear/internal/web/view/rolebinding"
+	"polar-bear/internal/web/view/secret"
+	"polar-bear/internal/web/view/service"
+	"polar-bear/internal/web/view/serviceaccount"
 	"polar-bear/internal/web/view/statefulset"
 )
 
@@ -41,21 +55,128 @@ func Resources(
 				return
 			}
 
+			ctx, opts, err := listContext(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
 			nss := core.GetNamespaces(store)
 
 			switch res {
 			case "pd":
-				pds := core.GetPods(store, ns)
-				err = pod.ListView(&startTime, cfg, rm, ns, pds, nss).Render(r.Context(), w)
+				pds := core.FilterObjects(core.GetPods(store, ns), opts)
+				err = render(
+					ctx, w, "pod-list",
+					pod.ListView(&startTime, cfg, rm, ns, pds, nss),
+				)
 			case "rs":
-				rss := core.GetReplicaSets(store, ns)
-				err = replicaset.ListView(&startTime, cfg, rm, ns, rss, nss).Render(r.Context(), w)
+				rss := core.FilterObjects(core.GetReplicaSets(store, ns), opts)
+				err = render(
+					ctx, w, "replicaset-list",
+					replicaset.ListView(&startTime, cfg, rm, ns, rss, nss),
+				)
 			case "sts":
-				sts := core.GetStatefulSets(store, ns)
-				err = statefulset.ListView(&startTime, cfg, rm, ns, sts, nss).Render(r.Context(), w)
+				sts := core.FilterObjects(core.GetStatefulSets(store, ns), opts)
+				err = render(
+					ctx, w, "statefulset-list",
+					statefulset.ListView(&startTime, cfg, rm, ns, sts, nss),
+				)
+			case "ds":
+				dss := core.FilterObjects(core.GetDaemonSets(store, ns), opts)
+				err = render(
+					ctx, w, "daemonset-list",
+					daemonset.ListView(&startTime, cfg, rm, ns, dss, nss),
+				)
+			case "job":
+				jobs := core.FilterObjects(core.GetJobs(store, ns), opts)
+				err = render(
+					ctx, w, "job-list",
+					job.ListView(&startTime, cfg, rm, ns, jobs, nss),
+				)
+			case "cronjob":
+				cjs := core.FilterObjects(core.GetCronJobs(store, ns), opts)
+				err = render(
+					ctx, w, "cronjob-list",
+					cronjob.ListView(&startTime, cfg, rm, ns, cjs, nss),
+				)
 			case "deploy":
-				deploys := core.GetDeployments(store, ns)
-				err = deployment.ListView(&startTime, cfg, rm, ns, deploys, nss).Render(r.Context(), w)
+				deploys := core.FilterObjects(core.GetDeployments(store, ns), opts)
+				err = render(
+					ctx, w, "deployment-list",
+					deployment.ListView(&startTime, cfg, rm, ns, deploys, nss),
+				)
+			case "svc":
+				svcs := core.FilterObjects(core.GetServices(store, ns), opts)
+				err = render(
+					ctx, w, "service-list",
+					service.ListView(&startTime, cfg, rm, ns, svcs, nss),
+				)
+			case "epslice":
+				slices := core.FilterObjects(core.GetEndpointSlices(store, ns), opts)
+				err = render(
+					ctx, w, "endpointslice-list",
+					endpointslice.ListView(&startTime, cfg, rm, ns, slices, nss),
+				)
+			case "ing":
+				ings := core.FilterObjects(core.GetIngresses(store, ns), opts)
+				err = render(
+					ctx, w, "ingress-list",
+					ingress.ListView(&startTime, cfg, rm, ns, ings, nss),
+				)
+			case "netpol":
+				nps := core.FilterObjects(core.GetNetworkPolicies(store, ns), opts)
+				err = render(
+					ctx, w, "networkpolicy-list",
+					networkpolicy.ListView(&startTime, cfg, rm, ns, nps, nss),
+				)
+			case "pvc":
+				pvcs := core.FilterObjects(core.GetPersistentVolumeClaims(store, ns), opts)
+				err = render(
+					ctx, w, "persistentvolumeclaim-list",
+					persistentvolumeclaim.ListView(&startTime, cfg, rm, ns, pvcs, nss),
+				)
+			case "cm":
+				cms := core.FilterObjects(core.GetConfigMaps(store, ns), opts)
+				err = render(
+					ctx, w, "configmap-list",
+					configmap.ListView(&startTime, cfg, rm, ns, cms, nss),
+				)
+			case "secret":
+				secrets := core.FilterObjects(core.GetSecrets(store, ns), opts)
+				err = render(
+					ctx, w, "secret-list",
+					secret.ListView(&startTime, cfg, rm, ns, secrets, nss),
+				)
+			case "sa":
+				sas := core.FilterObjects(core.GetServiceAccounts(store, ns), opts)
+				err = render(
+					ctx, w, "serviceaccount-list",
+					serviceaccount.ListView(&startTime, cfg, rm, ns, sas, nss),
+				)
+			case "role":
+				roles := core.FilterObjects(core.GetRoles(store, ns), opts)
+				err = render(
+					ctx, w, "role-list",
+					role.ListView(&startTime, cfg, rm, ns, roles, nss),
+				)
+			case "rolebinding":
+				rbs := core.FilterObjects(core.GetRoleBindings(store, ns), opts)
+				err = render(
+					ctx, w, "rolebinding-list",
+					rolebinding.ListView(&startTime, cfg, rm, ns, rbs, nss),
+				)
+			default:
+				crt, ok := core.GetCustomResourceType(store, res)
+				if !ok || !crt.Namespaced {
+					http.NotFound(w, r)
+					return
+				}
+				crs := core.FilterObjects(core.GetCustomResources(store, crt, ns), opts)
+				err = render(
+					ctx, w, "customresource-list",
+					customresource.ListView(&startTime, cfg, rm, ns, crt, crs, nss),
+				)
 			}
 
 			if err != nil {
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"polar-bear/internal/config"
+	"polar-bear/internal/core"
+	"polar-bear/internal/runtimemeta"
+	"polar-bear/internal/store"
+	"polar-bear/internal/web/view/search"
+)
+
+// searchResultsLimit is the number of results per kind shown below the
+// search box.
+const searchResultsLimit = 5
+
+// Search shows all objects matching the q parameter.
+func Search(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			query := r.URL.Query().Get("q")
+			groups := core.Search(store, query, 0)
+			nss := core.GetNamespaces(store)
+
+			err = render(r.Context(), w, "search", search.View(&startTime, cfg, rm, query, groups, nss))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+// HTMXSearch renders the results shown below the search box while typing.
+func HTMXSearch(store store.Store) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query().Get("q")
+			groups := core.Search(store, query, searchResultsLimit)
+
+			err := render(r.Context(), w, "search-results", search.Results(query, groups))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
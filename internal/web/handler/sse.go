@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"polar-bear/internal/event"
+	"polar-bear/internal/store"
+)
+
+// EventStream sends the same updates as Websocket as server-sent events, for
+// clients behind proxies that don't pass websocket upgrades.
+func EventStream(
+	ed event.Distribution,
+	store store.Store,
+) http.Handler {
+	logger := slog.With("component", "handler-sse")
+
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			sub, err := parseSubscription(r)
+			if err != nil {
+				logger.Error(
+					"error parsing subscription",
+					"error", err,
+				)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			view, ok := findLiveView(store, sub)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+
+			rc := http.NewResponseController(w)
+			write := func(msg []byte) error {
+				// The server's write timeout would end the stream otherwise
+				_ = rc.SetWriteDeadline(time.Now().Add(writeWait))
+				if _, err := w.Write(msg); err != nil {
+					return err
+				}
+				return rc.Flush()
+			}
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.WriteHeader(http.StatusOK)
+			if err := write([]byte(": connected\n\n")); err != nil {
+				logger.Error("unable to start event stream", "err", err)
+				return
+			}
+
+			logger.Debug(
+				"event stream established",
+				"kind", sub.Kind,
+				"ns", sub.Namespace,
+				"name", sub.Name,
+			)
+
+			updates := ed.Subscribe(liveTopics(sub)...)
+			defer ed.Unsubscribe(updates)
+
+			stream := liveStream{
+				logger: logger,
+				send: func(msg []byte) error {
+					return write(eventMessage(msg))
+				},
+				keepAlive: func() error {
+					return write([]byte(": ping\n\n"))
+				},
+			}
+			stream.run(r.Context(), updates, sub, view, store)
+		},
+	)
+}
+
+// eventMessage frames msg as a server-sent event, every line of it is sent
+// in its own data field.
+func eventMessage(msg []byte) []byte {
+	var buf bytes.Buffer
+	for line := range bytes.Lines(msg) {
+		fmt.Fprintf(&buf, "data: %s\n", bytes.TrimRight(line, "\r\n"))
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+
+	"polar-bear/internal/config"
+	"polar-bear/internal/core"
+	"polar-bear/internal/runtimemeta"
+	"polar-bear/internal/store"
+	"polar-bear/internal/web/view/csidriver"
+	"polar-bear/internal/web/view/persistentvolume"
+	"polar-bear/internal/web/view/storageclass"
+)
+
+func PersistentVolumes(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			ctx, opts, err := listContext(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			pvs := core.FilterObjects(core.GetPersistentVolumes(store), opts)
+			nss := core.GetNamespaces(store)
+
+			err = render(ctx, w, "persistentvolume-list", persistentvolume.ListView(&startTime, cfg, rm, pvs, nss))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func PersistentVolume(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			name, err := url.QueryUnescape(r.PathValue("pv"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+
+			pv := core.GetPersistentVolume(store, name)
+			if serveManifest(w, r, pv) {
+				return
+			}
+
+			vas := core.GetVolumeAttachmentsOfVolume(store, name)
+			evs := core.GetEventsRegarding(store, "PersistentVolume", "", name)
+			nss := core.GetNamespaces(store)
+
+			err = render(
+				r.Context(), w, "persistentvolume-detail",
+				persistentvolume.DetailView(&startTime, cfg, rm, name, pv, vas, evs, nss, detailManifest(r, pv)),
+			)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func StorageClasses(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			ctx, opts, err := listContext(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			scs := core.FilterObjects(core.GetStorageClasses(store), opts)
+			nss := core.GetNamespaces(store)
+
+			err = render(ctx, w, "storageclass-list", storageclass.ListView(&startTime, cfg, rm, scs, nss))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func StorageClass(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			name, err := url.QueryUnescape(r.PathValue("sc"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+
+			sc := core.GetStorageClass(store, name)
+			if serveManifest(w, r, sc) {
+				return
+			}
+
+			driver := storageClassDriver(store, sc)
+			pvs := core.GetPersistentVolumesOfClass(store, name)
+			cscs := core.GetCSIStorageCapacitiesOfClass(store, name)
+			nss := core.GetNamespaces(store)
+
+			err = render(
+				r.Context(), w, "storageclass-detail",
+				storageclass.DetailView(&startTime, cfg, rm, name, sc, driver, pvs, cscs, nss, detailManifest(r, sc)),
+			)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func CSIDrivers(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			ctx, opts, err := listContext(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			drivers := core.FilterObjects(core.GetCSIDrivers(store), opts)
+			nss := core.GetNamespaces(store)
+
+			err = render(ctx, w, "csidriver-list", csidriver.ListView(&startTime, cfg, rm, drivers, nss))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+func CSIDriver(
+	cfg *config.Config,
+	rm *runtimemeta.RuntimeMeta,
+	store store.Store,
+) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := r.Header.Get("X-Request-Time")
+			startTime, err := time.Parse(time.RFC3339Nano, start)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			name, err := url.QueryUnescape(r.PathValue("csidriver"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+
+			driver := core.GetCSIDriver(store, name)
+			if serveManifest(w, r, driver) {
+				return
+			}
+
+			cns := core.GetCSINodesWithDriver(store, name)
+			scs := core.GetStorageClassesOfProvisioner(store, name)
+			vas := core.GetVolumeAttachmentsOfAttacher(store, name)
+			nss := core.GetNamespaces(store)
+
+			err = render(
+				r.Context(), w, "csidriver-detail",
+				csidriver.DetailView(&startTime, cfg, rm, name, driver, cns, scs, vas, nss, detailManifest(r, driver)),
+			)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		},
+	)
+}
+
+// storageClassDriver returns the CSI driver provisioning the volumes of a
+// storage class, nil if the class doesn't exist or uses another provisioner.
+func storageClassDriver(store store.Store, sc *storagev1.StorageClass) *storagev1.CSIDriver {
+	if sc == nil {
+		return nil
+	}
+	return core.GetCSIDriver(store, sc.Provisioner)
+}
+
+// podClaims returns the persistent volume claims of the volumes of a pod,
+// which may not exist, by name.
+func podClaims(store store.Store, pd *corev1.Pod) map[string]*corev1.PersistentVolumeClaim {
+	if pd == nil {
+		return nil
+	}
+	claims := make(map[string]*corev1.PersistentVolumeClaim)
+	for _, v := range pd.Spec.Volumes {
+		name := core.ClaimName(pd, v)
+		if name == "" {
+			continue
+		}
+		if pvc := core.GetPersistentVolumeClaim(store, pd.Namespace, name); pvc != nil {
+			claims[name] = pvc
+		}
+	}
+	return claims
+}
@@ -1,19 +1,15 @@
 package handler
 
 import (
-	"bytes"
 	"context"
 	"log/slog"
 	"net/http"
-	"net/url"
 	"time"
 
 	"github.com/gorilla/websocket"
 
-	"polar-bear/internal/core"
 	"polar-bear/internal/event"
 	"polar-bear/internal/store"
-	"polar-bear/internal/web/view/pod"
 )
 
 const (
@@ -40,13 +36,19 @@ func Websocket(
 
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
-			nsName, err := url.QueryUnescape(r.PathValue("ns"))
+			sub, err := parseSubscription(r)
 			if err != nil {
 				logger.Error(
-					"error decoding namespace name",
+					"error parsing subscription",
 					"error", err,
 				)
-				http.Error(w, err.Error(), http.StatusNotAcceptable)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			view, ok := findLiveView(store, sub)
+			if !ok {
+				http.NotFound(w, r)
 				return
 			}
 
@@ -56,70 +58,51 @@ func Websocket(
 				return
 			}
 
-			logger.Debug("websocket connection established", "ns", nsName)
+			logger.Debug(
+				"websocket connection established",
+				"kind", sub.Kind,
+				"ns", sub.Namespace,
+				"name", sub.Name,
+			)
 
-			updates := make(chan string)
-			ed.Register(updates)
+			updates := ed.Subscribe(liveTopics(sub)...)
+			ctx, cancel := context.WithCancel(r.Context())
 
 			defer func() {
-				ed.Unregister(updates)
+				ed.Unsubscribe(updates)
 				conn.Close()
-				close(updates)
+				cancel()
 			}()
 
-			go writer(logger, conn, updates, nsName, store)
+			go writer(ctx, logger, conn, updates, sub, view, store)
 			reader(conn)
 		},
 	)
 }
 
 func writer(
+	ctx context.Context,
 	logger *slog.Logger,
 	ws *websocket.Conn,
-	updates chan string,
-	nsName string,
+	updates *event.Subscription,
+	sub subscription,
+	view liveView,
 	store store.Store,
 ) {
-	pingTicker := time.NewTicker(pingPeriod)
-	var buf bytes.Buffer
-
-	defer func() {
-		pingTicker.Stop()
-		ws.Close()
-	}()
-
-	for {
-		select {
-		case update := <-updates:
-			if update == "" {
-				return
-			}
-			logger.Info("got pod update", "key", update)
-
-			podInfos := core.GetPods(store, nsName)
-			tc := pod.PodList("", podInfos, "outerHTML")
-
-			buf.Reset()
-			err := tc.Render(context.Background(), &buf)
-			if err != nil {
-				logger.Error("unable to render template", "err", err)
-				return
-			}
+	defer ws.Close()
 
-			err = ws.WriteMessage(websocket.TextMessage, buf.Bytes())
-			if err != nil {
-				logger.Error("unable to write buffer to websocket", "err", err)
-				return
-			}
-		case <-pingTicker.C:
-			logger.Debug("send ping")
+	stream := liveStream{
+		logger: logger,
+		send: func(msg []byte) error {
 			_ = ws.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := ws.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
-				logger.Error("unable to write ping to websocket", "err", err)
-				return
-			}
-		}
+			return ws.WriteMessage(websocket.TextMessage, msg)
+		},
+		keepAlive: func() error {
+			_ = ws.SetWriteDeadline(time.Now().Add(writeWait))
+			return ws.WriteMessage(websocket.PingMessage, []byte{})
+		},
 	}
+	stream.run(ctx, updates, sub, view, store)
 }
 
 func reader(ws *websocket.Conn) {
@@ -0,0 +1,63 @@
+package capacity
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/web/view/shared"
+)
+
+// FormatQuantity formats an amount of a resource, bytes for memory and
+// storage.
+func FormatQuantity(name corev1.ResourceName, q resource.Quantity) string {
+	if isBytes(name) {
+		return shared.ToHumanReadableBytes(q.Value())
+	}
+	return q.String()
+}
+
+// FormatAmount formats the amount of a resource a pod requests or limits, "-"
+// if it doesn't set it.
+func FormatAmount(name corev1.ResourceName, list corev1.ResourceList) string {
+	q, ok := list[name]
+	if !ok {
+		return "-"
+	}
+	return FormatQuantity(name, q)
+}
+
+// FormatShare formats an amount with its share of the allocatable amount, as
+// in "1500m (38%)".
+func FormatShare(name corev1.ResourceName, q resource.Quantity, ratio float64, ok bool) string {
+	if !ok {
+		return FormatQuantity(name, q)
+	}
+	return fmt.Sprintf("%s (%.0f%%)", FormatQuantity(name, q), ratio*100)
+}
+
+// FormatOvercommit formats how far the limits exceed the allocatable amount,
+// as in "1.8x", or "none" if they fit.
+func FormatOvercommit(a core.Allocation) string {
+	ratio, ok := a.LimitsRatio()
+	if !ok || ratio <= 1 {
+		return "none"
+	}
+	return fmt.Sprintf("%.1fx", ratio)
+}
+
+// MeterValue returns the requested share of a resource for a meter element,
+// which clamps values above its maximum.
+func MeterValue(a core.Allocation) string {
+	ratio, _ := a.RequestsRatio()
+	return fmt.Sprintf("%.3f", ratio)
+}
+
+func isBytes(name corev1.ResourceName) bool {
+	return name == corev1.ResourceMemory ||
+		name == corev1.ResourceEphemeralStorage ||
+		strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix)
+}
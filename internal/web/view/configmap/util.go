@@ -0,0 +1,84 @@
+package configmap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// MaxValueSize is the number of bytes of a value shown before it is cut off,
+// config maps may hold up to 1 MiB.
+const MaxValueSize = 4096
+
+// Value is a key of a config map with the part of its value that is shown.
+type Value struct {
+	Key  string
+	Size int
+	// Binary values are not shown, they come from binaryData or aren't text
+	Binary    bool
+	Text      string
+	Truncated bool
+}
+
+// Values returns the keys of data and binaryData of a config map sorted by
+// name.
+func Values(cm *corev1.ConfigMap) []Value {
+	values := make([]Value, 0, len(cm.Data)+len(cm.BinaryData))
+	for key, data := range cm.Data {
+		values = append(values, textValue(key, data))
+	}
+	for key, data := range cm.BinaryData {
+		values = append(values, Value{Key: key, Size: len(data), Binary: true})
+	}
+	slices.SortFunc(values, func(a, b Value) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return values
+}
+
+func textValue(key string, data string) Value {
+	if isBinary(data) {
+		return Value{Key: key, Size: len(data), Binary: true}
+	}
+	text, truncated := truncate(data, MaxValueSize)
+	return Value{Key: key, Size: len(data), Text: text, Truncated: truncated}
+}
+
+// isBinary reports whether data isn't valid UTF-8 or contains control
+// characters other than whitespace.
+func isBinary(data string) bool {
+	if !utf8.ValidString(data) {
+		return true
+	}
+	return strings.ContainsFunc(data, func(r rune) bool {
+		return unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t'
+	})
+}
+
+// truncate cuts data to at most size bytes without splitting a rune.
+func truncate(data string, size int) (string, bool) {
+	if len(data) <= size {
+		return data, false
+	}
+	for size > 0 && !utf8.RuneStart(data[size]) {
+		size--
+	}
+	return data[:size], true
+}
+
+// KeyCount returns the number of keys of a config map.
+func KeyCount(cm *corev1.ConfigMap) int {
+	return len(cm.Data) + len(cm.BinaryData)
+}
+
+// FormatSize returns the size of a value in bytes.
+func FormatSize(size int) string {
+	if size == 1 {
+		return "1 byte"
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
@@ -0,0 +1,118 @@
+package configmap
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"empty", "", false},
+		{"text", "key=value", false},
+		{"whitespace", "a\tb\r\nc\n", false},
+		{"multi-byte runes", "grüße 日本 🐻‍❄️", false},
+		{"null byte", "a\x00b", true},
+		{"escape", "\x1b[31mred", true},
+		{"delete", "a\x7f", true},
+		{"C1 control", "a\u0085b", true},
+		{"invalid UTF-8", "a\xffb", true},
+		{"cut rune", "日本"[:4], true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBinary(tt.data); got != tt.want {
+				t.Errorf("isBinary(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		size          int
+		want          string
+		wantTruncated bool
+	}{
+		{"shorter", "abc", 4, "abc", false},
+		{"exact size", "abcd", 4, "abcd", false},
+		{"longer", "abcdef", 4, "abcd", true},
+		{"at a rune boundary", "ab日本", 5, "ab日", true},
+		{"inside a two byte rune", "abcü", 4, "abc", true},
+		{"inside a three byte rune", "ab日本", 4, "ab", true},
+		{"after the first byte of a rune", "ab日本", 3, "ab", true},
+		{"inside a four byte rune", "a🐻b", 4, "a", true},
+		{"inside the first rune", "🐻", 2, "", true},
+		{"zero size", "abc", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := truncate(tt.data, tt.size)
+			if got != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("truncate(%q, %d) = %q, %v, want %q, %v", tt.data, tt.size, got, truncated, tt.want, tt.wantTruncated)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncate(%q, %d) split a rune: %q", tt.data, tt.size, got)
+			}
+			if len(got) > tt.size {
+				t.Errorf("truncate(%q, %d) returned %d bytes", tt.data, tt.size, len(got))
+			}
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	long := strings.Repeat("ü", MaxValueSize) // two bytes each
+
+	cm := &corev1.ConfigMap{
+		Data: map[string]string{
+			"b.conf":   "x=1\n",
+			"a.txt":    long,
+			"c.bin":    "\x00\x01",
+			"d.script": "",
+		},
+		BinaryData: map[string][]byte{
+			"logo.png": {0x89, 'P', 'N', 'G'},
+		},
+	}
+
+	values := Values(cm)
+
+	var keys []string
+	for _, v := range values {
+		keys = append(keys, v.Key)
+	}
+	if want := []string{"a.txt", "b.conf", "c.bin", "d.script", "logo.png"}; !slices.Equal(keys, want) {
+		t.Fatalf("got keys %v, want %v", keys, want)
+	}
+
+	if v := values[0]; !v.Truncated || len(v.Text) != MaxValueSize || v.Size != 2*MaxValueSize {
+		t.Errorf("got long value of %d bytes, size %d, truncated %v", len(v.Text), v.Size, v.Truncated)
+	}
+	if v := values[1]; v.Binary || v.Truncated || v.Text != "x=1\n" || v.Size != 4 {
+		t.Errorf("got text value %+v", v)
+	}
+	if v := values[2]; !v.Binary || v.Text != "" || v.Size != 2 {
+		t.Errorf("got binary data value %+v", v)
+	}
+	if v := values[3]; v.Binary || v.Text != "" || v.Size != 0 {
+		t.Errorf("got empty value %+v", v)
+	}
+	if v := values[4]; !v.Binary || v.Size != 4 {
+		t.Errorf("got binaryData value %+v", v)
+	}
+	if count := KeyCount(cm); count != 5 {
+		t.Errorf("counted %d keys, want 5", count)
+	}
+}
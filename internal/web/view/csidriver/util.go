@@ -0,0 +1,92 @@
+package csidriver
+
+import (
+	"fmt"
+	"strings"
+
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// NodeDriver is the installation of a CSI driver on a node.
+type NodeDriver struct {
+	Node         string
+	NodeID       string
+	Allocatable  string
+	TopologyKeys []string
+}
+
+// NodeDrivers returns the installations of the driver name on the given CSI
+// nodes.
+func NodeDrivers(cns []*storagev1.CSINode, name string) []NodeDriver {
+	nds := make([]NodeDriver, 0, len(cns))
+	for _, cn := range cns {
+		for _, d := range cn.Spec.Drivers {
+			if d.Name != name {
+				continue
+			}
+			allocatable := "unlimited"
+			if d.Allocatable != nil && d.Allocatable.Count != nil {
+				allocatable = fmt.Sprintf("%d volumes", *d.Allocatable.Count)
+			}
+			nds = append(nds, NodeDriver{
+				Node:         cn.Name,
+				NodeID:       d.NodeID,
+				Allocatable:  allocatable,
+				TopologyKeys: d.TopologyKeys,
+			})
+		}
+	}
+	return nds
+}
+
+// FormatLifecycleModes returns the volume lifecycle modes of a driver, which
+// default to persistent volumes only.
+func FormatLifecycleModes(modes []storagev1.VolumeLifecycleMode) string {
+	if len(modes) == 0 {
+		return string(storagev1.VolumeLifecyclePersistent)
+	}
+	names := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		names = append(names, string(mode))
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatAudiences returns the audiences of the service account tokens a
+// driver requests.
+func formatAudiences(reqs []storagev1.TokenRequest) string {
+	if len(reqs) == 0 {
+		return "-"
+	}
+	auds := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		auds = append(auds, req.Audience)
+	}
+	return strings.Join(auds, ", ")
+}
+
+// attachmentSource returns the volume a volume attachment attaches.
+func attachmentSource(va *storagev1.VolumeAttachment) string {
+	switch {
+	case va.Spec.Source.PersistentVolumeName != nil:
+		return *va.Spec.Source.PersistentVolumeName
+	case va.Spec.Source.InlineVolumeSpec != nil:
+		return "inline"
+	default:
+		return "-"
+	}
+}
+
+func boolOrDefault(value *bool, def bool) string {
+	if value == nil {
+		return fmt.Sprintf("%t", def)
+	}
+	return fmt.Sprintf("%t", *value)
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package customresource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+
+	"polar-bear/internal/core"
+)
+
+// listColumns returns the printer columns kubectl shows without "-o wide".
+func listColumns(crt core.CustomResourceType) []apiextensionsv1.CustomResourceColumnDefinition {
+	cols := make([]apiextensionsv1.CustomResourceColumnDefinition, 0, len(crt.Columns))
+	for _, col := range crt.Columns {
+		if col.Priority == 0 {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+func columnValue(obj *unstructured.Unstructured, col apiextensionsv1.CustomResourceColumnDefinition) string {
+	jp := jsonpath.New(col.Name).AllowMissingKeys(true)
+	if err := jp.Parse(fmt.Sprintf("{%s}", col.JSONPath)); err != nil {
+		return "<invalid>"
+	}
+
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, obj.Object); err != nil {
+		return "<error>"
+	}
+
+	value := strings.TrimSpace(buf.String())
+	if value == "" {
+		return "-"
+	}
+
+	if col.Type == "date" {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return value
+}
+
+func prettyJSON(obj *unstructured.Unstructured, field string) string {
+	value, ok := obj.Object[field]
+	if !ok {
+		return ""
+	}
+
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("unable to format %s: %v", field, err)
+	}
+	return string(out)
+}
+
+func scope(crt core.CustomResourceType) string {
+	if crt.Namespaced {
+		return "Namespaced"
+	}
+	return "Cluster"
+}
@@ -0,0 +1,91 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// Hosts returns the hosts of the rules of an ingress like kubectl get ing.
+func Hosts(ing *networkingv1.Ingress) string {
+	hosts := make([]string, 0, len(ing.Spec.Rules))
+	for _, rule := range ing.Spec.Rules {
+		hosts = append(hosts, hostOrAny(rule.Host))
+	}
+	if len(hosts) == 0 {
+		return "*"
+	}
+	return strings.Join(hosts, ",")
+}
+
+// Class returns the ingress class of an ingress, or "" if it uses the default.
+func Class(ing *networkingv1.Ingress) string {
+	if ing.Spec.IngressClassName != nil {
+		return *ing.Spec.IngressClassName
+	}
+	// Deprecated, but still used by older controllers
+	return ing.Annotations["kubernetes.io/ingress.class"]
+}
+
+// backendKind returns the kind of object a backend sends traffic to.
+func backendKind(b networkingv1.IngressBackend) string {
+	if b.Resource != nil {
+		return b.Resource.Kind
+	}
+	return "Service"
+}
+
+// backendName returns the name of the object a backend sends traffic to.
+func backendName(b networkingv1.IngressBackend) string {
+	if b.Resource != nil {
+		return b.Resource.Name
+	}
+	if b.Service != nil {
+		return b.Service.Name
+	}
+	return ""
+}
+
+// backendPort returns the service port of a backend by name or number.
+func backendPort(b networkingv1.IngressBackend) string {
+	if b.Service == nil {
+		return ""
+	}
+	if b.Service.Port.Name != "" {
+		return b.Service.Port.Name
+	}
+	return fmt.Sprintf("%d", b.Service.Port.Number)
+}
+
+func pathType(path networkingv1.HTTPIngressPath) string {
+	if path.PathType == nil {
+		return ""
+	}
+	return string(*path.PathType)
+}
+
+func loadBalancerAddresses(ingress []networkingv1.IngressLoadBalancerIngress) string {
+	addrs := make([]string, 0, len(ingress))
+	for _, ing := range ingress {
+		addrs = append(addrs, ing.IP+ing.Hostname)
+	}
+	if len(addrs) == 0 {
+		return "pending"
+	}
+	return strings.Join(addrs, ", ")
+}
+
+func hostOrAny(host string) string {
+	if host == "" {
+		return "*"
+	}
+	return host
+}
+
+func valueOrDefault(value string) string {
+	if value == "" {
+		return "default"
+	}
+	return value
+}
@@ -0,0 +1,147 @@
+package networkpolicy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"polar-bear/internal/web/view/workload"
+)
+
+// Rule is an ingress or egress rule of a network policy in words.
+type Rule struct {
+	Peers []string
+	Ports []string
+}
+
+// Direction is the ingress or egress part of a network policy.
+type Direction struct {
+	Title string
+	// Isolated is set if the policy restricts traffic in this direction, all
+	// traffic not allowed by a rule is denied then
+	Isolated bool
+	Rules    []Rule
+}
+
+// Directions returns the ingress and egress rules of a network policy.
+func Directions(np *networkingv1.NetworkPolicy) []Direction {
+	ingress := Direction{Title: "Ingress", Isolated: hasPolicyType(np, networkingv1.PolicyTypeIngress)}
+	for _, rule := range np.Spec.Ingress {
+		ingress.Rules = append(ingress.Rules, Rule{
+			Peers: describePeers(rule.From, "any source"),
+			Ports: describePorts(rule.Ports),
+		})
+	}
+
+	egress := Direction{Title: "Egress", Isolated: hasPolicyType(np, networkingv1.PolicyTypeEgress)}
+	for _, rule := range np.Spec.Egress {
+		egress.Rules = append(egress.Rules, Rule{
+			Peers: describePeers(rule.To, "any destination"),
+			Ports: describePorts(rule.Ports),
+		})
+	}
+
+	return []Direction{ingress, egress}
+}
+
+// PolicyTypes returns the directions a network policy applies to.
+func PolicyTypes(np *networkingv1.NetworkPolicy) string {
+	types := make([]string, 0, 2)
+	for _, t := range []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress} {
+		if hasPolicyType(np, t) {
+			types = append(types, string(t))
+		}
+	}
+	return strings.Join(types, ", ")
+}
+
+// hasPolicyType reports whether a policy isolates pods in a direction. Without
+// explicit policy types ingress is always isolated, egress only with rules.
+func hasPolicyType(np *networkingv1.NetworkPolicy, t networkingv1.PolicyType) bool {
+	if len(np.Spec.PolicyTypes) > 0 {
+		return slices.Contains(np.Spec.PolicyTypes, t)
+	}
+	if t == networkingv1.PolicyTypeEgress {
+		return len(np.Spec.Egress) > 0
+	}
+	return true
+}
+
+// DescribeSelector returns the pods a selector matches in words.
+func DescribeSelector(sel metav1.LabelSelector) string {
+	if len(sel.MatchLabels) == 0 && len(sel.MatchExpressions) == 0 {
+		return "all pods"
+	}
+	return "pods with " + formatSelector(&sel)
+}
+
+func describePeers(peers []networkingv1.NetworkPolicyPeer, any string) []string {
+	if len(peers) == 0 {
+		return []string{any}
+	}
+
+	res := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		res = append(res, describePeer(peer))
+	}
+	return res
+}
+
+func describePeer(peer networkingv1.NetworkPolicyPeer) string {
+	if peer.IPBlock != nil {
+		if len(peer.IPBlock.Except) > 0 {
+			return fmt.Sprintf("IP block %s except %s", peer.IPBlock.CIDR, strings.Join(peer.IPBlock.Except, ", "))
+		}
+		return "IP block " + peer.IPBlock.CIDR
+	}
+
+	pods := "all pods"
+	if peer.PodSelector != nil {
+		pods = DescribeSelector(*peer.PodSelector)
+	}
+	switch {
+	case peer.NamespaceSelector == nil:
+		return pods + " in this namespace"
+	case len(peer.NamespaceSelector.MatchLabels) == 0 && len(peer.NamespaceSelector.MatchExpressions) == 0:
+		return pods + " in all namespaces"
+	default:
+		return pods + " in namespaces with " + formatSelector(peer.NamespaceSelector)
+	}
+}
+
+func describePorts(ports []networkingv1.NetworkPolicyPort) []string {
+	if len(ports) == 0 {
+		return []string{"any port"}
+	}
+
+	res := make([]string, 0, len(ports))
+	for _, port := range ports {
+		protocol := "TCP"
+		if port.Protocol != nil {
+			protocol = string(*port.Protocol)
+		}
+		switch {
+		case port.Port == nil:
+			res = append(res, "any "+protocol+" port")
+		case port.EndPort != nil:
+			res = append(res, fmt.Sprintf("%s %s-%d", protocol, port.Port.String(), *port.EndPort))
+		default:
+			res = append(res, fmt.Sprintf("%s %s", protocol, port.Port.String()))
+		}
+	}
+	return res
+}
+
+func formatSelector(sel *metav1.LabelSelector) string {
+	parts := make([]string, 0, 2)
+	if len(sel.MatchLabels) > 0 {
+		parts = append(parts, workload.FormatMapString(sel.MatchLabels))
+	}
+	if len(sel.MatchExpressions) > 0 {
+		parts = append(parts, workload.FormatLabelSelectorRequirements(sel.MatchExpressions))
+	}
+	return strings.Join(parts, ", ")
+}
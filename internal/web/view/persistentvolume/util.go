@@ -0,0 +1,140 @@
+package persistentvolume
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SourceProperty is a setting of the backing storage of a persistent volume.
+type SourceProperty struct {
+	Name  string
+	Value string
+}
+
+// Source returns the type of storage backing a persistent volume and its most
+// relevant settings. CSI volumes are shown with a link to their driver and are
+// not described here.
+func Source(src corev1.PersistentVolumeSource) (string, []SourceProperty) {
+	switch {
+	case src.CSI != nil:
+		return "CSI", []SourceProperty{
+			{"Volume Handle", src.CSI.VolumeHandle},
+			{"FS Type", valueOrDash(src.CSI.FSType)},
+			{"Read Only", fmt.Sprintf("%t", src.CSI.ReadOnly)},
+		}
+	case src.NFS != nil:
+		return "NFS", []SourceProperty{
+			{"Server", src.NFS.Server},
+			{"Path", src.NFS.Path},
+			{"Read Only", fmt.Sprintf("%t", src.NFS.ReadOnly)},
+		}
+	case src.HostPath != nil:
+		hostPathType := "-"
+		if src.HostPath.Type != nil && *src.HostPath.Type != "" {
+			hostPathType = string(*src.HostPath.Type)
+		}
+		return "Host Path", []SourceProperty{
+			{"Path", src.HostPath.Path},
+			{"Type", hostPathType},
+		}
+	case src.Local != nil:
+		fsType := "-"
+		if src.Local.FSType != nil && *src.Local.FSType != "" {
+			fsType = *src.Local.FSType
+		}
+		return "Local", []SourceProperty{
+			{"Path", src.Local.Path},
+			{"FS Type", fsType},
+		}
+	case src.ISCSI != nil:
+		return "iSCSI", []SourceProperty{
+			{"Target Portal", src.ISCSI.TargetPortal},
+			{"IQN", src.ISCSI.IQN},
+			{"LUN", fmt.Sprintf("%d", src.ISCSI.Lun)},
+		}
+	case src.FC != nil:
+		return "Fibre Channel", []SourceProperty{
+			{"Target WWNs", strings.Join(src.FC.TargetWWNs, ", ")},
+		}
+	case src.CephFS != nil:
+		return "CephFS", []SourceProperty{
+			{"Monitors", strings.Join(src.CephFS.Monitors, ", ")},
+			{"Path", valueOrDash(src.CephFS.Path)},
+		}
+	case src.RBD != nil:
+		return "Ceph RBD", []SourceProperty{
+			{"Monitors", strings.Join(src.RBD.CephMonitors, ", ")},
+			{"Pool", src.RBD.RBDPool},
+			{"Image", src.RBD.RBDImage},
+		}
+	case src.AWSElasticBlockStore != nil:
+		return "AWS EBS", []SourceProperty{
+			{"Volume ID", src.AWSElasticBlockStore.VolumeID},
+		}
+	case src.GCEPersistentDisk != nil:
+		return "GCE Persistent Disk", []SourceProperty{
+			{"PD Name", src.GCEPersistentDisk.PDName},
+		}
+	case src.AzureDisk != nil:
+		return "Azure Disk", []SourceProperty{
+			{"Disk Name", src.AzureDisk.DiskName},
+		}
+	case src.AzureFile != nil:
+		return "Azure File", []SourceProperty{
+			{"Share Name", src.AzureFile.ShareName},
+		}
+	default:
+		return "Other", nil
+	}
+}
+
+// FormatNodeAffinity returns the node selector terms a volume is restricted
+// to, none if it is reachable from all nodes.
+func FormatNodeAffinity(affinity *corev1.VolumeNodeAffinity) []string {
+	if affinity == nil || affinity.Required == nil {
+		return nil
+	}
+	terms := make([]string, 0, len(affinity.Required.NodeSelectorTerms))
+	for _, term := range affinity.Required.NodeSelectorTerms {
+		reqs := make([]string, 0, len(term.MatchExpressions)+len(term.MatchFields))
+		for _, req := range slices.Concat(term.MatchExpressions, term.MatchFields) {
+			reqs = append(reqs, fmt.Sprintf("%s %s (%s)", req.Key, req.Operator, strings.Join(req.Values, ", ")))
+		}
+		terms = append(terms, strings.Join(reqs, " and "))
+	}
+	return terms
+}
+
+// ClaimName returns the claim a volume is bound to as namespace/name, empty
+// if it isn't bound.
+func ClaimName(pv *corev1.PersistentVolume) string {
+	if pv.Spec.ClaimRef == nil {
+		return ""
+	}
+	return pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+}
+
+func phaseColor(phase corev1.PersistentVolumePhase) string {
+	switch phase {
+	case corev1.VolumeBound:
+		return "green"
+	case corev1.VolumeAvailable:
+		return "blue"
+	case corev1.VolumePending, corev1.VolumeReleased:
+		return "yellow"
+	case corev1.VolumeFailed:
+		return "red"
+	default:
+		return "gray"
+	}
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
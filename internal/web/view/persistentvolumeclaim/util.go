@@ -0,0 +1,78 @@
+package persistentvolumeclaim
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// accessModeNames are the abbreviations kubectl uses for access modes.
+var accessModeNames = map[corev1.PersistentVolumeAccessMode]string{
+	corev1.ReadWriteOnce:    "RWO",
+	corev1.ReadOnlyMany:     "ROX",
+	corev1.ReadWriteMany:    "RWX",
+	corev1.ReadWriteOncePod: "RWOP",
+}
+
+// FormatAccessModes returns the abbreviated access modes of a claim or volume.
+func FormatAccessModes(modes []corev1.PersistentVolumeAccessMode) string {
+	if len(modes) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		if name, ok := accessModeNames[mode]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, string(mode))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// FormatStorage returns the storage size of a resource list, or a dash if it
+// has none.
+func FormatStorage(list corev1.ResourceList) string {
+	size, ok := list[corev1.ResourceStorage]
+	if !ok {
+		return "-"
+	}
+	return size.String()
+}
+
+// FormatVolumeMode returns the volume mode, which defaults to a filesystem.
+func FormatVolumeMode(mode *corev1.PersistentVolumeMode) string {
+	if mode == nil {
+		return string(corev1.PersistentVolumeFilesystem)
+	}
+	return string(*mode)
+}
+
+// StorageClass returns the storage class a claim requests, empty if it uses
+// the default class or binds to a volume without a class.
+func StorageClass(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
+}
+
+func phaseColor(phase corev1.PersistentVolumeClaimPhase) string {
+	switch phase {
+	case corev1.ClaimBound:
+		return "green"
+	case corev1.ClaimPending:
+		return "yellow"
+	case corev1.ClaimLost:
+		return "red"
+	default:
+		return "gray"
+	}
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,59 @@
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// VolumeSource describes where the data of a pod volume comes from. Claims
+// are linked by the volumes panel and not described here.
+func VolumeSource(v corev1.Volume) string {
+	switch {
+	case v.ConfigMap != nil:
+		return "ConfigMap: " + v.ConfigMap.Name
+	case v.Secret != nil:
+		return "Secret: " + v.Secret.SecretName
+	case v.EmptyDir != nil:
+		if v.EmptyDir.Medium != "" {
+			return fmt.Sprintf("EmptyDir (%s)", v.EmptyDir.Medium)
+		}
+		return "EmptyDir"
+	case v.HostPath != nil:
+		return "HostPath: " + v.HostPath.Path
+	case v.Projected != nil:
+		return "Projected (" + projectedSources(v.Projected.Sources) + ")"
+	case v.DownwardAPI != nil:
+		return "DownwardAPI"
+	case v.CSI != nil:
+		return "CSI: " + v.CSI.Driver
+	case v.NFS != nil:
+		return fmt.Sprintf("NFS: %s:%s", v.NFS.Server, v.NFS.Path)
+	case v.Image != nil:
+		return "Image: " + v.Image.Reference
+	default:
+		return "Other"
+	}
+}
+
+func projectedSources(srcs []corev1.VolumeProjection) string {
+	kinds := make([]string, 0, len(srcs))
+	for _, src := range srcs {
+		switch {
+		case src.ServiceAccountToken != nil:
+			kinds = append(kinds, "ServiceAccountToken")
+		case src.ConfigMap != nil:
+			kinds = append(kinds, "ConfigMap "+src.ConfigMap.Name)
+		case src.Secret != nil:
+			kinds = append(kinds, "Secret "+src.Secret.Name)
+		case src.DownwardAPI != nil:
+			kinds = append(kinds, "DownwardAPI")
+		case src.ClusterTrustBundle != nil:
+			kinds = append(kinds, "ClusterTrustBundle")
+		default:
+			kinds = append(kinds, "Other")
+		}
+	}
+	return strings.Join(kinds, ", ")
+}
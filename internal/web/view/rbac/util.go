@@ -0,0 +1,108 @@
+package rbac
+
+import (
+	"context"
+	"strings"
+
+	"github.com/a-h/templ"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/web/view/shared"
+)
+
+// Query is a question asked to the evaluator, either what a subject can do
+// or who can perform a verb on a resource. An empty namespace asks about
+// cluster wide permissions.
+type Query struct {
+	Namespace string
+
+	SubjectKind string
+	Subject     string
+
+	Verb     string
+	Group    string
+	Resource string
+	Name     string
+}
+
+// SubjectKinds are the kinds of subjects bindings can refer to.
+var SubjectKinds = []string{rbacv1.UserKind, rbacv1.GroupKind, rbacv1.ServiceAccountKind}
+
+// FormatVerbs returns the verbs of a rule.
+func FormatVerbs(rule rbacv1.PolicyRule) string {
+	return strings.Join(rule.Verbs, ", ")
+}
+
+// FormatResources returns what a rule applies to, resources qualified by
+// their API group or non-resource URLs.
+func FormatResources(rule rbacv1.PolicyRule) string {
+	if len(rule.NonResourceURLs) > 0 {
+		return strings.Join(rule.NonResourceURLs, ", ")
+	}
+	resources := make([]string, 0, len(rule.Resources)*len(rule.APIGroups))
+	for _, group := range rule.APIGroups {
+		for _, res := range rule.Resources {
+			if group == "" {
+				resources = append(resources, res)
+			} else {
+				resources = append(resources, res+"."+group)
+			}
+		}
+	}
+	return strings.Join(resources, ", ")
+}
+
+// FormatResourceNames returns the objects a rule is restricted to, or a dash
+// if it applies to all of them.
+func FormatResourceNames(rule rbacv1.PolicyRule) string {
+	if len(rule.ResourceNames) == 0 {
+		return "-"
+	}
+	return strings.Join(rule.ResourceNames, ", ")
+}
+
+// FormatSubject returns a subject as namespace/name for service accounts and
+// its name otherwise.
+func FormatSubject(s rbacv1.Subject) string {
+	if s.Kind == rbacv1.ServiceAccountKind {
+		return s.Namespace + "/" + s.Name
+	}
+	return s.Name
+}
+
+// subjectNamespace returns the namespace of a subject of a binding in ns,
+// service accounts default to the namespace of the binding.
+func subjectNamespace(s rbacv1.Subject, ns string) string {
+	if s.Kind == rbacv1.ServiceAccountKind && s.Namespace == "" {
+		return ns
+	}
+	return s.Namespace
+}
+
+// roleLink returns the page of the role a binding in ns refers to.
+func roleLink(ctx context.Context, ns string, ref rbacv1.RoleRef) templ.SafeURL {
+	if ref.Kind == "ClusterRole" {
+		return shared.ClusterRoleLink(ctx, ref.Name)
+	}
+	return shared.RoleLink(ctx, ns, ref.Name)
+}
+
+// bindingLink returns the page of the binding of a grant.
+func bindingLink(ctx context.Context, grant core.Grant) templ.SafeURL {
+	if grant.BindingKind == "ClusterRoleBinding" {
+		return shared.ClusterRoleBindingLink(ctx, grant.BindingName)
+	}
+	return shared.RoleBindingLink(ctx, grant.BindingNamespace, grant.BindingName)
+}
+
+func kindColor(kind string) string {
+	switch kind {
+	case rbacv1.UserKind:
+		return "blue"
+	case rbacv1.GroupKind:
+		return "yellow"
+	default:
+		return "green"
+	}
+}
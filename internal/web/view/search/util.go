@@ -0,0 +1,22 @@
+package search
+
+import "polar-bear/internal/core"
+
+// Count returns the number of results of a search.
+func Count(groups []core.SearchGroup) int {
+	count := 0
+	for _, group := range groups {
+		count += group.Total
+	}
+	return count
+}
+
+// Limited reports whether some results of a search are left out.
+func Limited(groups []core.SearchGroup) bool {
+	for _, group := range groups {
+		if len(group.Results) < group.Total {
+			return true
+		}
+	}
+	return false
+}
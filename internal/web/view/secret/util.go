@@ -0,0 +1,30 @@
+package secret
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Type returns the type of a secret, which defaults to Opaque.
+func Type(s *corev1.Secret) string {
+	if s.Type == "" {
+		return string(corev1.SecretTypeOpaque)
+	}
+	return string(s.Type)
+}
+
+// FormatSize returns the size of a value in bytes.
+func FormatSize(size int) string {
+	if size == 1 {
+		return "1 byte"
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
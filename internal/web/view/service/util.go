@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// FormatPorts returns the ports of a service like kubectl get svc does,
+// e.g. 80:30080/TCP,443/TCP.
+func FormatPorts(ports []corev1.ServicePort) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+
+	parts := make([]string, 0, len(ports))
+	for _, port := range ports {
+		if port.NodePort != 0 {
+			parts = append(parts, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Endpoint is an address of a service, flattened from its endpoint slices.
+type Endpoint struct {
+	Slice       string
+	Addresses   []string
+	Ready       bool
+	Serving     bool
+	Terminating bool
+	Target      *corev1.ObjectReference
+	Node        string
+	Zone        string
+}
+
+// Endpoints returns the endpoints of all slices of a service.
+func Endpoints(slices []*discoveryv1.EndpointSlice) []Endpoint {
+	eps := make([]Endpoint, 0)
+	for _, slice := range slices {
+		for _, ep := range slice.Endpoints {
+			eps = append(eps, Endpoint{
+				Slice:       slice.Name,
+				Addresses:   ep.Addresses,
+				Ready:       isTrue(ep.Conditions.Ready, true),
+				Serving:     isTrue(ep.Conditions.Serving, isTrue(ep.Conditions.Ready, true)),
+				Terminating: isTrue(ep.Conditions.Terminating, false),
+				Target:      ep.TargetRef,
+				Node:        valueOrEmpty(ep.NodeName),
+				Zone:        valueOrEmpty(ep.Zone),
+			})
+		}
+	}
+	return eps
+}
+
+// CountReady returns the number of ready endpoints.
+func CountReady(eps []Endpoint) int {
+	ready := 0
+	for _, ep := range eps {
+		if ep.Ready {
+			ready++
+		}
+	}
+	return ready
+}
+
+// State returns the condition of an endpoint and the color of its badge.
+func (ep Endpoint) State() (string, string) {
+	switch {
+	case ep.Terminating:
+		return "Terminating", "gray"
+	case ep.Ready:
+		return "Ready", "green"
+	case ep.Serving:
+		return "Serving", "yellow"
+	default:
+		return "Not Ready", "red"
+	}
+}
+
+// SlicePorts returns the ports of an endpoint slice, e.g. http 8080/TCP.
+func SlicePorts(ports []discoveryv1.EndpointPort) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+
+	parts := make([]string, 0, len(ports))
+	for _, port := range ports {
+		part := ""
+		if port.Port != nil {
+			part = fmt.Sprintf("%d", *port.Port)
+		}
+		if port.Protocol != nil {
+			part += "/" + string(*port.Protocol)
+		}
+		if port.Name != nil && *port.Name != "" {
+			part = *port.Name + " " + part
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// isTrue returns the value of an endpoint condition, def if it is unknown.
+func isTrue(cond *bool, def bool) bool {
+	if cond == nil {
+		return def
+	}
+	return *cond
+}
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,31 @@
+package serviceaccount
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AutomountToken reports whether pods get an API token of the service
+// account mounted unless they opt out, which is the default.
+func AutomountToken(sa *corev1.ServiceAccount) bool {
+	return sa.AutomountServiceAccountToken == nil || *sa.AutomountServiceAccountToken
+}
+
+// FormatSecrets returns the names of referenced secrets, or a dash.
+func FormatSecrets(refs []corev1.LocalObjectReference) string {
+	if len(refs) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// UserName returns the name the service account authenticates as, which
+// bindings may refer to as a user.
+func UserName(sa *corev1.ServiceAccount) string {
+	return "system:serviceaccount:" + sa.Namespace + ":" + sa.Name
+}
@@ -0,0 +1,45 @@
+package shared
+
+import (
+	"context"
+
+	"github.com/a-h/templ"
+)
+
+// Clusters describes the clusters polar-bear serves and the one a page shows.
+type Clusters struct {
+	// Names of all clusters, the first one is also served without prefix
+	Names []string
+	// Current is the name of the cluster the page shows
+	Current string
+	// Prefix of the paths of the current cluster's pages, empty for the
+	// unprefixed URLs of the first cluster
+	Prefix string
+}
+
+type clustersKey struct{}
+
+// WithClusters returns a context in which links point to pages of the current cluster.
+func WithClusters(ctx context.Context, clusters Clusters) context.Context {
+	return context.WithValue(ctx, clustersKey{}, clusters)
+}
+
+// ClustersFrom returns the clusters of a request, set by WithClusters.
+func ClustersFrom(ctx context.Context) Clusters {
+	clusters, _ := ctx.Value(clustersKey{}).(Clusters)
+	return clusters
+}
+
+// ClusterPrefix returns the path prefix of the current cluster's pages.
+func ClusterPrefix(ctx context.Context) string {
+	return ClustersFrom(ctx).Prefix
+}
+
+// ClusterHomeLink returns the overview page of a cluster.
+func ClusterHomeLink(name string) templ.SafeURL {
+	return templ.URL("/c/" + name + "/")
+}
+
+func clusterURL(ctx context.Context, path string) templ.SafeURL {
+	return templ.URL(ClusterPrefix(ctx) + path)
+}
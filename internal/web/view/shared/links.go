@@ -1,10 +1,14 @@
 package shared
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/a-h/templ"
+
+	"polar-bear/internal/core"
 )
 
 func registryURL(name string) (string, error) {
@@ -70,54 +74,351 @@ func RegistryLink(name string) templ.SafeURL {
 	return templ.URL(url)
 }
 
-func ClusterLink(item string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/%s", item))
+func ClusterLink(ctx context.Context, item string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/%s", item))
+}
+
+func NamespacesLink(ctx context.Context) templ.SafeURL {
+	return clusterURL(ctx, "/ns")
+}
+
+func NamespaceLink(ctx context.Context, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s", name))
+}
+
+func NodesLink(ctx context.Context) templ.SafeURL {
+	return clusterURL(ctx, "/no")
+}
+
+func NodeLink(ctx context.Context, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/no/%s", name))
+}
+
+func PodsLink(ctx context.Context, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/pd", name))
+}
+
+func PodLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/pd/%s", ns, name))
+}
+
+func DeploymentsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/deploy", ns))
+}
+
+func DeploymentLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/deploy/%s", ns, name))
+}
+
+func ReplicaSetsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/rs", ns))
+}
+
+func ReplicaSetLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/rs/%s", ns, name))
+}
+
+func StatefulSetsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/sts", ns))
+}
+
+func StatefulSetLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/sts/%s", ns, name))
+}
+
+func DaemonSetsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/ds", ns))
+}
+
+func DaemonSetLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/ds/%s", ns, name))
+}
+
+func JobsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/job", ns))
+}
+
+func JobLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/job/%s", ns, name))
+}
+
+func CronJobsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/cronjob", ns))
+}
+
+func CronJobLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/cronjob/%s", ns, name))
+}
+
+func ServicesLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/svc", ns))
+}
+
+func ServiceLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/svc/%s", ns, name))
+}
+
+func EndpointSlicesLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/epslice", ns))
+}
+
+func EndpointSliceLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/epslice/%s", ns, name))
+}
+
+func IngressesLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/ing", ns))
+}
+
+func IngressLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/ing/%s", ns, name))
+}
+
+func NetworkPoliciesLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/netpol", ns))
+}
+
+func NetworkPolicyLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/netpol/%s", ns, name))
+}
+
+func PersistentVolumeClaimsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/pvc", ns))
 }
 
-func NamespacesLink() templ.SafeURL {
-	return templ.URL("/ns")
+func PersistentVolumeClaimLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/pvc/%s", ns, name))
 }
 
-func NamespaceLink(name string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/ns/%s", name))
+func PersistentVolumesLink(ctx context.Context) templ.SafeURL {
+	return clusterURL(ctx, "/pv")
 }
 
-func NodesLink() templ.SafeURL {
-	return templ.URL("/no")
+func PersistentVolumeLink(ctx context.Context, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/pv/%s", name))
 }
 
-func NodeLink(name string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/no/%s", name))
+func StorageClassesLink(ctx context.Context) templ.SafeURL {
+	return clusterURL(ctx, "/sc")
 }
 
-func PodsLink(name string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/ns/%s/pd", name))
+func StorageClassLink(ctx context.Context, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/sc/%s", name))
 }
 
-func PodLink(ns string, name string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/ns/%s/pd/%s", ns, name))
+func CSIDriversLink(ctx context.Context) templ.SafeURL {
+	return clusterURL(ctx, "/csidriver")
 }
 
-func DeploymentsLink(ns string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/ns/%s/deploy", ns))
+func CSIDriverLink(ctx context.Context, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/csidriver/%s", name))
 }
 
-func DeploymentLink(ns string, name string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/ns/%s/deploy/%s", ns, name))
+func ConfigMapsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/cm", ns))
 }
 
-func ReplicaSetsLink(ns string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/ns/%s/rs", ns))
+func ConfigMapLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/cm/%s", ns, name))
 }
 
-func ReplicaSetLink(ns string, name string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/ns/%s/rs/%s", ns, name))
+func SecretsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/secret", ns))
+}
+
+func SecretLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/secret/%s", ns, name))
+}
+
+func ServiceAccountsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/sa", ns))
+}
+
+func ServiceAccountLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/sa/%s", ns, name))
+}
+
+func RolesLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/role", ns))
+}
+
+func RoleLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/role/%s", ns, name))
+}
+
+func RoleBindingsLink(ctx context.Context, ns string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/rolebinding", ns))
+}
+
+func RoleBindingLink(ctx context.Context, ns string, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/rolebinding/%s", ns, name))
+}
+
+func ClusterRolesLink(ctx context.Context) templ.SafeURL {
+	return clusterURL(ctx, "/clusterrole")
+}
+
+func ClusterRoleLink(ctx context.Context, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/clusterrole/%s", name))
+}
+
+func ClusterRoleBindingsLink(ctx context.Context) templ.SafeURL {
+	return clusterURL(ctx, "/clusterrolebinding")
+}
+
+func ClusterRoleBindingLink(ctx context.Context, name string) templ.SafeURL {
+	return clusterURL(ctx, fmt.Sprintf("/clusterrolebinding/%s", name))
+}
+
+// RBACLink returns the RBAC evaluator answering the given query.
+func RBACLink(ctx context.Context, query url.Values) templ.SafeURL {
+	if len(query) == 0 {
+		return clusterURL(ctx, "/rbac")
+	}
+	return clusterURL(ctx, "/rbac?"+query.Encode())
+}
+
+// SearchLink returns the results of a global search.
+func SearchLink(ctx context.Context, query string) templ.SafeURL {
+	if query == "" {
+		return clusterURL(ctx, "/search")
+	}
+	return clusterURL(ctx, "/search?"+url.Values{"q": {query}}.Encode())
+}
+
+// SearchResultLink returns the detail page of a search result.
+func SearchResultLink(ctx context.Context, res core.SearchResult) templ.SafeURL {
+	if res.Resource != "" {
+		return CustomResourceLink(ctx, res.Namespace, res.Resource, res.Name)
+	}
+	return ObjectLink(ctx, res.Kind, res.Namespace, res.Name)
+}
+
+// SubjectLink returns the page of an RBAC subject, the evaluator showing its
+// permissions in ns for users and groups.
+func SubjectLink(ctx context.Context, kind string, ns string, name string) templ.SafeURL {
+	if kind == "ServiceAccount" {
+		return ServiceAccountLink(ctx, ns, name)
+	}
+	q := url.Values{}
+	q.Set("kind", kind)
+	q.Set("subject", name)
+	if ns != "" {
+		q.Set("ns", ns)
+	}
+	return RBACLink(ctx, q)
+}
+
+func CustomResourceDefinitionsLink(ctx context.Context) templ.SafeURL {
+	return clusterURL(ctx, "/crd")
+}
+
+func CustomResourcesLink(ctx context.Context, ns string, res string) templ.SafeURL {
+	if ns == "" {
+		return clusterURL(ctx, fmt.Sprintf("/cr/%s", res))
+	}
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/%s", ns, res))
+}
+
+func CustomResourceLink(ctx context.Context, ns string, res string, name string) templ.SafeURL {
+	if ns == "" {
+		return clusterURL(ctx, fmt.Sprintf("/cr/%s/%s", res, name))
+	}
+	return clusterURL(ctx, fmt.Sprintf("/ns/%s/%s/%s", ns, res, name))
+}
+
+// ObjectLink returns the detail page of an object referenced by kind, or an
+// empty URL if polar-bear has no page for that kind.
+func ObjectLink(ctx context.Context, kind string, ns string, name string) templ.SafeURL {
+	switch kind {
+	case "Namespace":
+		return NamespaceLink(ctx, name)
+	case "Node":
+		return NodeLink(ctx, name)
+	case "Pod":
+		return PodLink(ctx, ns, name)
+	case "Deployment":
+		return DeploymentLink(ctx, ns, name)
+	case "ReplicaSet":
+		return ReplicaSetLink(ctx, ns, name)
+	case "StatefulSet":
+		return StatefulSetLink(ctx, ns, name)
+	case "DaemonSet":
+		return DaemonSetLink(ctx, ns, name)
+	case "Job":
+		return JobLink(ctx, ns, name)
+	case "CronJob":
+		return CronJobLink(ctx, ns, name)
+	case "Service":
+		return ServiceLink(ctx, ns, name)
+	case "EndpointSlice":
+		return EndpointSliceLink(ctx, ns, name)
+	case "Ingress":
+		return IngressLink(ctx, ns, name)
+	case "NetworkPolicy":
+		return NetworkPolicyLink(ctx, ns, name)
+	case "PersistentVolumeClaim":
+		return PersistentVolumeClaimLink(ctx, ns, name)
+	case "PersistentVolume":
+		return PersistentVolumeLink(ctx, name)
+	case "StorageClass":
+		return StorageClassLink(ctx, name)
+	case "CSIDriver":
+		return CSIDriverLink(ctx, name)
+	case "ConfigMap":
+		return ConfigMapLink(ctx, ns, name)
+	case "Secret":
+		return SecretLink(ctx, ns, name)
+	case "ServiceAccount":
+		return ServiceAccountLink(ctx, ns, name)
+	case "Role":
+		return RoleLink(ctx, ns, name)
+	case "RoleBinding":
+		return RoleBindingLink(ctx, ns, name)
+	case "ClusterRole":
+		return ClusterRoleLink(ctx, name)
+	case "ClusterRoleBinding":
+		return ClusterRoleBindingLink(ctx, name)
+	default:
+		return templ.SafeURL("")
+	}
+}
+
+// WebsocketLink returns the websocket endpoint subscribed to updates of the
+// given kind, an empty name subscribes to the list of resources of it passing
+// the list options of ctx.
+func WebsocketLink(ctx context.Context, kind string, ns string, name string) string {
+	return ClusterPrefix(ctx) + "/ws?" + liveQuery(ctx, kind, ns, name)
+}
+
+// EventStreamLink returns the server-sent events endpoint with the same
+// subscription as WebsocketLink.
+func EventStreamLink(ctx context.Context, kind string, ns string, name string) string {
+	return ClusterPrefix(ctx) + "/sse?" + liveQuery(ctx, kind, ns, name)
+}
+
+func liveQuery(ctx context.Context, kind string, ns string, name string) string {
+	q := url.Values{}
+	if name == "" {
+		q = ListOptionsFrom(ctx).Query()
+	}
+	q.Set("kind", kind)
+	if ns != "" {
+		q.Set("ns", ns)
+	}
+	if name != "" {
+		q.Set("name", name)
+	}
+	return q.Encode()
 }
 
-func StatefulSetsLink(ns string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/ns/%s/sts", ns))
+// ManifestLink returns the YAML tab of a detail page.
+func ManifestLink(detail templ.SafeURL) templ.SafeURL {
+	return detail + "?tab=yaml"
 }
 
-func StatefulSetLink(ns string, name string) templ.SafeURL {
-	return templ.URL(fmt.Sprintf("/ns/%s/sts/%s", ns, name))
+// RawManifestLink returns the object of a detail page in the given format,
+// yaml or json.
+func RawManifestLink(detail templ.SafeURL, format string) templ.SafeURL {
+	return detail + templ.SafeURL("?format="+format)
 }
@@ -0,0 +1,100 @@
+package shared
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+
+	"polar-bear/internal/core"
+)
+
+type listOptionsKey struct{}
+
+// WithListOptions returns a context in which list pages show and stream the
+// objects passing the given options.
+func WithListOptions(ctx context.Context, opts core.ListOptions) context.Context {
+	return context.WithValue(ctx, listOptionsKey{}, opts)
+}
+
+// ListOptionsFrom returns the list options of a page, the zero value if it
+// has none.
+func ListOptionsFrom(ctx context.Context) core.ListOptions {
+	opts, _ := ctx.Value(listOptionsKey{}).(core.ListOptions)
+	return opts
+}
+
+// SortOption is a sort order offered on list pages.
+type SortOption struct {
+	Value string
+	Label string
+}
+
+// SortOptions returns the sort orders of a list page, restarts only apply to
+// pods.
+func SortOptions(kind string) []SortOption {
+	opts := []SortOption{
+		{"", "Default"},
+		{core.SortName, "Name"},
+		{core.SortAge, "Age, newest first"},
+		{"-" + core.SortAge, "Age, oldest first"},
+		{core.SortStatus, "Status"},
+	}
+	if kind == "pod" {
+		opts = append(opts, SortOption{"-" + core.SortRestarts, "Restarts, most first"})
+	}
+	return opts
+}
+
+// filterChip is an active filter of a list page, removed by following its
+// link.
+type filterChip struct {
+	Label  string
+	Remove templ.SafeURL
+}
+
+// filterChips returns a chip per requirement of the selectors, the restart
+// filter, each namespace and the sort order of a list of kind.
+func filterChips(kind string, opts core.ListOptions) []filterChip {
+	chips := make([]filterChip, 0)
+	labelReqs := opts.LabelRequirements()
+	for i, req := range labelReqs {
+		rest := opts
+		rest.Selector = strings.Join(slices.Delete(slices.Clone(labelReqs), i, i+1), ",")
+		chips = append(chips, filterChip{"label " + req, listQueryLink(rest)})
+	}
+	fieldReqs := opts.FieldRequirements()
+	for i, req := range fieldReqs {
+		rest := opts
+		rest.Fields = strings.Join(slices.Delete(slices.Clone(fieldReqs), i, i+1), ",")
+		chips = append(chips, filterChip{"field " + req, listQueryLink(rest)})
+	}
+	if opts.MinRestarts > 0 {
+		rest := opts
+		rest.MinRestarts = 0
+		chips = append(chips, filterChip{"restarts >= " + strconv.Itoa(opts.MinRestarts), listQueryLink(rest)})
+	}
+	for i, ns := range opts.Namespaces {
+		rest := opts
+		rest.Namespaces = slices.Delete(slices.Clone(opts.Namespaces), i, i+1)
+		chips = append(chips, filterChip{"namespace " + ns, listQueryLink(rest)})
+	}
+	if opts.Sort != "" {
+		rest := opts
+		rest.Sort = ""
+		label := opts.Sort
+		if i := slices.IndexFunc(SortOptions(kind), func(so SortOption) bool { return so.Value == opts.Sort }); i >= 0 {
+			label = SortOptions(kind)[i].Label
+		}
+		chips = append(chips, filterChip{"sorted by " + label, listQueryLink(rest)})
+	}
+	return chips
+}
+
+// listQueryLink returns the current page with the given options, relative to
+// its path.
+func listQueryLink(opts core.ListOptions) templ.SafeURL {
+	return templ.SafeURL("?" + opts.Query().Encode())
+}
@@ -0,0 +1,24 @@
+package shared
+
+import "context"
+
+// Transports of live updates.
+const (
+	TransportWebsocket = "ws"
+	TransportSSE       = "sse"
+)
+
+type transportKey struct{}
+
+// WithTransport returns a context in which Live connects with the given transport.
+func WithTransport(ctx context.Context, transport string) context.Context {
+	return context.WithValue(ctx, transportKey{}, transport)
+}
+
+// Transport returns the transport of live updates, websockets by default.
+func Transport(ctx context.Context) string {
+	if transport, ok := ctx.Value(transportKey{}).(string); ok && transport != "" {
+		return transport
+	}
+	return TransportWebsocket
+}
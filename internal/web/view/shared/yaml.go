@@ -0,0 +1,146 @@
+package shared
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+// YAMLToken is a part of a line of YAML and the class it is highlighted with.
+type YAMLToken struct {
+	Class string
+	Text  string
+}
+
+const (
+	yamlKeyClass     = "text-blue-700"
+	yamlMarkClass    = "text-gray-400"
+	yamlStringClass  = "text-green-700"
+	yamlLiteralClass = "text-orange-700"
+)
+
+// HighlightYAML splits YAML into lines of highlighted tokens. It only knows
+// the block style that manifests are written in, not YAML in general.
+func HighlightYAML(src []byte) [][]YAMLToken {
+	var lines [][]YAMLToken
+
+	// Column of the node of the block scalar being read, -1 outside of one
+	block := -1
+
+	for line := range strings.SplitSeq(strings.TrimSuffix(string(src), "\n"), "\n") {
+		rest := strings.TrimLeft(line, " ")
+		column := len(line) - len(rest)
+
+		var tokens []YAMLToken
+		if column > 0 {
+			tokens = append(tokens, YAMLToken{Text: line[:column]})
+		}
+		if block >= 0 && (column > block || rest == "") {
+			// Keep the height of empty lines
+			tokens = append(tokens, YAMLToken{Class: yamlStringClass, Text: cmp.Or(rest, " ")})
+			lines = append(lines, tokens)
+			continue
+		}
+		block = -1
+
+		if strings.HasPrefix(rest, "#") {
+			tokens = append(tokens, YAMLToken{Class: yamlMarkClass, Text: rest})
+			lines = append(lines, tokens)
+			continue
+		}
+
+		// Column of the node a block scalar value belongs to, its lines are
+		// indented deeper
+		parent := column
+		for {
+			item, ok := strings.CutPrefix(rest, "- ")
+			if !ok {
+				break
+			}
+			tokens = append(tokens, YAMLToken{Class: yamlMarkClass, Text: "- "})
+			rest = item
+			parent = column
+			column += 2
+		}
+
+		if key, value, ok := splitYAMLKey(rest); ok {
+			tokens = append(tokens,
+				YAMLToken{Class: yamlKeyClass, Text: key},
+				YAMLToken{Class: yamlMarkClass, Text: ":"},
+			)
+			if value != "" {
+				tokens = append(tokens, YAMLToken{Text: " "})
+			}
+			rest = value
+			parent = column
+		}
+
+		if rest != "" {
+			tokens = append(tokens, YAMLToken{Class: yamlValueClass(rest), Text: rest})
+			if strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">") {
+				block = parent
+			}
+		}
+		lines = append(lines, tokens)
+	}
+
+	return lines
+}
+
+// splitYAMLKey splits a mapping entry into its key and value.
+func splitYAMLKey(s string) (key string, value string, ok bool) {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+		end := quotedEnd(s)
+		if end < 0 || !strings.HasPrefix(s[end:], ":") {
+			return "", "", false
+		}
+		if len(s) > end+1 && s[end+1] != ' ' {
+			return "", "", false
+		}
+		return s[:end], strings.TrimPrefix(s[end+1:], " "), true
+	}
+
+	if strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[") {
+		return "", "", false
+	}
+	if key, value, ok := strings.Cut(s, ": "); ok {
+		return key, value, true
+	}
+	if key, ok := strings.CutSuffix(s, ":"); ok {
+		return key, "", true
+	}
+	return "", "", false
+}
+
+// quotedEnd returns the index after the closing quote of the quoted string s
+// starts with, or -1 if it isn't closed on this line.
+func quotedEnd(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func yamlValueClass(value string) string {
+	switch value {
+	case "true", "false", "null", "~":
+		return yamlLiteralClass
+	case "{}", "[]":
+		return yamlMarkClass
+	}
+	if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+		return yamlMarkClass
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return yamlLiteralClass
+	}
+	return yamlStringClass
+}
@@ -0,0 +1,159 @@
+package shared
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHighlightYAML(t *testing.T) {
+	indent := func(n int) YAMLToken { return YAMLToken{Text: strings.Repeat(" ", n)} }
+	key := func(s string) YAMLToken { return YAMLToken{Class: yamlKeyClass, Text: s} }
+	str := func(s string) YAMLToken { return YAMLToken{Class: yamlStringClass, Text: s} }
+	lit := func(s string) YAMLToken { return YAMLToken{Class: yamlLiteralClass, Text: s} }
+	mark := func(s string) YAMLToken { return YAMLToken{Class: yamlMarkClass, Text: s} }
+	colon, space, item := mark(":"), YAMLToken{Text: " "}, mark("- ")
+
+	tests := []struct {
+		name string
+		src  string
+		want [][]YAMLToken
+	}{
+		{
+			name: "mapping",
+			src:  "kind: Pod\nmetadata:\n  name: web\n",
+			want: [][]YAMLToken{
+				{key("kind"), colon, space, str("Pod")},
+				{key("metadata"), colon},
+				{indent(2), key("name"), colon, space, str("web")},
+			},
+		},
+		{
+			name: "literals",
+			src:  "replicas: 3\nratio: 0.5\npaused: false\nselector: null\nlabels: {}\nports: []\n",
+			want: [][]YAMLToken{
+				{key("replicas"), colon, space, lit("3")},
+				{key("ratio"), colon, space, lit("0.5")},
+				{key("paused"), colon, space, lit("false")},
+				{key("selector"), colon, space, lit("null")},
+				{key("labels"), colon, space, mark("{}")},
+				{key("ports"), colon, space, mark("[]")},
+			},
+		},
+		{
+			name: "list items",
+			src:  "containers:\n- name: app\n  image: web:1\n- - nested\nargs:\n- --port=80\n",
+			want: [][]YAMLToken{
+				{key("containers"), colon},
+				{item, key("name"), colon, space, str("app")},
+				{indent(2), key("image"), colon, space, str("web:1")},
+				{item, item, str("nested")},
+				{key("args"), colon},
+				{item, str("--port=80")},
+			},
+		},
+		{
+			name: "quoted keys containing colons",
+			src:  "annotations:\n  \"example.com/url\": https://example.com\n  'a: b': \"c: d\"\n  \"x\\\": y\": z\n",
+			want: [][]YAMLToken{
+				{key("annotations"), colon},
+				{indent(2), key(`"example.com/url"`), colon, space, str("https://example.com")},
+				{indent(2), key(`'a: b'`), colon, space, str(`"c: d"`)},
+				{indent(2), key(`"x\": y"`), colon, space, str("z")},
+			},
+		},
+		{
+			name: "quoted values are no keys",
+			src:  "- \"a: b\"\n- 'it''s: x'\n- \"open: quote\n",
+			want: [][]YAMLToken{
+				{item, str(`"a: b"`)},
+				{item, str(`'it''s: x'`)},
+				{item, str(`"open: quote`)},
+			},
+		},
+		{
+			name: "values with colons",
+			src:  "image: registry:5000/web:1\ncommand: echo a:b\n",
+			want: [][]YAMLToken{
+				{key("image"), colon, space, str("registry:5000/web:1")},
+				{key("command"), colon, space, str("echo a:b")},
+			},
+		},
+		{
+			name: "block scalars",
+			src:  "data:\n  config: |\n    a: 1\n\n    - b\n  other: >-\n    folded\n  next: x\n",
+			want: [][]YAMLToken{
+				{key("data"), colon},
+				{indent(2), key("config"), colon, space, mark("|")},
+				{indent(4), str("a: 1")},
+				{str(" ")},
+				{indent(4), str("- b")},
+				{indent(2), key("other"), colon, space, mark(">-")},
+				{indent(4), str("folded")},
+				{indent(2), key("next"), colon, space, str("x")},
+			},
+		},
+		{
+			name: "block scalar of a list item",
+			src:  "args:\n- |\n  #!/bin/sh\n  echo\n- x\n",
+			want: [][]YAMLToken{
+				{key("args"), colon},
+				{item, mark("|")},
+				{indent(2), str("#!/bin/sh")},
+				{indent(2), str("echo")},
+				{item, str("x")},
+			},
+		},
+		{
+			name: "block scalar of a key in a list item",
+			src:  "- script: |\n    echo\n  image: sh\n",
+			want: [][]YAMLToken{
+				{item, key("script"), colon, space, mark("|")},
+				{indent(4), str("echo")},
+				{indent(2), key("image"), colon, space, str("sh")},
+			},
+		},
+		{
+			name: "comments",
+			src:  "# note: first\nkind: Pod\n  # indented: comment\ncolor: '#fff'\nurl: a#b\n",
+			want: [][]YAMLToken{
+				{mark("# note: first")},
+				{key("kind"), colon, space, str("Pod")},
+				{indent(2), mark("# indented: comment")},
+				{key("color"), colon, space, str("'#fff'")},
+				{key("url"), colon, space, str("a#b")},
+			},
+		},
+		{
+			name: "flow collections are no keys",
+			src:  "{a: b}\n[a: b]\n",
+			want: [][]YAMLToken{
+				{str("{a: b}")},
+				{str("[a: b]")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HighlightYAML([]byte(tt.src))
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+
+			// Highlighting keeps the text, empty lines of block scalars
+			// are shown with a space
+			var lines []string
+			for _, tokens := range got {
+				var sb strings.Builder
+				for _, token := range tokens {
+					sb.WriteString(token.Text)
+				}
+				lines = append(lines, strings.TrimRight(sb.String(), " "))
+			}
+			if text := strings.Join(lines, "\n") + "\n"; text != tt.src {
+				t.Errorf("got text %q, want %q", text, tt.src)
+			}
+		})
+	}
+}
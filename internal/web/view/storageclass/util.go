@@ -0,0 +1,88 @@
+package storageclass
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotations marking the default storage class, the beta one is still set by
+// some installers.
+const (
+	annotationDefault     = "storageclass.kubernetes.io/is-default-class"
+	annotationBetaDefault = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// IsDefault reports whether claims without a class get this class.
+func IsDefault(sc *storagev1.StorageClass) bool {
+	return sc.Annotations[annotationDefault] == "true" || sc.Annotations[annotationBetaDefault] == "true"
+}
+
+// ReclaimPolicy returns what happens to the volumes of a class once their
+// claim is deleted, which defaults to deleting them.
+func ReclaimPolicy(sc *storagev1.StorageClass) string {
+	if sc.ReclaimPolicy == nil {
+		return string(corev1.PersistentVolumeReclaimDelete)
+	}
+	return string(*sc.ReclaimPolicy)
+}
+
+// BindingMode returns when the volumes of a class are provisioned and bound,
+// which defaults to immediately.
+func BindingMode(sc *storagev1.StorageClass) string {
+	if sc.VolumeBindingMode == nil {
+		return string(storagev1.VolumeBindingImmediate)
+	}
+	return string(*sc.VolumeBindingMode)
+}
+
+// FormatTopologies returns the topologies volumes of a class may be
+// provisioned in, one per term.
+func FormatTopologies(terms []corev1.TopologySelectorTerm) []string {
+	topologies := make([]string, 0, len(terms))
+	for _, term := range terms {
+		reqs := make([]string, 0, len(term.MatchLabelExpressions))
+		for _, req := range term.MatchLabelExpressions {
+			reqs = append(reqs, fmt.Sprintf("%s in (%s)", req.Key, strings.Join(req.Values, ", ")))
+		}
+		topologies = append(topologies, strings.Join(reqs, " and "))
+	}
+	return topologies
+}
+
+func boolOrFalse(value *bool) string {
+	return fmt.Sprintf("%t", value != nil && *value)
+}
+
+// formatNodeTopology returns the nodes a storage capacity applies to.
+func formatNodeTopology(sel *metav1.LabelSelector) string {
+	if sel == nil {
+		return "no nodes"
+	}
+	parts := make([]string, 0, len(sel.MatchLabels)+len(sel.MatchExpressions))
+	for _, key := range slices.Sorted(maps.Keys(sel.MatchLabels)) {
+		parts = append(parts, key+"="+sel.MatchLabels[key])
+	}
+	for _, req := range sel.MatchExpressions {
+		parts = append(parts, fmt.Sprintf("%s %s (%s)", req.Key, req.Operator, strings.Join(req.Values, ", ")))
+	}
+	if len(parts) == 0 {
+		return "all nodes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatQuantity returns a size reported by a CSI driver, or a dash if it
+// reported none.
+func formatQuantity(q *resource.Quantity) string {
+	if q == nil {
+		return "-"
+	}
+	return q.String()
+}
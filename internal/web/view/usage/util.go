@@ -0,0 +1,113 @@
+package usage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"polar-bear/internal/core"
+	"polar-bear/internal/web/view/capacity"
+)
+
+// Size of the viewBox of sparklines, they are stretched to the panel width.
+const (
+	sparklineWidth  = 100
+	sparklineHeight = 20
+)
+
+// FormatQuantity formats an amount of a resource like capacity.FormatQuantity,
+// CPU rounded to millicores as the metrics server reports nanocores.
+func FormatQuantity(name corev1.ResourceName, q resource.Quantity) string {
+	if name == corev1.ResourceCPU {
+		q = *resource.NewMilliQuantity(q.MilliValue(), resource.DecimalSI)
+	}
+	return capacity.FormatQuantity(name, q)
+}
+
+// FormatUsed formats an amount with the share of it that is used, as in
+// "500m (24% used)", or "-" if it isn't set.
+func FormatUsed(name corev1.ResourceName, q resource.Quantity, ratio float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%.0f%% used)", FormatQuantity(name, q), ratio*100)
+}
+
+// meterScale returns the amount usage is shown against: the allocatable
+// amount of nodes or the limits of pods, their requests without limits.
+func meterScale(ru core.ResourceUsage) (resource.Quantity, bool) {
+	for _, q := range []resource.Quantity{ru.Allocatable, ru.Limits, ru.Requests} {
+		if !q.IsZero() {
+			return q, true
+		}
+	}
+	return resource.Quantity{}, false
+}
+
+// HasMeter reports whether there is an amount to compare the usage with.
+func HasMeter(ru core.ResourceUsage) bool {
+	_, ok := meterScale(ru)
+	return ok
+}
+
+// MeterValue returns the used share of the meter scale for a meter element,
+// which clamps values above its maximum.
+func MeterValue(ru core.ResourceUsage) string {
+	scale, _ := meterScale(ru)
+	return fmt.Sprintf("%.3f", ru.Usage.AsApproximateFloat64()/scale.AsApproximateFloat64())
+}
+
+// MeterLow returns where the requests are on the meter scale, so the meter
+// turns yellow once more than requested is used.
+func MeterLow(ru core.ResourceUsage) string {
+	scale, _ := meterScale(ru)
+	low := 0.7
+	if !ru.Requests.IsZero() && ru.Requests.Cmp(scale) < 0 {
+		low = ru.Requests.AsApproximateFloat64() / scale.AsApproximateFloat64()
+	}
+	return fmt.Sprintf("%.3f", min(low, 0.9))
+}
+
+// HasSparkline reports whether there are enough samples of a resource to
+// draw its sparkline.
+func HasSparkline(u *core.Usage, name corev1.ResourceName) bool {
+	_, values := u.Series(name)
+	return len(values) > 1
+}
+
+// SparklinePoints returns the points of the polyline of a resource over the
+// usage history, from zero at the bottom to the peak at the top.
+func SparklinePoints(u *core.Usage, name corev1.ResourceName) string {
+	times, values := u.Series(name)
+	peak := slices.Max(values)
+	start := u.Timestamp.Add(-core.UsageHistory)
+
+	points := make([]string, 0, len(values))
+	for i, value := range values {
+		x := float64(times[i].Sub(start)) / float64(core.UsageHistory) * sparklineWidth
+		y := float64(sparklineHeight)
+		if peak > 0 {
+			y -= value / peak * sparklineHeight
+		}
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	return strings.Join(points, " ")
+}
+
+// FormatPeak formats the highest usage of a resource over the history.
+func FormatPeak(u *core.Usage, name corev1.ResourceName) string {
+	peak := resource.Quantity{}
+	for _, sample := range u.History {
+		if q, ok := sample.Usage[name]; ok && q.Cmp(peak) > 0 {
+			peak = q
+		}
+	}
+	return FormatQuantity(name, peak)
+}
+
+func sparklineViewBox() string {
+	return fmt.Sprintf("0 0 %d %d", sparklineWidth, sparklineHeight)
+}
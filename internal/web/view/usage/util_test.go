@@ -0,0 +1,99 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"polar-bear/internal/core"
+)
+
+// testUsage returns usage with a CPU sample every 15 minutes up to now, nil
+// values are samples without CPU.
+func testUsage(cpu ...*resource.Quantity) *core.Usage {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	u := &core.Usage{Timestamp: now}
+	for i, q := range cpu {
+		sample := core.UsageSample{
+			Time:  now.Add(time.Duration(i-len(cpu)+1) * 15 * time.Minute),
+			Usage: corev1.ResourceList{},
+		}
+		if q != nil {
+			sample.Usage[corev1.ResourceCPU] = *q
+		}
+		u.History = append(u.History, sample)
+	}
+	return u
+}
+
+func cpu(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
+
+func TestSparklinePoints(t *testing.T) {
+	tests := []struct {
+		name string
+		u    *core.Usage
+		want string
+	}{
+		{
+			name: "scaled to the peak",
+			u:    testUsage(cpu("0"), cpu("250m"), cpu("500m"), cpu("1"), cpu("500m")),
+			want: "0.0,20.0 25.0,15.0 50.0,10.0 75.0,0.0 100.0,10.0",
+		},
+		{
+			name: "small values fill the height",
+			u:    testUsage(cpu("1m"), cpu("2m")),
+			want: "75.0,10.0 100.0,0.0",
+		},
+		{
+			name: "no usage stays at the bottom",
+			u:    testUsage(cpu("0"), cpu("0"), cpu("0")),
+			want: "50.0,20.0 75.0,20.0 100.0,20.0",
+		},
+		{
+			name: "samples without the resource are skipped",
+			u:    testUsage(cpu("1"), nil, cpu("500m")),
+			want: "50.0,0.0 100.0,10.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SparklinePoints(tt.u, corev1.ResourceCPU); got != tt.want {
+				t.Errorf("got points %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasSparkline(t *testing.T) {
+	tests := []struct {
+		name string
+		u    *core.Usage
+		want bool
+	}{
+		{name: "no samples", u: testUsage(), want: false},
+		{name: "one sample", u: testUsage(cpu("1")), want: false},
+		{name: "one sample with the resource", u: testUsage(nil, cpu("1")), want: false},
+		{name: "two samples", u: testUsage(cpu("1"), cpu("2")), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasSparkline(tt.u, corev1.ResourceCPU); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPeak(t *testing.T) {
+	u := testUsage(cpu("250m"), cpu("1500m"), nil, cpu("1"))
+	if got, want := FormatPeak(u, corev1.ResourceCPU), FormatQuantity(corev1.ResourceCPU, resource.MustParse("1500m")); got != want {
+		t.Errorf("got peak %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,265 @@
+package workload
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// revisionAnnotation holds the revision of a deployment and its replica sets.
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// Count is a number of pods in the status of a workload. Lagging counts are
+// lower than desired and highlighted.
+type Count struct {
+	Name    string
+	Value   int32
+	Lagging bool
+}
+
+func (c Count) class() string {
+	if c.Lagging {
+		return "text-orange-700"
+	}
+	return "text-gray-800"
+}
+
+// Revision is an entry in the rollout history of a workload: a replica set of
+// a deployment, or a controller revision of a stateful set or daemon set.
+type Revision struct {
+	Kind    string
+	Name    string
+	Number  int64
+	Created time.Time
+	Images  []string
+	Current bool
+}
+
+// DeploymentRevision returns the revision a deployment is rolled out to.
+func DeploymentRevision(deploy *appsv1.Deployment) string {
+	return deploy.Annotations[revisionAnnotation]
+}
+
+// ReplicaSetRevision returns the revision of the deployment a replica set
+// was created for, or "-" if it isn't part of one.
+func ReplicaSetRevision(rs *appsv1.ReplicaSet) string {
+	return valueOrDash(rs.Annotations[revisionAnnotation])
+}
+
+// ReplicaSetRevisions returns the history of a deployment from its replica
+// sets, current is the revision of the deployment.
+func ReplicaSetRevisions(rss []*appsv1.ReplicaSet, current string) []Revision {
+	revs := make([]Revision, 0, len(rss))
+	for _, rs := range rss {
+		number, _ := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		revs = append(revs, Revision{
+			Kind:    "ReplicaSet",
+			Name:    rs.Name,
+			Number:  number,
+			Created: rs.CreationTimestamp.Time,
+			Images:  containerImages(rs.Spec.Template.Spec),
+			Current: current != "" && rs.Annotations[revisionAnnotation] == current,
+		})
+	}
+	return sortRevisions(revs)
+}
+
+// ControllerRevisions returns the history of a stateful set or daemon set,
+// current is the name of the revision it is updated to.
+func ControllerRevisions(crs []*appsv1.ControllerRevision, current string) []Revision {
+	revs := make([]Revision, 0, len(crs))
+	for _, cr := range crs {
+		revs = append(revs, Revision{
+			Kind:    "ControllerRevision",
+			Name:    cr.Name,
+			Number:  cr.Revision,
+			Created: cr.CreationTimestamp.Time,
+			Images:  revisionImages(cr),
+			Current: cr.Name == current,
+		})
+	}
+	return sortRevisions(revs)
+}
+
+// LatestControllerRevision returns the name of the newest controller revision.
+// Daemon sets don't report the revision they are updated to, but the
+// controller always updates to the newest one.
+func LatestControllerRevision(crs []*appsv1.ControllerRevision) string {
+	var latest *appsv1.ControllerRevision
+	for _, cr := range crs {
+		if latest == nil || cr.Revision > latest.Revision {
+			latest = cr
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Name
+}
+
+func jobPhaseColor(phase string) string {
+	switch phase {
+	case "Complete":
+		return "green"
+	case "Failed":
+		return "red"
+	case "Suspended":
+		return "gray"
+	default:
+		return "blue"
+	}
+}
+
+// FormatDuration returns the time a job took, or has been running for if
+// end is nil.
+func FormatDuration(start *metav1.Time, end *metav1.Time) string {
+	if start == nil {
+		return "-"
+	}
+	until := time.Now()
+	if end != nil {
+		until = end.Time
+	}
+	return until.Sub(start.Time).Round(time.Second).String()
+}
+
+// FormatTime returns an optional timestamp of a job or cron job.
+func FormatTime(t *metav1.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func sortRevisions(revs []Revision) []Revision {
+	slices.SortFunc(revs, func(a, b Revision) int {
+		return int(b.Number - a.Number)
+	})
+	return revs
+}
+
+// revisionImages returns the images of the pod template patch that controller
+// revisions of stateful sets and daemon sets store.
+func revisionImages(cr *appsv1.ControllerRevision) []string {
+	var patch struct {
+		Spec struct {
+			Template struct {
+				Spec corev1.PodSpec `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(cr.Data.Raw, &patch); err != nil {
+		return nil
+	}
+	return containerImages(patch.Spec.Template.Spec)
+}
+
+func containerImages(spec corev1.PodSpec) []string {
+	images := make([]string, 0, len(spec.Containers))
+	for _, cnt := range spec.Containers {
+		images = append(images, cnt.Image)
+	}
+	return images
+}
+
+func joinImages(images []string) string {
+	return strings.Join(images, ", ")
+}
+
+// imageName returns an image reference without its tag or digest.
+func imageName(image string) string {
+	if name, _, ok := strings.Cut(image, "@"); ok {
+		return name
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
+
+func formatResources(list corev1.ResourceList) string {
+	if len(list) == 0 {
+		return "-"
+	}
+
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		quantity := list[corev1.ResourceName(name)]
+		parts = append(parts, fmt.Sprintf("%s: %s", name, quantity.String()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// Int32OrDefault returns the value of an optional count, or def if unset.
+func Int32OrDefault(value *int32, def int32) int32 {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
+func FormatMapString(m map[string]string) string {
+	if len(m) == 0 {
+		return "none"
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(m))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, m[k]))
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+func FormatLabelSelectorRequirements(reqs []metav1.LabelSelectorRequirement) string {
+	if len(reqs) == 0 {
+		return "none"
+	}
+
+	parts := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		var part string
+		switch req.Operator {
+		case metav1.LabelSelectorOpIn:
+			part = fmt.Sprintf("%s in (%s)", req.Key, strings.Join(req.Values, ", "))
+		case metav1.LabelSelectorOpNotIn:
+			part = fmt.Sprintf("%s notin (%s)", req.Key, strings.Join(req.Values, ", "))
+		case metav1.LabelSelectorOpExists:
+			part = req.Key
+		case metav1.LabelSelectorOpDoesNotExist:
+			part = fmt.Sprintf("!%s", req.Key)
+		default:
+			part = fmt.Sprintf("%s %s (%s)", req.Key, req.Operator, strings.Join(req.Values, ", "))
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,57 @@
+// Server-sent events extension for htmx. Connects to the URL of sse-connect
+// and applies every message as out-of-band swaps, like the websocket
+// extension does. The browser reconnects a dropped event stream by itself.
+(function () {
+  var api;
+
+  htmx.defineExtension("sse", {
+    init: function (apiRef) {
+      api = apiRef;
+    },
+    onEvent: function (name, evt) {
+      var elt = evt.target || evt.detail.elt;
+      switch (name) {
+        case "htmx:beforeCleanupElement":
+          var data = api.getInternalData(elt);
+          if (data.sseEventSource) {
+            data.sseEventSource.close();
+          }
+          return;
+        case "htmx:beforeProcessNode":
+          forEachConnect(elt, connect);
+      }
+    },
+  });
+
+  function forEachConnect(elt, fn) {
+    if (elt.hasAttribute && elt.hasAttribute("sse-connect")) {
+      fn(elt);
+    }
+    if (elt.querySelectorAll) {
+      elt.querySelectorAll("[sse-connect]").forEach(fn);
+    }
+  }
+
+  function connect(elt) {
+    var data = api.getInternalData(elt);
+    if (data.sseEventSource) {
+      return;
+    }
+
+    var source = new EventSource(api.getAttributeValue(elt, "sse-connect"));
+    source.onmessage = function (evt) {
+      if (!api.bodyContains(elt)) {
+        source.close();
+        return;
+      }
+
+      var settleInfo = api.makeSettleInfo(elt);
+      var fragment = api.makeFragment(evt.data);
+      Array.from(fragment.children).forEach(function (child) {
+        api.oobSwap(api.getAttributeValue(child, "hx-swap-oob") || "true", child, settleInfo);
+      });
+      api.settleImmediately(settleInfo.tasks);
+    };
+    data.sseEventSource = source;
+  }
+})();